@@ -18,7 +18,9 @@ import (
 
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/apis"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/controller"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/migration"
 	nsoptions "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/namespaces"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/webhook"
 
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
 	"github.com/operator-framework/operator-sdk/pkg/leader"
@@ -180,6 +182,20 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup the defaulting, validating and conversion webhooks, they need the CRD types in the scheme
+	if webhook.IsEnabled() {
+		if err := webhook.AddToManager(mgr, oprNameSpace); err != nil {
+			log.Error(err, "failed to set up webhooks")
+			os.Exit(1)
+		}
+		// Rewrite the custom resources stored at older versions, once they convert
+		if err := migration.AddToManager(mgr, watchNameSpace, webhook.ConversionInstalled()); err != nil {
+			log.Error(err, "failed to set up the storage version migration")
+		}
+	} else {
+		log.Info("Webhooks are disabled, set " + webhook.EnvEnableWebhooks + "=true to enable them and the storage version migration")
+	}
+
 	// Create Service object to expose the metrics port.
 	_, err = metrics.ExposeMetricsPort(ctx, metricsPort)
 	if err != nil {
@@ -7,6 +7,7 @@ import (
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha4"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
 	routev1 "github.com/openshift/api/route/v1"
 )
 
@@ -18,4 +19,6 @@ func init() {
 	AddToSchemes = append(AddToSchemes, v2alpha3.SchemeBuilder.AddToScheme, routev1.SchemeBuilder.AddToScheme)
 	AddToSchemes = append(AddToSchemes, v2alpha4.SchemeBuilder.AddToScheme, routev1.SchemeBuilder.AddToScheme)
 	AddToSchemes = append(AddToSchemes, v2alpha5.SchemeBuilder.AddToScheme, routev1.SchemeBuilder.AddToScheme)
+	// The service monitors and prometheus rules of the brokers
+	AddToSchemes = append(AddToSchemes, monitoringv1.AddToScheme)
 }
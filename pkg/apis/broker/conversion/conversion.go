@@ -0,0 +1,313 @@
+// Package conversion converts the broker custom resources between their API versions.
+//
+// Each kind has one hub version, the version it is stored in, and every other version
+// of the kind converts to and from the hub. Fields are matched by name, so a version
+// only needs to declare the conversion methods as long as its fields keep the name and
+// a compatible type from one version to the next. The fields of the hub spec an older
+// version has no room for are kept in an annotation of the older version, so that a
+// client reading and writing back an older version does not drop them.
+//
+// Only ActiveMQArtemis has such versions. The versions of ActiveMQArtemisAddress add fields
+// from one to the next under the same names and its CRD converts without a webhook, the
+// other kinds have a single version.
+package conversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// HubSpecAnnotation holds the hub spec of a custom resource read at a version that
+// cannot represent all of it
+const HubSpecAnnotation = "broker.amq.io/conversion-hub-spec"
+
+// Hub is implemented by the version of a kind every other version converts through
+type Hub interface {
+	runtime.Object
+	Hub()
+}
+
+// Convertible is implemented by the versions of a kind other than the hub
+type Convertible interface {
+	runtime.Object
+	// ConvertTo fills in hub from the receiver
+	ConvertTo(hub Hub) error
+	// ConvertFrom fills in the receiver from hub
+	ConvertFrom(hub Hub) error
+}
+
+// Convert converts src into a new object of the same kind at version gv. The scheme must
+// know every version of the kind, including its hub.
+func Convert(scheme *runtime.Scheme, src runtime.Object, gv schema.GroupVersion) (runtime.Object, error) {
+
+	srcGVK, err := objectKind(scheme, src)
+	if err != nil {
+		return nil, err
+	}
+	dstGVK := gv.WithKind(srcGVK.Kind)
+	if srcGVK == dstGVK {
+		return src.DeepCopyObject(), nil
+	}
+
+	dst, err := scheme.New(dstGVK)
+	if err != nil {
+		return nil, err
+	}
+
+	switch s := src.(type) {
+	case Hub:
+		d, ok := dst.(Convertible)
+		if !ok {
+			return nil, fmt.Errorf("%s cannot be converted from the hub version %s", dstGVK, srcGVK)
+		}
+		err = d.ConvertFrom(s)
+	case Convertible:
+		if d, ok := dst.(Hub); ok {
+			err = s.ConvertTo(d)
+			break
+		}
+		d, ok := dst.(Convertible)
+		if !ok {
+			return nil, fmt.Errorf("%s has no conversion to %s", srcGVK, dstGVK)
+		}
+		var hub Hub
+		if hub, err = newHub(scheme, srcGVK.GroupKind()); err == nil {
+			if err = s.ConvertTo(hub); err == nil {
+				err = d.ConvertFrom(hub)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%s has no conversion to other versions", srcGVK)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dst.GetObjectKind().SetGroupVersionKind(dstGVK)
+	return dst, nil
+}
+
+func objectKind(scheme *runtime.Scheme, obj runtime.Object) (schema.GroupVersionKind, error) {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if !gvk.Empty() {
+		return gvk, nil
+	}
+	gvks, _, err := scheme.ObjectKinds(obj)
+	if err != nil {
+		return gvk, err
+	}
+	return gvks[0], nil
+}
+
+func newHub(scheme *runtime.Scheme, gk schema.GroupKind) (Hub, error) {
+	for gvk := range scheme.AllKnownTypes() {
+		if gvk.GroupKind() != gk {
+			continue
+		}
+		obj, err := scheme.New(gvk)
+		if err != nil {
+			return nil, err
+		}
+		if hub, ok := obj.(Hub); ok {
+			return hub, nil
+		}
+	}
+	return nil, fmt.Errorf("no hub version registered for %s", gk)
+}
+
+// SaveHubSpec keeps hubSpec in the annotations of the older version meta belongs to when
+// spokeSpec, already converted from hubSpec, does not hold all of it. Both are pointers.
+func SaveHubSpec(meta *metav1.ObjectMeta, spokeSpec interface{}, hubSpec interface{}) error {
+
+	check := reflect.New(reflect.TypeOf(hubSpec).Elem())
+	if err := Copy(check.Interface(), spokeSpec); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(check.Interface(), hubSpec) {
+		removeAnnotation(meta)
+		return nil
+	}
+
+	data, err := json.Marshal(hubSpec)
+	if err != nil {
+		return err
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[HubSpecAnnotation] = string(data)
+	return nil
+}
+
+// RestoreHubSpec fills in hubSpec from the annotation set by SaveHubSpec, if any, and
+// removes the annotation from meta
+func RestoreHubSpec(meta *metav1.ObjectMeta, hubSpec interface{}) error {
+	data, ok := meta.Annotations[HubSpecAnnotation]
+	if !ok {
+		return nil
+	}
+	removeAnnotation(meta)
+	if err := json.Unmarshal([]byte(data), hubSpec); err != nil {
+		return fmt.Errorf("invalid %s annotation: %v", HubSpecAnnotation, err)
+	}
+	return nil
+}
+
+func removeAnnotation(meta *metav1.ObjectMeta) {
+	delete(meta.Annotations, HubSpecAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+}
+
+// Copy sets the fields of dst to the fields of src with the same name, dst and src are
+// pointers to values of the same shape from two versions. Fields src does not have are
+// left as they are in dst, fields dst does not have are ignored. Elements of lists of
+// named items, acceptors for instance, are matched by name rather than position.
+// dst may share memory with src afterwards, so pass a copy of src if it is to be
+// changed later.
+func Copy(dst interface{}, src interface{}) error {
+	d := reflect.ValueOf(dst)
+	s := reflect.ValueOf(src)
+	if d.Kind() != reflect.Ptr || s.Kind() != reflect.Ptr {
+		return fmt.Errorf("copy needs pointers, got %s and %s", d.Type(), s.Type())
+	}
+	return copyValue(d.Elem(), s.Elem())
+}
+
+func copyValue(dst reflect.Value, src reflect.Value) error {
+
+	if dst.Type() == src.Type() {
+		dst.Set(src)
+		return nil
+	}
+
+	switch {
+	case dst.Kind() == reflect.Ptr && src.Kind() == reflect.Ptr:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return copyValue(dst.Elem(), src.Elem())
+
+	case dst.Kind() == reflect.Struct && src.Kind() == reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			field := src.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			dstField := dst.FieldByName(field.Name)
+			if !dstField.IsValid() {
+				continue
+			}
+			if err := copyValue(dstField, src.Field(i)); err != nil {
+				return fmt.Errorf("%s: %v", field.Name, err)
+			}
+		}
+		return nil
+
+	case dst.Kind() == reflect.Slice && src.Kind() == reflect.Slice:
+		return copySlice(dst, src)
+
+	case isInt(dst.Kind()) && isInt(src.Kind()):
+		if dst.OverflowInt(src.Int()) {
+			return fmt.Errorf("%d is out of range for %s", src.Int(), dst.Type())
+		}
+		dst.SetInt(src.Int())
+		return nil
+
+	case isFloat(dst.Kind()) && isInt(src.Kind()):
+		// keep a fraction the older version had no room for
+		if math.Trunc(dst.Float()) != float64(src.Int()) {
+			dst.SetFloat(float64(src.Int()))
+		}
+		return nil
+
+	case isInt(dst.Kind()) && isFloat(src.Kind()):
+		value := math.Trunc(src.Float())
+		if value < math.MinInt64 || value > math.MaxInt64 || dst.OverflowInt(int64(value)) {
+			return fmt.Errorf("%v is out of range for %s", src.Float(), dst.Type())
+		}
+		dst.SetInt(int64(value))
+		return nil
+
+	case dst.Kind() == src.Kind() && src.Type().ConvertibleTo(dst.Type()):
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+
+	return fmt.Errorf("cannot convert %s to %s", src.Type(), dst.Type())
+}
+
+func copySlice(dst reflect.Value, src reflect.Value) error {
+
+	if src.IsNil() {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	key := itemKey(dst.Type().Elem())
+	used := make([]bool, dst.Len())
+	result := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+	for i := 0; i < src.Len(); i++ {
+		item := src.Index(i)
+		for j := 0; j < dst.Len(); j++ {
+			if used[j] {
+				continue
+			}
+			if key == "" && i == j || key != "" && dst.Index(j).FieldByName(key).String() == itemName(item, key) {
+				result.Index(i).Set(dst.Index(j))
+				used[j] = true
+				break
+			}
+		}
+		if err := copyValue(result.Index(i), item); err != nil {
+			return fmt.Errorf("[%d].%v", i, err)
+		}
+	}
+	dst.Set(result)
+	return nil
+}
+
+// itemKey returns the field identifying the items of a list, "" when they have none and
+// are matched by position
+func itemKey(t reflect.Type) string {
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+	for _, name := range []string{"Name", "Match"} {
+		if field, ok := t.FieldByName(name); ok && field.Type.Kind() == reflect.String {
+			return name
+		}
+	}
+	return ""
+}
+
+func itemName(item reflect.Value, key string) string {
+	if item.Kind() != reflect.Struct {
+		return ""
+	}
+	field := item.FieldByName(key)
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return ""
+	}
+	return field.String()
+}
+
+func isInt(k reflect.Kind) bool {
+	return k == reflect.Int || k == reflect.Int8 || k == reflect.Int16 || k == reflect.Int32 || k == reflect.Int64
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
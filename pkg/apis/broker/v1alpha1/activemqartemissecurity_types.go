@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -20,9 +21,11 @@ type ActiveMQArtemisSecuritySpec struct {
 }
 
 type LoginModulesType struct {
-	PropertiesLoginModules []PropertiesLoginModuleType `json:"propertiesLoginModules,omitempty"`
-	GuestLoginModules      []GuestLoginModuleType      `json:"guestLoginModules,omitempty"`
-	KeycloakLoginModules   []KeycloakLoginModuleType   `json:"keycloakLoginModules,omitempty"`
+	PropertiesLoginModules          []PropertiesLoginModuleType          `json:"propertiesLoginModules,omitempty"`
+	GuestLoginModules               []GuestLoginModuleType               `json:"guestLoginModules,omitempty"`
+	KeycloakLoginModules            []KeycloakLoginModuleType            `json:"keycloakLoginModules,omitempty"`
+	LDAPLoginModules                []LDAPLoginModuleType                `json:"ldapLoginModules,omitempty"`
+	TextFileCertificateLoginModules []TextFileCertificateLoginModuleType `json:"textFileCertificateLoginModules,omitempty"`
 }
 
 type PropertiesLoginModuleType struct {
@@ -31,9 +34,63 @@ type PropertiesLoginModuleType struct {
 }
 
 type UserType struct {
-	Name     string   `json:"name,omitempty"`
-	Password *string  `json:"password,omitempty"`
-	Roles    []string `json:"roles,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Password *string `json:"password,omitempty"`
+	// PasswordFrom reads the password from a secret rather than the CR, the operator
+	// watches the secret and passes on a new password to the brokers
+	PasswordFrom *PasswordSourceType `json:"passwordFrom,omitempty"`
+	Roles        []string            `json:"roles,omitempty"`
+}
+
+type PasswordSourceType struct {
+	// SecretKeyRef selects a key of a secret in the namespace of the CR
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// LDAPLoginModuleType authenticates users against an LDAP directory and reads their
+// roles from it
+type LDAPLoginModuleType struct {
+	Name string `json:"name,omitempty"`
+	// ConnectionURL is the url of the directory, ldap://host:389 for instance
+	ConnectionURL      string  `json:"connectionURL,omitempty"`
+	ConnectionUsername *string `json:"connectionUsername,omitempty"`
+	// ConnectionPasswordFrom reads the password the module binds with from a secret
+	ConnectionPasswordFrom *PasswordSourceType `json:"connectionPasswordFrom,omitempty"`
+	ConnectionProtocol     *string             `json:"connectionProtocol,omitempty"`
+	Authentication         *string             `json:"authentication,omitempty"`
+	// UserBase and UserSearchMatching find the entry of a user, {0} is the user name
+	UserBase           string `json:"userBase,omitempty"`
+	UserSearchMatching string `json:"userSearchMatching,omitempty"`
+	UserSearchSubtree  *bool  `json:"userSearchSubtree,omitempty"`
+	// RoleBase and RoleSearchMatching find the role entries of a user, {0} is the
+	// distinguished name and {1} the name of the user. RoleName is the attribute of a role
+	// entry holding the role name.
+	RoleBase           *string `json:"roleBase,omitempty"`
+	RoleName           *string `json:"roleName,omitempty"`
+	RoleSearchMatching *string `json:"roleSearchMatching,omitempty"`
+	RoleSearchSubtree  *bool   `json:"roleSearchSubtree,omitempty"`
+	// UserRoleName is an attribute of the user entry holding role names
+	UserRoleName *string `json:"userRoleName,omitempty"`
+	// ExpandRoles also grants the roles the roles of a user are members of
+	ExpandRoles                  *bool   `json:"expandRoles,omitempty"`
+	ExpandRolesMatching          *string `json:"expandRolesMatching,omitempty"`
+	Referral                     *string `json:"referral,omitempty"`
+	IgnorePartialResultException *bool   `json:"ignorePartialResultException,omitempty"`
+}
+
+// TextFileCertificateLoginModuleType authenticates clients by the subject of the
+// certificate they present to an acceptor requiring client authentication
+type TextFileCertificateLoginModuleType struct {
+	Name  string                `json:"name,omitempty"`
+	Users []CertificateUserType `json:"users,omitempty"`
+}
+
+type CertificateUserType struct {
+	Name string `json:"name,omitempty"`
+	// DN is the subject distinguished name of the certificate of the user, a regular
+	// expression when wrapped in slashes
+	DN    string   `json:"dn,omitempty"`
+	Roles []string `json:"roles,omitempty"`
 }
 
 type GuestLoginModuleType struct {
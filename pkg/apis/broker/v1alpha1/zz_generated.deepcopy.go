@@ -5,6 +5,7 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -223,6 +224,27 @@ func (in *BrokerSecuritySettingType) DeepCopy() *BrokerSecuritySettingType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateUserType) DeepCopyInto(out *CertificateUserType) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateUserType.
+func (in *CertificateUserType) DeepCopy() *CertificateUserType {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateUserType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConnectorConfigType) DeepCopyInto(out *ConnectorConfigType) {
 	*out = *in
@@ -629,6 +651,92 @@ func (in *KeycloakModuleConfigurationType) DeepCopy() *KeycloakModuleConfigurati
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPLoginModuleType) DeepCopyInto(out *LDAPLoginModuleType) {
+	*out = *in
+	if in.ConnectionUsername != nil {
+		in, out := &in.ConnectionUsername, &out.ConnectionUsername
+		*out = new(string)
+		**out = **in
+	}
+	if in.ConnectionPasswordFrom != nil {
+		in, out := &in.ConnectionPasswordFrom, &out.ConnectionPasswordFrom
+		*out = new(PasswordSourceType)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionProtocol != nil {
+		in, out := &in.ConnectionProtocol, &out.ConnectionProtocol
+		*out = new(string)
+		**out = **in
+	}
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(string)
+		**out = **in
+	}
+	if in.UserSearchSubtree != nil {
+		in, out := &in.UserSearchSubtree, &out.UserSearchSubtree
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RoleBase != nil {
+		in, out := &in.RoleBase, &out.RoleBase
+		*out = new(string)
+		**out = **in
+	}
+	if in.RoleName != nil {
+		in, out := &in.RoleName, &out.RoleName
+		*out = new(string)
+		**out = **in
+	}
+	if in.RoleSearchMatching != nil {
+		in, out := &in.RoleSearchMatching, &out.RoleSearchMatching
+		*out = new(string)
+		**out = **in
+	}
+	if in.RoleSearchSubtree != nil {
+		in, out := &in.RoleSearchSubtree, &out.RoleSearchSubtree
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UserRoleName != nil {
+		in, out := &in.UserRoleName, &out.UserRoleName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ExpandRoles != nil {
+		in, out := &in.ExpandRoles, &out.ExpandRoles
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExpandRolesMatching != nil {
+		in, out := &in.ExpandRolesMatching, &out.ExpandRolesMatching
+		*out = new(string)
+		**out = **in
+	}
+	if in.Referral != nil {
+		in, out := &in.Referral, &out.Referral
+		*out = new(string)
+		**out = **in
+	}
+	if in.IgnorePartialResultException != nil {
+		in, out := &in.IgnorePartialResultException, &out.IgnorePartialResultException
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPLoginModuleType.
+func (in *LDAPLoginModuleType) DeepCopy() *LDAPLoginModuleType {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPLoginModuleType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoginModuleReferenceType) DeepCopyInto(out *LoginModuleReferenceType) {
 	*out = *in
@@ -689,6 +797,20 @@ func (in *LoginModulesType) DeepCopyInto(out *LoginModulesType) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LDAPLoginModules != nil {
+		in, out := &in.LDAPLoginModules, &out.LDAPLoginModules
+		*out = make([]LDAPLoginModuleType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TextFileCertificateLoginModules != nil {
+		in, out := &in.TextFileCertificateLoginModules, &out.TextFileCertificateLoginModules
+		*out = make([]TextFileCertificateLoginModuleType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -725,6 +847,27 @@ func (in *ManagementSecuritySettingsType) DeepCopy() *ManagementSecuritySettings
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordSourceType) DeepCopyInto(out *PasswordSourceType) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordSourceType.
+func (in *PasswordSourceType) DeepCopy() *PasswordSourceType {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordSourceType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PermissionType) DeepCopyInto(out *PermissionType) {
 	*out = *in
@@ -844,6 +987,29 @@ func (in *SecuritySettingsType) DeepCopy() *SecuritySettingsType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TextFileCertificateLoginModuleType) DeepCopyInto(out *TextFileCertificateLoginModuleType) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]CertificateUserType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TextFileCertificateLoginModuleType.
+func (in *TextFileCertificateLoginModuleType) DeepCopy() *TextFileCertificateLoginModuleType {
+	if in == nil {
+		return nil
+	}
+	out := new(TextFileCertificateLoginModuleType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserType) DeepCopyInto(out *UserType) {
 	*out = *in
@@ -852,6 +1018,11 @@ func (in *UserType) DeepCopyInto(out *UserType) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.PasswordFrom != nil {
+		in, out := &in.PasswordFrom, &out.PasswordFrom
+		*out = new(PasswordSourceType)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Roles != nil {
 		in, out := &in.Roles, &out.Roles
 		*out = make([]string, len(*in))
@@ -0,0 +1,43 @@
+package v2alpha3
+
+import (
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/conversion"
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+)
+
+var _ conversion.Convertible = &ActiveMQArtemis{}
+
+// ConvertTo converts this ActiveMQArtemis to the v2alpha5 hub version
+func (src *ActiveMQArtemis) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*brokerv2alpha5.ActiveMQArtemis)
+	in := src.DeepCopy()
+
+	dst.ObjectMeta = in.ObjectMeta
+	dst.Spec = brokerv2alpha5.ActiveMQArtemisSpec{}
+	dst.Status = brokerv2alpha5.ActiveMQArtemisStatus{}
+	if err := conversion.RestoreHubSpec(&dst.ObjectMeta, &dst.Spec); err != nil {
+		return err
+	}
+	if err := conversion.Copy(&dst.Spec, &in.Spec); err != nil {
+		return err
+	}
+	return conversion.Copy(&dst.Status, &in.Status)
+}
+
+// ConvertFrom converts the v2alpha5 hub version to this ActiveMQArtemis. The status
+// fields added since v2alpha3 are dropped, the operator keeps them up to date.
+func (dst *ActiveMQArtemis) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*brokerv2alpha5.ActiveMQArtemis)
+	in := src.DeepCopy()
+
+	dst.ObjectMeta = in.ObjectMeta
+	dst.Spec = ActiveMQArtemisSpec{}
+	dst.Status = ActiveMQArtemisStatus{}
+	if err := conversion.Copy(&dst.Spec, &in.Spec); err != nil {
+		return err
+	}
+	if err := conversion.Copy(&dst.Status, &in.Status); err != nil {
+		return err
+	}
+	return conversion.SaveHubSpec(&dst.ObjectMeta, &dst.Spec, &src.Spec)
+}
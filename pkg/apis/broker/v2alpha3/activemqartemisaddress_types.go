@@ -20,6 +20,12 @@ type ActiveMQArtemisAddressSpec struct {
 	Password                 *string                 `json:"password,omitempty"`
 	QueueConfiguration       *QueueConfigurationType `json:"queueConfiguration,omitempty"`
 	ApplyToCrNames           []string                `json:"applyToCrNames,omitempty"`
+	// How often in seconds the address is checked against the live brokers so that
+	// missing queues are recreated. Defaults to 60, a value of 0 disables the check
+	ResyncPeriodSeconds *int32 `json:"resyncPeriodSeconds,omitempty"`
+	// When true the periodic check also removes idle durable anycast queues bound to
+	// the address that are not declared by any ActiveMQArtemisAddress in the namespace
+	StrictQueueManagement bool `json:"strictQueueManagement,omitempty"`
 }
 
 type QueueConfigurationType struct {
@@ -56,6 +62,28 @@ type ActiveMQArtemisAddressStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
 	// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
+
+	// Result of applying the address to each of the target broker pods
+	BrokerStatus []BrokerAddressStatus `json:"brokerStatus,omitempty"`
+}
+
+// BrokerAddressStatus is the observed state of the address on a single broker pod
+type BrokerAddressStatus struct {
+	// Name of the broker pod
+	PodName string `json:"podName"`
+	// Whether the address exists on the broker
+	AddressExists bool `json:"addressExists"`
+	// Whether the queue exists on the broker, always false when no queue name is specified
+	QueueExists bool `json:"queueExists"`
+	// The last error returned by the broker management api, empty if the last call succeeded
+	LastError string `json:"lastError,omitempty"`
+	// Time the broker was last checked
+	LastUpdated metav1.Time `json:"lastUpdated,omitempty"`
+	// Whether the last periodic check found the broker out of sync with the spec
+	DriftDetected bool `json:"driftDetected,omitempty"`
+	// Queues bound to the address that no ActiveMQArtemisAddress declares. With
+	// strictQueueManagement these are the queues removed by the last check
+	UnmanagedQueues []string `json:"unmanagedQueues,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -58,7 +58,7 @@ func (in *ActiveMQArtemisAddress) DeepCopyInto(out *ActiveMQArtemisAddress) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -146,6 +146,11 @@ func (in *ActiveMQArtemisAddressSpec) DeepCopyInto(out *ActiveMQArtemisAddressSp
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ResyncPeriodSeconds != nil {
+		in, out := &in.ResyncPeriodSeconds, &out.ResyncPeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -162,6 +167,13 @@ func (in *ActiveMQArtemisAddressSpec) DeepCopy() *ActiveMQArtemisAddressSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ActiveMQArtemisAddressStatus) DeepCopyInto(out *ActiveMQArtemisAddressStatus) {
 	*out = *in
+	if in.BrokerStatus != nil {
+		in, out := &in.BrokerStatus, &out.BrokerStatus
+		*out = make([]BrokerAddressStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -620,6 +632,28 @@ func (in *AddressSettingsType) DeepCopy() *AddressSettingsType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerAddressStatus) DeepCopyInto(out *BrokerAddressStatus) {
+	*out = *in
+	in.LastUpdated.DeepCopyInto(&out.LastUpdated)
+	if in.UnmanagedQueues != nil {
+		in, out := &in.UnmanagedQueues, &out.UnmanagedQueues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerAddressStatus.
+func (in *BrokerAddressStatus) DeepCopy() *BrokerAddressStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerAddressStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConnectorType) DeepCopyInto(out *ConnectorType) {
 	*out = *in
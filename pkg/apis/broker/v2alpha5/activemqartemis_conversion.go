@@ -0,0 +1,10 @@
+package v2alpha5
+
+import (
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/conversion"
+)
+
+var _ conversion.Hub = &ActiveMQArtemis{}
+
+// Hub marks v2alpha5 as the version the other ActiveMQArtemis versions convert through
+func (*ActiveMQArtemis) Hub() {}
@@ -4,6 +4,7 @@ import (
 	"github.com/RHsyseng/operator-utils/pkg/olm"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -26,6 +27,8 @@ type ActiveMQArtemisSpec struct {
 	Upgrades       ActiveMQArtemisUpgrades `json:"upgrades,omitempty"`
 	//below are v2alpha4 types
 	AddressSettings AddressSettingsType `json:"addressSettings,omitempty"`
+	// Monitoring has the brokers scraped by a prometheus operator, it needs the metrics plugin
+	Monitoring *MonitoringType `json:"monitoring,omitempty"`
 }
 
 type AddressSettingsType struct {
@@ -118,15 +121,80 @@ type DeploymentPlanType struct {
 	PodSecurity           PodSecurityType             `json:"podSecurity,omitempty"`
 	LivenessProbe         LivenessProbeType           `json:"livenessProbe,omitempty"`
 	ReadinessProbe        ReadinessProbeType          `json:"readinessProbe,omitempty"`
-	EnableMetricsPlugin   *bool                       `json:"enableMetricsPlugin,omitempty"`
+	// ExtraLivenessDelaySeconds is added to the initial delay of the liveness probe, giving
+	// the broker time to recover a large journal before the probe may fail. The Kubernetes
+	// API the operator is built against has no startup probes.
+	ExtraLivenessDelaySeconds *int32 `json:"extraLivenessDelaySeconds,omitempty"`
+	EnableMetricsPlugin       *bool  `json:"enableMetricsPlugin,omitempty"`
+	// Affinity, Tolerations, NodeSelector and PriorityClassName are set as they are on
+	// the pods of the broker statefulset
+	Affinity          *corev1.Affinity    `json:"affinity,omitempty"`
+	Tolerations       []corev1.Toleration `json:"tolerations,omitempty"`
+	NodeSelector      map[string]string   `json:"nodeSelector,omitempty"`
+	PriorityClassName string              `json:"priorityClassName,omitempty"`
+	// PodTemplate is merged onto the generated pod template as a strategic merge patch,
+	// it may not change the init container, the broker container other than adding env
+	// vars and volume mounts, nor the volumes of the operator
+	PodTemplate *corev1.PodTemplateSpec `json:"podTemplate,omitempty"`
+	// PodDisruptionBudget of the broker pods, clustered deployments of more than one broker
+	// get one with maxUnavailable 1 when it is not set
+	PodDisruptionBudget *PodDisruptionBudgetType `json:"podDisruptionBudget,omitempty"`
+	// Autoscaling makes a horizontal pod autoscaler scale the deployment through the scale
+	// subresource of the custom resource, setting its size
+	Autoscaling *AutoscalingType `json:"autoscaling,omitempty"`
+	// ClusterTLS encrypts the cluster connections between the brokers with certificates of
+	// an internal ca the operator generates for each pod and rotates before they expire
+	ClusterTLS bool `json:"clusterTLS,omitempty"`
 }
 
+// AutoscalingType is the range the brokers are scaled in and the metrics they are scaled on
+type AutoscalingType struct {
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	MaxReplicas int32  `json:"maxReplicas"`
+	// Metrics are averaged over the broker pods, the deployment is scaled so that none of
+	// the averages exceeds its target
+	Metrics []BrokerMetricType `json:"metrics,omitempty"`
+	// TargetCPUUtilizationPercentage is the average cpu usage of the broker pods aimed for,
+	// as a percentage of the cpu they request
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+}
+
+// BrokerMetricType is a metric of the broker pods served by the custom metrics api, such as
+// the total number of pending messages or of connections exported by the metrics plugin
+type BrokerMetricType struct {
+	Name               string `json:"name"`
+	TargetAverageValue string `json:"targetAverageValue"`
+}
+
+// PodDisruptionBudgetType is the pod disruption budget of the broker pods, at most one of
+// MinAvailable and MaxUnavailable may be set
+type PodDisruptionBudgetType struct {
+	// Enabled set to false deploys the brokers without a pod disruption budget
+	Enabled        *bool               `json:"enabled,omitempty"`
+	MinAvailable   *intstr.IntOrString `json:"minAvailable,omitempty"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// LivenessProbeType overrides the liveness probe of the broker container, the handler
+// replaces the tcp check of the console port when one is set
 type LivenessProbeType struct {
-	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	corev1.Handler      `json:",inline"`
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	TimeoutSeconds      *int32 `json:"timeoutSeconds,omitempty"`
+	PeriodSeconds       *int32 `json:"periodSeconds,omitempty"`
+	SuccessThreshold    *int32 `json:"successThreshold,omitempty"`
+	FailureThreshold    *int32 `json:"failureThreshold,omitempty"`
 }
 
+// ReadinessProbeType overrides the readiness probe of the broker container, the handler
+// replaces the readiness script of the broker image when one is set
 type ReadinessProbeType struct {
-	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+	corev1.Handler      `json:",inline"`
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+	TimeoutSeconds      *int32 `json:"timeoutSeconds,omitempty"`
+	PeriodSeconds       *int32 `json:"periodSeconds,omitempty"`
+	SuccessThreshold    *int32 `json:"successThreshold,omitempty"`
+	FailureThreshold    *int32 `json:"failureThreshold,omitempty"`
 }
 
 type PodSecurityType struct {
@@ -140,7 +208,31 @@ type ExtraMountsType struct {
 }
 
 type StorageType struct {
+	// Size requested by the claim of each broker. The claims of running brokers are
+	// expanded when it grows, provided their storage class allows volume expansion.
 	Size string `json:"size,omitempty"`
+	// StorageClassName, AccessModes and Selector are set as they are on the claims of the
+	// brokers. Kubernetes can't change these once the statefulset created the claims, later
+	// changes are not applied and reported with a StorageNotApplied event.
+	StorageClassName *string                             `json:"storageClassName,omitempty"`
+	AccessModes      []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+	Selector         *metav1.LabelSelector               `json:"selector,omitempty"`
+	// Labels and Annotations are added to the claims of the brokers, the labels of the
+	// operator take precedence. Like the above, they are not changed later.
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Volumes moves data directories of the brokers off the data volume, each onto a
+	// claim of its own. The directories can't change once the statefulset is created.
+	Volumes []DataVolumeType `json:"volumes,omitempty"`
+}
+
+// DataVolumeType is a volume holding one of the data directories of a broker
+type DataVolumeType struct {
+	// Directory is one of journal, bindings, paging or largeMessages
+	Directory        string                              `json:"directory"`
+	Size             string                              `json:"size,omitempty"`
+	StorageClassName *string                             `json:"storageClassName,omitempty"`
+	AccessModes      []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
 }
 
 type AcceptorType struct {
@@ -163,8 +255,37 @@ type AcceptorType struct {
 	AMQPMinLargeMessageSize           int    `json:"amqpMinLargeMessageSize,omitempty"`
 	SupportAdvisory                   *bool  `json:"supportAdvisory,omitempty"`
 	SuppressInternalManagementObjects *bool  `json:"suppressInternalManagementObjects,omitempty"`
+	// IngressHost of the ingress exposing the acceptor on kubernetes, see ConsoleType
+	IngressHost string `json:"ingressHost,omitempty"`
+	// IngressClassName of the ingress controller serving the ingress. The ingress stays on
+	// extensions/v1beta1 until the client-go upgrade, which has no class name field, so it
+	// is set as the kubernetes.io/ingress.class annotation
+	IngressClassName string `json:"ingressClassName,omitempty"`
+	// IngressAnnotations are added to the ingress, for the ingress controller to pass the
+	// tls connections through for instance
+	IngressAnnotations map[string]string `json:"ingressAnnotations,omitempty"`
+	// IngressTLSSecret holds the certificate the ingress terminates tls with
+	IngressTLSSecret string `json:"ingressTlsSecret,omitempty"`
+	// ExposeMode is how the exposed acceptor is reached from outside the cluster, a route
+	// on OpenShift and an ingress otherwise when empty
+	ExposeMode ExposeMode `json:"exposeMode,omitempty"`
+	// CertManager requests the certificate of the acceptor from cert-manager, issued into
+	// its ssl secret
+	CertManager *CertManagerType `json:"certManager,omitempty"`
 }
 
+// ExposeMode is how an exposed acceptor is reached from outside the cluster. Each broker
+// is exposed on its own, through a route or ingress of its service or by giving the
+// service a load balancer or node port
+type ExposeMode string
+
+const (
+	ExposeModeRoute        ExposeMode = "route"
+	ExposeModeIngress      ExposeMode = "ingress"
+	ExposeModeLoadBalancer ExposeMode = "loadBalancer"
+	ExposeModeNodePort     ExposeMode = "nodePort"
+)
+
 type ConnectorType struct {
 	Name                string `json:"name"`
 	Type                string `json:"type,omitempty"`
@@ -180,6 +301,9 @@ type ConnectorType struct {
 	SSLProvider         string `json:"sslProvider,omitempty"`
 	SNIHost             string `json:"sniHost,omitempty"`
 	Expose              bool   `json:"expose,omitempty"`
+	// CertManager requests the certificate of the connector from cert-manager, issued into
+	// its ssl secret
+	CertManager *CertManagerType `json:"certManager,omitempty"`
 }
 
 type ConsoleType struct {
@@ -187,6 +311,101 @@ type ConsoleType struct {
 	SSLEnabled    bool   `json:"sslEnabled,omitempty"`
 	SSLSecret     string `json:"sslSecret,omitempty"`
 	UseClientAuth bool   `json:"useClientAuth,omitempty"`
+	// IngressHost of the ingress exposing the console on kubernetes. Each broker has an
+	// ingress of its own, $(CR_NAME), $(CR_NAMESPACE), $(BROKER_ORDINAL) and $(ITEM_NAME)
+	// are replaced to give them distinct hosts
+	IngressHost string `json:"ingressHost,omitempty"`
+	// IngressClassName of the ingress controller serving the ingress, see AcceptorType
+	IngressClassName string `json:"ingressClassName,omitempty"`
+	// IngressAnnotations are added to the ingress
+	IngressAnnotations map[string]string `json:"ingressAnnotations,omitempty"`
+	// IngressTLSSecret holds the certificate the ingress terminates tls with
+	IngressTLSSecret string `json:"ingressTlsSecret,omitempty"`
+	// CertManager requests the certificate of the console from cert-manager, issued into
+	// its ssl secret
+	CertManager *CertManagerType `json:"certManager,omitempty"`
+	// OAuthProxy fronts the exposed console of each broker with a proxy the users log in
+	// through, in place of the console login
+	OAuthProxy *ConsoleOAuthProxyType `json:"oauthProxy,omitempty"`
+}
+
+// ConsoleOAuthProxyType is the proxy sidecar logging the users of the console in with the
+// OpenShift oauth server or an OIDC provider. The proxy passes the users on to the console,
+// which gives them the hawtio roles they are mapped to and refuses the others. The admin
+// user of the brokers keeps logging in to the console with its password.
+//
+// Only the console service and route lead to the proxy. The console port stays open on the
+// pod ip for the jolokia requests of the operator and the metrics scrapes, restrict it with
+// a network policy to keep the users from reaching it directly within the cluster.
+type ConsoleOAuthProxyType struct {
+	// Provider the users log in with, openshift or oidc
+	Provider string `json:"provider"`
+	// Image of the proxy, the OpenShift oauth-proxy or oauth2-proxy image when empty
+	Image string `json:"image,omitempty"`
+	// IssuerURL of the OIDC provider
+	IssuerURL string `json:"issuerUrl,omitempty"`
+	// ClientID and ClientSecret the proxy is registered with at the OIDC provider. With
+	// the openshift provider the proxy is registered through the service account of the
+	// pods, which the operator creates unless the pod security names one, that must then
+	// carry the oauth redirect references of the console routes.
+	ClientID     string                    `json:"clientId,omitempty"`
+	ClientSecret *corev1.SecretKeySelector `json:"clientSecret,omitempty"`
+	// RoleMappings give the users the hawtio roles of the management security settings
+	RoleMappings []ConsoleRoleMappingType `json:"roleMappings"`
+}
+
+// ConsoleRoleMappingType gives users logged in through the proxy a hawtio role
+type ConsoleRoleMappingType struct {
+	Role string `json:"role"`
+	// Users are OpenShift user names, or the emails of OIDC users
+	Users []string `json:"users"`
+}
+
+// CertManagerType is the cert-manager certificate of an acceptor, connector or the console.
+// The pem certificate and key cert-manager issues into the ssl secret, as in any secret of
+// type kubernetes.io/tls, are converted to PKCS#12 stores the broker loads, kept in the
+// <cr>-<name>-stores secret of the operator, and the brokers are restarted when the
+// certificate is renewed. Secrets not issued by cert-manager are to be labelled
+// ActiveMQArtemis=<cr> for their renewals to be picked up before the next resync.
+type CertManagerType struct {
+	// IssuerRef is the issuer or cluster issuer of the certificate
+	IssuerRef IssuerReference `json:"issuerRef"`
+	// DNSNames of the certificate, the broker pods by their headless service when empty
+	DNSNames []string `json:"dnsNames,omitempty"`
+	// Duration and RenewBefore of the certificate, the cert-manager defaults when empty
+	Duration    string `json:"duration,omitempty"`
+	RenewBefore string `json:"renewBefore,omitempty"`
+}
+
+// IssuerReference is a cert-manager issuer
+type IssuerReference struct {
+	Name string `json:"name"`
+	// Kind is Issuer or ClusterIssuer, Issuer when empty
+	Kind string `json:"kind,omitempty"`
+	// Group of the issuer, cert-manager.io when empty
+	Group string `json:"group,omitempty"`
+}
+
+// MonitoringType is how the prometheus operator scrapes the metrics of the brokers and
+// alerts on them
+type MonitoringType struct {
+	// Enabled creates a metrics service and a service monitor for the brokers
+	Enabled bool `json:"enabled,omitempty"`
+	// Interval between scrapes, the prometheus default when empty
+	Interval string `json:"interval,omitempty"`
+	// Labels are added to the service monitor and prometheus rule, for a prometheus to
+	// select them
+	Labels map[string]string `json:"labels,omitempty"`
+	// Alerts creates a prometheus rule with the default broker alerts
+	Alerts *AlertsType `json:"alerts,omitempty"`
+}
+
+// AlertsType is the prometheus rule with the default broker alerts
+type AlertsType struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// AddressMemoryUsagePercentage of the global max size over which the address memory
+	// alert fires. Default 90
+	AddressMemoryUsagePercentage *int32 `json:"addressMemoryUsagePercentage,omitempty"`
 }
 
 // ActiveMQArtemis App product upgrade flags
@@ -202,6 +421,66 @@ type ActiveMQArtemisStatus struct {
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
 	// Add custom validation using kubebuilder tags: https://book.kubebuilder.io/beyond_basics/generating_crd.html
 	PodStatus olm.DeploymentStatus `json:"podStatus"`
+
+	// The generation of the custom resource most recently acted on by the operator
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// The current state of the operator state machine for this deployment, one of
+	// creating_k8s_resources, scaling or running
+	FSMState string `json:"fsmState,omitempty"`
+	// Current conditions of the deployment, see ConditionType for the known types
+	Conditions []Condition `json:"conditions,omitempty"`
+	// The number of broker pods, and the label selector of these, read through the scale
+	// subresource
+	Replicas int32  `json:"replicas,omitempty"`
+	Selector string `json:"selector,omitempty"`
+	// The addresses clients outside the cluster connect to the acceptors exposed by load
+	// balancer or node port on
+	ExternalAddresses []ExternalAddress `json:"externalAddresses,omitempty"`
+}
+
+// ExternalAddress is where an acceptor of a broker is reached from outside the cluster
+type ExternalAddress struct {
+	Acceptor string `json:"acceptor"`
+	Pod      string `json:"pod"`
+	// Host is the load balancer ip or hostname, or the ip of the node of the broker for a
+	// node port
+	Host string `json:"host"`
+	Port int32  `json:"port"`
+}
+
+// ConditionType is the type of a status condition
+type ConditionType string
+
+const (
+	// The kubernetes resources backing the broker deployment have been created
+	ConditionTypeDeployed ConditionType = "Deployed"
+	// All the requested broker pods are ready
+	ConditionTypeReady ConditionType = "Ready"
+	// The latest spec has been rolled out to every broker pod
+	ConditionTypeConfigApplied ConditionType = "ConfigApplied"
+	// The broker image is being changed on the running pods
+	ConditionTypeUpgrading ConditionType = "Upgrading"
+	// The spec passed the operator validation checks
+	ConditionTypeValid ConditionType = "Valid"
+	// The persistent volume claims of the brokers have the requested storage size
+	ConditionTypeStorageResized ConditionType = "StorageResized"
+)
+
+// Condition describes one aspect of the observed state of a resource
+// +k8s:openapi-gen=true
+type Condition struct {
+	// Type of the condition
+	Type ConditionType `json:"type"`
+	// Status of the condition, one of True, False or Unknown
+	Status corev1.ConditionStatus `json:"status"`
+	// The generation of the custom resource the condition was computed from
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Last time the condition changed status
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// A CamelCase reason for the last transition
+	Reason string `json:"reason,omitempty"`
+	// A human readable message about the last transition
+	Message string `json:"message,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,83 @@
+package v2alpha5
+
+import (
+	"strings"
+)
+
+const (
+	// Acceptors without a port are given one from DefaultAcceptorPortBase upwards,
+	// DefaultAcceptorPortIncrement apart
+	DefaultAcceptorPortBase      int32 = 61626
+	DefaultAcceptorPortIncrement int32 = 10
+	// The port the broker pods use to talk to each other, it always accepts CORE
+	ClusterAcceptorPort int32 = 61616
+	// The port the broker pods talk to each other over tls on, with cluster tls enabled
+	ClusterTLSAcceptorPort int32 = 61617
+	// The port the oauth proxy of the console listens on
+	ConsoleOAuthProxyPort int32 = 4180
+	// The console oauth proxy providers
+	ConsoleOAuthProviderOpenShift = "openshift"
+	ConsoleOAuthProviderOIDC      = "oidc"
+	// The protocols of an acceptor with no protocols or "all"
+	DefaultAcceptorProtocols = "AMQP,CORE,HORNETQ,MQTT,OPENWIRE,STOMP"
+	DefaultConnectorType     = "tcp"
+	DefaultJournalType       = "nio"
+	DefaultStorageSize       = "2Gi"
+	DefaultApplyRule         = "merge_all"
+)
+
+// SetDefaults_ActiveMQArtemis fills in the values the operator would otherwise pick on
+// its own while reconciling, so that they show in the stored spec and stay the same
+// from one reconcile to the next. The images depend on the environment of the operator,
+// the controller stores them and keeps them following its supported versions.
+func SetDefaults_ActiveMQArtemis(obj *ActiveMQArtemis) {
+
+	plan := &obj.Spec.DeploymentPlan
+	if plan.Clustered == nil {
+		clustered := true
+		plan.Clustered = &clustered
+	}
+	if plan.MessageMigration == nil {
+		messageMigration := true
+		plan.MessageMigration = &messageMigration
+	}
+	if plan.JournalType == "" {
+		plan.JournalType = DefaultJournalType
+	}
+	if plan.PersistenceEnabled && plan.Storage.Size == "" {
+		plan.Storage.Size = DefaultStorageSize
+	}
+
+	usedPorts := make(map[int32]bool)
+	for _, acceptor := range obj.Spec.Acceptors {
+		usedPorts[acceptor.Port] = true
+	}
+	nextPort := DefaultAcceptorPortBase
+	for i := range obj.Spec.Acceptors {
+		acceptor := &obj.Spec.Acceptors[i]
+		if acceptor.Port == 0 {
+			for usedPorts[nextPort] {
+				nextPort += DefaultAcceptorPortIncrement
+			}
+			acceptor.Port = nextPort
+			usedPorts[nextPort] = true
+		}
+		if acceptor.Protocols == "" || strings.ToLower(acceptor.Protocols) == "all" {
+			acceptor.Protocols = DefaultAcceptorProtocols
+		}
+		if acceptor.Port == ClusterAcceptorPort && !strings.Contains(strings.ToUpper(acceptor.Protocols), "CORE") {
+			acceptor.Protocols = acceptor.Protocols + ",CORE"
+		}
+	}
+
+	for i := range obj.Spec.Connectors {
+		if obj.Spec.Connectors[i].Type == "" {
+			obj.Spec.Connectors[i].Type = DefaultConnectorType
+		}
+	}
+
+	if len(obj.Spec.AddressSettings.AddressSetting) > 0 && obj.Spec.AddressSettings.ApplyRule == nil {
+		applyRule := DefaultApplyRule
+		obj.Spec.AddressSettings.ApplyRule = &applyRule
+	}
+}
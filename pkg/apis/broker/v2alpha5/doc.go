@@ -1,4 +1,5 @@
 // Package v2alpha5 contains API Schema definitions for the broker v2alpha5 API group
 // +k8s:deepcopy-gen=package,register
+// +k8s:defaulter-gen=TypeMeta
 // +groupName=broker.amq.io
 package v2alpha5
@@ -5,7 +5,10 @@
 package v2alpha5
 
 import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -21,6 +24,18 @@ func (in *AcceptorType) DeepCopyInto(out *AcceptorType) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.IngressAnnotations != nil {
+		in, out := &in.IngressAnnotations, &out.IngressAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerType)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -109,11 +124,18 @@ func (in *ActiveMQArtemisSpec) DeepCopyInto(out *ActiveMQArtemisSpec) {
 	if in.Connectors != nil {
 		in, out := &in.Connectors, &out.Connectors
 		*out = make([]ConnectorType, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out.Console = in.Console
+	in.Console.DeepCopyInto(&out.Console)
 	out.Upgrades = in.Upgrades
 	in.AddressSettings.DeepCopyInto(&out.AddressSettings)
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringType)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -131,6 +153,18 @@ func (in *ActiveMQArtemisSpec) DeepCopy() *ActiveMQArtemisSpec {
 func (in *ActiveMQArtemisStatus) DeepCopyInto(out *ActiveMQArtemisStatus) {
 	*out = *in
 	in.PodStatus.DeepCopyInto(&out.PodStatus)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExternalAddresses != nil {
+		in, out := &in.ExternalAddresses, &out.ExternalAddresses
+		*out = make([]ExternalAddress, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -524,9 +558,121 @@ func (in *AddressSettingsType) DeepCopy() *AddressSettingsType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertsType) DeepCopyInto(out *AlertsType) {
+	*out = *in
+	if in.AddressMemoryUsagePercentage != nil {
+		in, out := &in.AddressMemoryUsagePercentage, &out.AddressMemoryUsagePercentage
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertsType.
+func (in *AlertsType) DeepCopy() *AlertsType {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertsType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalingType) DeepCopyInto(out *AutoscalingType) {
+	*out = *in
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = make([]BrokerMetricType, len(*in))
+		copy(*out, *in)
+	}
+	if in.TargetCPUUtilizationPercentage != nil {
+		in, out := &in.TargetCPUUtilizationPercentage, &out.TargetCPUUtilizationPercentage
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalingType.
+func (in *AutoscalingType) DeepCopy() *AutoscalingType {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalingType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BrokerMetricType) DeepCopyInto(out *BrokerMetricType) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BrokerMetricType.
+func (in *BrokerMetricType) DeepCopy() *BrokerMetricType {
+	if in == nil {
+		return nil
+	}
+	out := new(BrokerMetricType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerType) DeepCopyInto(out *CertManagerType) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerType.
+func (in *CertManagerType) DeepCopy() *CertManagerType {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConnectorType) DeepCopyInto(out *ConnectorType) {
 	*out = *in
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerType)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -540,9 +686,75 @@ func (in *ConnectorType) DeepCopy() *ConnectorType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsoleOAuthProxyType) DeepCopyInto(out *ConsoleOAuthProxyType) {
+	*out = *in
+	if in.ClientSecret != nil {
+		in, out := &in.ClientSecret, &out.ClientSecret
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RoleMappings != nil {
+		in, out := &in.RoleMappings, &out.RoleMappings
+		*out = make([]ConsoleRoleMappingType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsoleOAuthProxyType.
+func (in *ConsoleOAuthProxyType) DeepCopy() *ConsoleOAuthProxyType {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsoleOAuthProxyType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsoleRoleMappingType) DeepCopyInto(out *ConsoleRoleMappingType) {
+	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsoleRoleMappingType.
+func (in *ConsoleRoleMappingType) DeepCopy() *ConsoleRoleMappingType {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsoleRoleMappingType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConsoleType) DeepCopyInto(out *ConsoleType) {
 	*out = *in
+	if in.IngressAnnotations != nil {
+		in, out := &in.IngressAnnotations, &out.IngressAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerType)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OAuthProxy != nil {
+		in, out := &in.OAuthProxy, &out.OAuthProxy
+		*out = new(ConsoleOAuthProxyType)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -556,6 +768,32 @@ func (in *ConsoleType) DeepCopy() *ConsoleType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataVolumeType) DeepCopyInto(out *DataVolumeType) {
+	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataVolumeType.
+func (in *DataVolumeType) DeepCopy() *DataVolumeType {
+	if in == nil {
+		return nil
+	}
+	out := new(DataVolumeType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentPlanType) DeepCopyInto(out *DeploymentPlanType) {
 	*out = *in
@@ -565,7 +803,7 @@ func (in *DeploymentPlanType) DeepCopyInto(out *DeploymentPlanType) {
 		**out = **in
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
-	out.Storage = in.Storage
+	in.Storage.DeepCopyInto(&out.Storage)
 	in.ExtraMounts.DeepCopyInto(&out.ExtraMounts)
 	if in.Clustered != nil {
 		in, out := &in.Clustered, &out.Clustered
@@ -575,11 +813,50 @@ func (in *DeploymentPlanType) DeepCopyInto(out *DeploymentPlanType) {
 	in.PodSecurity.DeepCopyInto(&out.PodSecurity)
 	in.LivenessProbe.DeepCopyInto(&out.LivenessProbe)
 	in.ReadinessProbe.DeepCopyInto(&out.ReadinessProbe)
+	if in.ExtraLivenessDelaySeconds != nil {
+		in, out := &in.ExtraLivenessDelaySeconds, &out.ExtraLivenessDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
 	if in.EnableMetricsPlugin != nil {
 		in, out := &in.EnableMetricsPlugin, &out.EnableMetricsPlugin
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(v1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(PodDisruptionBudgetType)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AutoscalingType)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -593,6 +870,22 @@ func (in *DeploymentPlanType) DeepCopy() *DeploymentPlanType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalAddress) DeepCopyInto(out *ExternalAddress) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalAddress.
+func (in *ExternalAddress) DeepCopy() *ExternalAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExtraMountsType) DeepCopyInto(out *ExtraMountsType) {
 	*out = *in
@@ -619,14 +912,51 @@ func (in *ExtraMountsType) DeepCopy() *ExtraMountsType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IssuerReference) DeepCopyInto(out *IssuerReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IssuerReference.
+func (in *IssuerReference) DeepCopy() *IssuerReference {
+	if in == nil {
+		return nil
+	}
+	out := new(IssuerReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LivenessProbeType) DeepCopyInto(out *LivenessProbeType) {
 	*out = *in
+	in.Handler.DeepCopyInto(&out.Handler)
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
 	if in.TimeoutSeconds != nil {
 		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
 		*out = new(int32)
 		**out = **in
 	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -640,6 +970,65 @@ func (in *LivenessProbeType) DeepCopy() *LivenessProbeType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringType) DeepCopyInto(out *MonitoringType) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = new(AlertsType)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringType.
+func (in *MonitoringType) DeepCopy() *MonitoringType {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringType)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodDisruptionBudgetType) DeepCopyInto(out *PodDisruptionBudgetType) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodDisruptionBudgetType.
+func (in *PodDisruptionBudgetType) DeepCopy() *PodDisruptionBudgetType {
+	if in == nil {
+		return nil
+	}
+	out := new(PodDisruptionBudgetType)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodSecurityType) DeepCopyInto(out *PodSecurityType) {
 	*out = *in
@@ -669,11 +1058,32 @@ func (in *PodSecurityType) DeepCopy() *PodSecurityType {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReadinessProbeType) DeepCopyInto(out *ReadinessProbeType) {
 	*out = *in
+	in.Handler.DeepCopyInto(&out.Handler)
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
 	if in.TimeoutSeconds != nil {
 		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
 		*out = new(int32)
 		**out = **in
 	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
 	return
 }
 
@@ -690,6 +1100,42 @@ func (in *ReadinessProbeType) DeepCopy() *ReadinessProbeType {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageType) DeepCopyInto(out *StorageType) {
 	*out = *in
+	if in.StorageClassName != nil {
+		in, out := &in.StorageClassName, &out.StorageClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.AccessModes != nil {
+		in, out := &in.AccessModes, &out.AccessModes
+		*out = make([]v1.PersistentVolumeAccessMode, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]DataVolumeType, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -28,5 +28,18 @@ import (
 // Public to allow building arbitrary schemes.
 // All generated defaulters are covering - they call all nested defaulters.
 func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&ActiveMQArtemis{}, func(obj interface{}) { SetObjectDefaults_ActiveMQArtemis(obj.(*ActiveMQArtemis)) })
+	scheme.AddTypeDefaultingFunc(&ActiveMQArtemisList{}, func(obj interface{}) { SetObjectDefaults_ActiveMQArtemisList(obj.(*ActiveMQArtemisList)) })
 	return nil
 }
+
+func SetObjectDefaults_ActiveMQArtemis(in *ActiveMQArtemis) {
+	SetDefaults_ActiveMQArtemis(in)
+}
+
+func SetObjectDefaults_ActiveMQArtemisList(in *ActiveMQArtemisList) {
+	for i := range in.Items {
+		a := &in.Items[i]
+		SetObjectDefaults_ActiveMQArtemis(a)
+	}
+}
@@ -22,6 +22,7 @@ func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenA
 		"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5.ActiveMQArtemisScaledownStatus": schema_pkg_apis_broker_v2alpha5_ActiveMQArtemisScaledownStatus(ref),
 		"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5.ActiveMQArtemisSpec":            schema_pkg_apis_broker_v2alpha5_ActiveMQArtemisSpec(ref),
 		"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5.ActiveMQArtemisStatus":          schema_pkg_apis_broker_v2alpha5_ActiveMQArtemisStatus(ref),
+		"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5.Condition":                      schema_pkg_apis_broker_v2alpha5_Condition(ref),
 	}
 }
 
@@ -326,11 +327,94 @@ func schema_pkg_apis_broker_v2alpha5_ActiveMQArtemisStatus(ref common.ReferenceC
 							Ref:         ref("github.com/RHsyseng/operator-utils/pkg/olm.DeploymentStatus"),
 						},
 					},
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The generation of the custom resource most recently acted on by the operator",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"fsmState": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The current state of the operator state machine for this deployment, one of creating_k8s_resources, scaling or running",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"conditions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Current conditions of the deployment, see ConditionType for the known types",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Ref: ref("github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5.Condition"),
+									},
+								},
+							},
+						},
+					},
 				},
 				Required: []string{"podStatus"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/RHsyseng/operator-utils/pkg/olm.DeploymentStatus"},
+			"github.com/RHsyseng/operator-utils/pkg/olm.DeploymentStatus", "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5.Condition"},
+	}
+}
+
+func schema_pkg_apis_broker_v2alpha5_Condition(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Condition describes one aspect of the observed state of a resource",
+				Properties: map[string]spec.Schema{
+					"type": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Type of the condition",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"status": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Status of the condition, one of True, False or Unknown",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"observedGeneration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "The generation of the custom resource the condition was computed from",
+							Type:        []string{"integer"},
+							Format:      "int64",
+						},
+					},
+					"lastTransitionTime": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Last time the condition changed status",
+							Ref:         ref("k8s.io/apimachinery/pkg/apis/meta/v1.Time"),
+						},
+					},
+					"reason": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A CamelCase reason for the last transition",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"message": {
+						SchemaProps: spec.SchemaProps{
+							Description: "A human readable message about the last transition",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"type", "status"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/apimachinery/pkg/apis/meta/v1.Time"},
 	}
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/environments"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/escape"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/lsrcrs"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/random"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/selectors"
@@ -17,6 +18,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -38,12 +40,13 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+	recorder := mgr.GetRecorder("v1alpha1activemqartemissecurity-controller")
 	noCacheClient, err := client.New(mgr.GetConfig(), client.Options{})
 	if err == nil {
-		return &ReconcileActiveMQArtemisSecurity{client: noCacheClient, scheme: mgr.GetScheme()}
+		return &ReconcileActiveMQArtemisSecurity{client: noCacheClient, scheme: mgr.GetScheme(), recorder: recorder}
 	}
 	log.Info("Using manager's client")
-	return &ReconcileActiveMQArtemisSecurity{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileActiveMQArtemisSecurity{client: mgr.GetClient(), scheme: mgr.GetScheme(), recorder: recorder}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -60,6 +63,14 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to the secrets users read their passwords from
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: secretToSecurityRequests(mgr.GetClient()),
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -68,8 +79,9 @@ var _ reconcile.Reconciler = &ReconcileActiveMQArtemisSecurity{}
 type ReconcileActiveMQArtemisSecurity struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
 func (r *ReconcileActiveMQArtemisSecurity) Reconcile(request reconcile.Request) (reconcile.Result, error) {
@@ -94,26 +106,55 @@ func (r *ReconcileActiveMQArtemisSecurity) Reconcile(request reconcile.Request)
 		return reconcile.Result{}, err
 	}
 
+	securityHandler := &ActiveMQArtemisSecurityConfigHandler{
+		instance,
+		request.NamespacedName,
+		r,
+	}
+	passwords, err := securityHandler.resolveSecretPasswords()
+	if err != nil {
+		log.Error(err, "failed to read user passwords", "request", request.NamespacedName)
+		return reconcile.Result{}, err
+	}
+	digests, merr := common.ToJson(passwordDigests(passwords))
+	if merr != nil {
+		log.Error(merr, "failed to marshal password digests")
+	}
+
 	toReconcile := true
-	if securityHandler := v2alpha5.GetBrokerConfigHandler(request.NamespacedName); securityHandler == nil {
-		log.Info("Operator doesn't have the security handler, try retrive it from secret")
-		if existingHandler := lsrcrs.RetrieveLastSuccessfulReconciledCR(request.NamespacedName, "security", r.client, getLabels(instance)); existingHandler != nil {
-			//compare resource version
-			if existingHandler.Checksum == instance.ResourceVersion {
+	previousDigests := make(map[string]string)
+	if existingHandler := lsrcrs.RetrieveLastSuccessfulReconciledCR(request.NamespacedName, "security", r.client, getLabels(instance)); existingHandler != nil {
+		if existingHandler.Data != "" {
+			common.FromJson(&existingHandler.Data, &previousDigests)
+		}
+		if v2alpha5.GetBrokerConfigHandler(request.NamespacedName) == nil {
+			log.Info("Operator doesn't have the security handler, retrieved it from secret")
+			//compare resource version and the passwords read from secrets
+			if existingHandler.Checksum == instance.ResourceVersion && existingHandler.Data == digests {
 				log.V(1).Info("The incoming security CR is identical to stored CR, no reconcile")
 				toReconcile = false
 			}
 		}
 	}
 
-	if err := v2alpha5.AddBrokerConfigHandler(request.NamespacedName, &ActiveMQArtemisSecurityConfigHandler{
-		instance,
-		request.NamespacedName,
-		r,
-	}, toReconcile); err != nil {
+	if err := v2alpha5.AddBrokerConfigHandler(request.NamespacedName, securityHandler, toReconcile); err != nil {
 		log.Error(err, "failed to config security cr", "request", request.NamespacedName)
+		r.recorder.Eventf(instance, corev1.EventTypeWarning, v2alpha5.EventReasonSecurityFailed, "Failed to apply the security to the brokers: %v", err)
 		return reconcile.Result{}, nil
 	}
+	if toReconcile {
+		r.recorder.Event(instance, corev1.EventTypeNormal, v2alpha5.EventReasonSecurityApplied, "Applied the security to the brokers it is for")
+	}
+
+	// the pod template does not change with the passwords of reloaded modules, the
+	// running brokers are told about them instead
+	if users := changedUsers(passwords, previousDigests); len(users) > 0 {
+		log.Info("Passwords changed, resetting users on the brokers", "count", len(users))
+		if err := v2alpha5.ResetUsersForSecurity(securityHandler, users, r.client); err != nil {
+			log.Error(err, "failed to reset users", "request", request.NamespacedName)
+			return reconcile.Result{}, err
+		}
+	}
 	//persist the CR
 	crstr, merr := common.ToJson(instance)
 	if merr != nil {
@@ -121,7 +162,7 @@ func (r *ReconcileActiveMQArtemisSecurity) Reconcile(request reconcile.Request)
 	}
 
 	lsrcrs.StoreLastSuccessfulReconciledCR(instance, instance.Name, instance.Namespace, "security",
-		crstr, "", instance.ResourceVersion, getLabels(instance), r.client, r.scheme)
+		crstr, digests, instance.ResourceVersion, getLabels(instance), r.client, r.scheme)
 
 	return reconcile.Result{}, nil
 }
@@ -151,6 +192,11 @@ func (r *ActiveMQArtemisSecurityConfigHandler) IsApplicableFor(brokerNamespacedN
 	return false
 }
 
+// HawtioRoles returns the roles the console admits
+func (r *ActiveMQArtemisSecurityConfigHandler) HawtioRoles() []string {
+	return r.SecurityCR.Spec.SecuritySettings.Management.HawtioRoles
+}
+
 func (r *ActiveMQArtemisSecurityConfigHandler) processCrPasswords() *brokerv1alpha1.ActiveMQArtemisSecurity {
 	result := r.SecurityCR.DeepCopy()
 
@@ -158,7 +204,12 @@ func (r *ActiveMQArtemisSecurityConfigHandler) processCrPasswords() *brokerv1alp
 		for i, pm := range result.Spec.LoginModules.PropertiesLoginModules {
 			if len(pm.Users) > 0 {
 				for j, user := range pm.Users {
-					if user.Password == nil {
+					if user.PasswordFrom != nil {
+						//replaced by the env var holding the password when the init container runs
+						placeholder := passwordPlaceholder(passwordEnvVarName(i, j))
+						result.Spec.LoginModules.PropertiesLoginModules[i].Users[j].Password = &placeholder
+						result.Spec.LoginModules.PropertiesLoginModules[i].Users[j].PasswordFrom = nil
+					} else if user.Password == nil {
 						result.Spec.LoginModules.PropertiesLoginModules[i].Users[j].Password = r.getPassword("security-properties-"+pm.Name, user.Name)
 					}
 				}
@@ -244,18 +295,19 @@ func (r *ActiveMQArtemisSecurityConfigHandler) getPassword(secretName string, ke
 
 func (r *ActiveMQArtemisSecurityConfigHandler) Config(initContainers []corev1.Container, outputDirRoot string, yacfgProfileVersion string, yacfgProfileName string) (value []string) {
 	log.Info("Reconciling security", "cr", r.SecurityCR)
-	result := r.processCrPasswords()
+	result := withoutExtraModules(r.processCrPasswords())
 	outputDir := outputDirRoot + "/security"
 	var configCmds = []string{"echo \"making dir " + outputDir + "\"", "mkdir -p " + outputDir}
 	filePath := outputDir + "/security-config.yaml"
-	cmdPersistCRAsYaml, err := r.persistCR(filePath, result)
+	cmdsPersistCRAsYaml, err := persistCR(filePath, result)
 	if err != nil {
 		log.Error(err, "Error marshalling security CR", "cr", r.SecurityCR)
 		return nil
 	}
-	log.Info("get the command", "value", cmdPersistCRAsYaml)
-	configCmds = append(configCmds, cmdPersistCRAsYaml)
+	log.Info("get the commands", "value", cmdsPersistCRAsYaml)
+	configCmds = append(configCmds, cmdsPersistCRAsYaml...)
 	configCmds = append(configCmds, "/opt/amq-broker/script/cfg/config-security.sh")
+	configCmds = append(configCmds, r.extraModulesConfig(outputDir, outputDirRoot, "$CONFIG_INSTANCE_DIR")...)
 	envVarName := "SECURITY_CFG_YAML"
 	envVar := corev1.EnvVar{
 		envVarName,
@@ -280,15 +332,53 @@ func (r *ActiveMQArtemisSecurityConfigHandler) Config(initContainers []corev1.Co
 	}
 	environments.Create(initContainers, &envVar)
 
+	passwords, err := r.resolveSecretPasswords()
+	if err != nil {
+		log.Error(err, "Error reading user passwords", "cr", r.SecurityCR)
+	}
+	for _, p := range passwords {
+		envVar = corev1.EnvVar{
+			Name: p.envVar,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: p.ref.DeepCopy(),
+			},
+		}
+		environments.Create(initContainers, &envVar)
+	}
+	if digest := restartDigest(passwords); digest != "" {
+		envVar = corev1.EnvVar{
+			Name:  passwordDigestEnvVar,
+			Value: digest,
+		}
+		environments.Create(initContainers, &envVar)
+	}
+
 	log.Info("returning config cmds", "value", configCmds)
 	return configCmds
 }
 
-func (r *ActiveMQArtemisSecurityConfigHandler) persistCR(filePath string, cr *brokerv1alpha1.ActiveMQArtemisSecurity) (value string, err error) {
+// persistCR returns the commands writing the CR as yaml to filePath. The yaml is written
+// as is, the password placeholders in it are then replaced by the passwords as quoted
+// yaml scalars.
+func persistCR(filePath string, cr *brokerv1alpha1.ActiveMQArtemisSecurity) (value []string, err error) {
 
 	data, err := yaml.Marshal(cr)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	cmds := []string{"printf '%s' " + escape.ShellQuote(string(data)) + " > " + filePath}
+
+	var envVars []string
+	for i, pm := range cr.Spec.LoginModules.PropertiesLoginModules {
+		for j, user := range pm.Users {
+			envVar := passwordEnvVarName(i, j)
+			if user.Password != nil && *user.Password == passwordPlaceholder(envVar) {
+				envVars = append(envVars, envVar)
+			}
+		}
+	}
+	if len(envVars) > 0 {
+		cmds = append(cmds, substitutePasswordsCmd(filePath, quoteYAML, envVars))
 	}
-	return "echo \"" + string(data) + "\" > " + filePath, nil
+	return cmds, nil
 }
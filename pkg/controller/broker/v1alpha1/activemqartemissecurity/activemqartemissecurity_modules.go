@@ -0,0 +1,241 @@
+package v1alpha1activemqartemissecurity
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	brokerv1alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v1alpha1"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/escape"
+)
+
+// The login config generated by config-security.sh only knows about the properties, guest
+// and keycloak login modules. The LDAP and certificate login modules are left out of the
+// CR it is given and added by the operator to the domains of the generated login.config.
+
+const (
+	ldapLoginModuleClass        = "org.apache.activemq.artemis.spi.core.security.jaas.LDAPLoginModule"
+	certificateLoginModuleClass = "org.apache.activemq.artemis.spi.core.security.jaas.TextFileCertificateLoginModule"
+
+	defaultBrokerDomain  = "activemq"
+	defaultConsoleDomain = "console"
+)
+
+// withoutExtraModules returns a copy of the CR without the login modules config-security.sh
+// does not know about, nor the references of the security domains to them
+func withoutExtraModules(cr *brokerv1alpha1.ActiveMQArtemisSecurity) *brokerv1alpha1.ActiveMQArtemisSecurity {
+	result := cr.DeepCopy()
+	extra := extraModuleNames(cr)
+	if len(extra) == 0 {
+		return result
+	}
+	result.Spec.LoginModules.LDAPLoginModules = nil
+	result.Spec.LoginModules.TextFileCertificateLoginModules = nil
+	for _, domain := range []*brokerv1alpha1.BrokerDomainType{&result.Spec.SecurityDomains.BrokerDomain, &result.Spec.SecurityDomains.ConsoleDomain} {
+		var modules []brokerv1alpha1.LoginModuleReferenceType
+		for _, module := range domain.LoginModules {
+			if module.Name == nil || !extra[*module.Name] {
+				modules = append(modules, module)
+			}
+		}
+		domain.LoginModules = modules
+	}
+	return result
+}
+
+func extraModuleNames(cr *brokerv1alpha1.ActiveMQArtemisSecurity) map[string]bool {
+	names := make(map[string]bool)
+	for _, module := range cr.Spec.LoginModules.LDAPLoginModules {
+		names[module.Name] = true
+	}
+	for _, module := range cr.Spec.LoginModules.TextFileCertificateLoginModules {
+		names[module.Name] = true
+	}
+	return names
+}
+
+// extraModulesConfig returns the commands adding the LDAP and certificate login modules to
+// the login.config files found under the given directories, after config-security.sh ran.
+// The modules are added to the end of the domains that refer to them.
+func (r *ActiveMQArtemisSecurityConfigHandler) extraModulesConfig(outputDir string, configDirs ...string) []string {
+	cr := r.SecurityCR
+	if len(extraModuleNames(cr)) == 0 {
+		return nil
+	}
+
+	var envVars []string
+	for i, module := range cr.Spec.LoginModules.LDAPLoginModules {
+		if module.ConnectionPasswordFrom != nil && module.ConnectionPasswordFrom.SecretKeyRef != nil {
+			envVars = append(envVars, ldapPasswordEnvVarName(i))
+		}
+	}
+
+	var cmds []string
+	var merges []string
+	domains := []struct {
+		domain      *brokerv1alpha1.BrokerDomainType
+		defaultName string
+	}{
+		{&cr.Spec.SecurityDomains.BrokerDomain, defaultBrokerDomain},
+		{&cr.Spec.SecurityDomains.ConsoleDomain, defaultConsoleDomain},
+	}
+	for _, d := range domains {
+		entries := r.extraModuleEntries(d.domain)
+		if len(entries) == 0 {
+			continue
+		}
+		name := d.defaultName
+		if d.domain.Name != nil && *d.domain.Name != "" {
+			name = *d.domain.Name
+		}
+		fragment := outputDir + "/" + escape.ShellQuote("login-"+name+".config")
+		cmds = append(cmds, "printf '%s\\n' "+strings.Join(entries, " ")+" > "+fragment)
+		if len(envVars) > 0 {
+			cmds = append(cmds, substitutePasswordsCmd(fragment, quoteJAAS, envVars))
+		}
+		merges = append(merges, "awk -v d="+escape.ShellQuote(name)+" -v f="+fragment+" "+escape.ShellQuote(mergeDomainProgram)+" \"$f\" > \"$f.tmp\" && mv \"$f.tmp\" \"$f\"")
+	}
+	if len(merges) == 0 {
+		return nil
+	}
+
+	var certFiles []string
+	for _, module := range cr.Spec.LoginModules.TextFileCertificateLoginModules {
+		users, roles := certificateFiles(&module)
+		usersFile := outputDir + "/" + escape.ShellQuote(module.Name+"-cert-users.properties")
+		rolesFile := outputDir + "/" + escape.ShellQuote(module.Name+"-cert-roles.properties")
+		cmds = append(cmds, writeLines(users, usersFile), writeLines(roles, rolesFile))
+		certFiles = append(certFiles, usersFile, rolesFile)
+	}
+	if len(certFiles) > 0 {
+		merges = append(merges, "cp "+strings.Join(certFiles, " ")+" \"$(dirname \"$f\")\"")
+	}
+
+	cmds = append(cmds, "for f in $(find "+strings.Join(configDirs, " ")+" -name login.config 2>/dev/null); do "+strings.Join(merges, " && ")+"; done")
+	return cmds
+}
+
+// mergeDomainProgram is an awk program inserting the lines of file f before the end of the
+// block of domain d, the block is added when there is none. The name of the domain is
+// compared literally, it is not a pattern.
+const mergeDomainProgram = `{ trimmed = $0; sub(/^[ \t]+/, "", trimmed) }
+substr(trimmed, 1, length(d)) == d && substr(trimmed, length(d) + 1) ~ /^[ \t]*[{]/ { inDomain = 1 }
+inDomain && /^[ \t]*[}][ \t]*;/ { while ((getline line < f) > 0) print line; inDomain = 0; merged = 1 }
+{ print }
+END { if (!merged) { print d " {"; while ((getline line < f) > 0) print line; print "};" } }`
+
+// extraModuleEntries returns the shell quoted lines of the login config entries of the LDAP
+// and certificate login modules the domain refers to, in the order it refers to them
+func (r *ActiveMQArtemisSecurityConfigHandler) extraModuleEntries(domain *brokerv1alpha1.BrokerDomainType) []string {
+	var lines []string
+	for _, ref := range domain.LoginModules {
+		if ref.Name == nil {
+			continue
+		}
+		for i, module := range r.SecurityCR.Spec.LoginModules.LDAPLoginModules {
+			if module.Name == *ref.Name {
+				lines = append(lines, moduleHeader(ldapLoginModuleClass, &ref)...)
+				lines = append(lines, ldapOptions(&module, ldapPasswordEnvVarName(i))...)
+				lines = append(lines, escape.ShellQuote("    ;"))
+			}
+		}
+		for _, module := range r.SecurityCR.Spec.LoginModules.TextFileCertificateLoginModules {
+			if module.Name == *ref.Name {
+				lines = append(lines, moduleHeader(certificateLoginModuleClass, &ref)...)
+				lines = append(lines,
+					option("org.apache.activemq.jaas.textfiledn.user", module.Name+"-cert-users.properties"),
+					option("org.apache.activemq.jaas.textfiledn.role", module.Name+"-cert-roles.properties"),
+					escape.ShellQuote("    ;"))
+			}
+		}
+	}
+	return lines
+}
+
+func moduleHeader(class string, ref *brokerv1alpha1.LoginModuleReferenceType) []string {
+	flag := "required"
+	if ref.Flag != nil && *ref.Flag != "" {
+		flag = *ref.Flag
+	}
+	lines := []string{escape.ShellQuote("    " + class + " " + flag)}
+	if ref.Debug != nil {
+		lines = append(lines, escape.ShellQuote("        debug="+strconv.FormatBool(*ref.Debug)))
+	}
+	if ref.Reload != nil {
+		lines = append(lines, escape.ShellQuote("        reload="+strconv.FormatBool(*ref.Reload)))
+	}
+	return lines
+}
+
+func ldapOptions(module *brokerv1alpha1.LDAPLoginModuleType, passwordEnvVar string) []string {
+	lines := []string{
+		option("initialContextFactory", "com.sun.jndi.ldap.LdapCtxFactory"),
+		option("connectionURL", module.ConnectionURL),
+	}
+	optionalString := func(name string, value *string) {
+		if value != nil {
+			lines = append(lines, option(name, *value))
+		}
+	}
+	optionalBool := func(name string, value *bool) {
+		if value != nil {
+			lines = append(lines, option(name, strconv.FormatBool(*value)))
+		}
+	}
+	optionalString("connectionUsername", module.ConnectionUsername)
+	if module.ConnectionPasswordFrom != nil && module.ConnectionPasswordFrom.SecretKeyRef != nil {
+		// replaced by the env var holding the password when the init container runs
+		lines = append(lines, escape.ShellQuote("        connectionPassword="+passwordPlaceholder(passwordEnvVar)))
+	}
+	optionalString("connectionProtocol", module.ConnectionProtocol)
+	optionalString("authentication", module.Authentication)
+	lines = append(lines, option("userBase", module.UserBase), option("userSearchMatching", module.UserSearchMatching))
+	optionalBool("userSearchSubtree", module.UserSearchSubtree)
+	optionalString("roleBase", module.RoleBase)
+	optionalString("roleName", module.RoleName)
+	optionalString("roleSearchMatching", module.RoleSearchMatching)
+	optionalBool("roleSearchSubtree", module.RoleSearchSubtree)
+	optionalString("userRoleName", module.UserRoleName)
+	optionalBool("expandRoles", module.ExpandRoles)
+	optionalString("expandRolesMatching", module.ExpandRolesMatching)
+	optionalString("referral", module.Referral)
+	optionalBool("ignorePartialResultException", module.IgnorePartialResultException)
+	return lines
+}
+
+// certificateFiles returns the lines of the users file, user names to certificate subjects,
+// and of the roles file, roles to user names, of a certificate login module
+func certificateFiles(module *brokerv1alpha1.TextFileCertificateLoginModuleType) ([]string, []string) {
+	var users []string
+	roleUsers := make(map[string][]string)
+	for _, user := range module.Users {
+		users = append(users, escape.PropertyKey(user.Name)+"="+escape.PropertyValue(user.DN))
+		for _, role := range user.Roles {
+			roleUsers[role] = append(roleUsers[role], user.Name)
+		}
+	}
+	var roles []string
+	for role, names := range roleUsers {
+		roles = append(roles, escape.PropertyKey(role)+"="+escape.PropertyValue(strings.Join(names, ",")))
+	}
+	sort.Strings(roles)
+	return users, roles
+}
+
+// option returns the shell quoted line of a login module option
+func option(name string, value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return escape.ShellQuote("        " + name + `="` + value + `"`)
+}
+
+func writeLines(lines []string, file string) string {
+	if len(lines) == 0 {
+		return ": > " + file
+	}
+	var quoted []string
+	for _, line := range lines {
+		quoted = append(quoted, escape.ShellQuote(line))
+	}
+	return "printf '%s\\n' " + strings.Join(quoted, " ") + " > " + file
+}
@@ -0,0 +1,276 @@
+package v1alpha1activemqartemissecurity
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	brokerv1alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const generatedLoginConfig = `activemq {
+    org.apache.activemq.artemis.spi.core.security.jaas.PropertiesLoginModule sufficient
+        org.apache.activemq.jaas.properties.user="artemis-users.properties"
+        org.apache.activemq.jaas.properties.role="artemis-roles.properties"
+    ;
+};
+`
+
+// loadProperties reads the lines of a properties file the way java.util.Properties.load does
+func loadProperties(data string) map[string]string {
+	unescape := func(s string) string {
+		var out strings.Builder
+		for i := 0; i < len(s); i++ {
+			if s[i] != '\\' || i == len(s)-1 {
+				out.WriteByte(s[i])
+				continue
+			}
+			i++
+			switch s[i] {
+			case 't':
+				out.WriteByte('\t')
+			case 'n':
+				out.WriteByte('\n')
+			case 'r':
+				out.WriteByte('\r')
+			case 'f':
+				out.WriteByte('\f')
+			case 'u':
+				code, err := strconv.ParseUint(s[i+1:i+5], 16, 32)
+				Expect(err).To(BeNil())
+				out.WriteRune(rune(code))
+				i += 4
+			default:
+				out.WriteByte(s[i])
+			}
+		}
+		return out.String()
+	}
+	// the index of the first character past the key, a separator or whitespace not escaped
+	keyEnd := func(line string) int {
+		for i := 0; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+			} else if strings.IndexByte("=: \t\f", line[i]) >= 0 {
+				return i
+			}
+		}
+		return len(line)
+	}
+
+	properties := make(map[string]string)
+	logical := ""
+	for _, physical := range strings.Split(data, "\n") {
+		physical = strings.TrimLeft(physical, " \t\f")
+		trailing := len(physical) - len(strings.TrimRight(physical, "\\"))
+		if trailing%2 == 1 {
+			logical += physical[:len(physical)-1]
+			continue
+		}
+		line := logical + physical
+		logical = ""
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		end := keyEnd(line)
+		value := strings.TrimLeft(line[end:], " \t\f")
+		if value != "" && (value[0] == '=' || value[0] == ':') {
+			value = strings.TrimLeft(value[1:], " \t\f")
+		}
+		properties[unescape(line[:end])] = unescape(value)
+	}
+	return properties
+}
+
+var _ = Describe("Adding the LDAP and certificate login modules", func() {
+
+	// the fragments are written to outputDir, the login config is under configDir
+	var dir, outputDir, configDir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "login")
+		Expect(err).To(BeNil())
+		outputDir = filepath.Join(dir, "security")
+		configDir = filepath.Join(dir, "config")
+		Expect(os.Mkdir(outputDir, 0755)).To(Succeed())
+		Expect(os.Mkdir(configDir, 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(configDir, "login.config"), []byte(generatedLoginConfig), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("quotes the option values and the bind password", func() {
+		ldapName := "ldap"
+		certName := "certs"
+		sufficient := "sufficient"
+		username := `cn=admin,dc=example,dc=com`
+		roleName := `cn's "name"`
+		handler := &ActiveMQArtemisSecurityConfigHandler{SecurityCR: &brokerv1alpha1.ActiveMQArtemisSecurity{
+			Spec: brokerv1alpha1.ActiveMQArtemisSecuritySpec{
+				LoginModules: brokerv1alpha1.LoginModulesType{
+					LDAPLoginModules: []brokerv1alpha1.LDAPLoginModuleType{{
+						Name:               ldapName,
+						ConnectionURL:      `ldap://ldap.example.com:389/$HOME`,
+						ConnectionUsername: &username,
+						ConnectionPasswordFrom: &brokerv1alpha1.PasswordSourceType{
+							SecretKeyRef: &corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "ldap"},
+								Key:                  "password",
+							},
+						},
+						UserBase:           `ou=Users,dc=example,dc=com`,
+						UserSearchMatching: `(uid={0})`,
+						RoleName:           &roleName,
+					}},
+					TextFileCertificateLoginModules: []brokerv1alpha1.TextFileCertificateLoginModuleType{{
+						Name: certName,
+						Users: []brokerv1alpha1.CertificateUserType{
+							{Name: "app=1:a b", DN: `CN=app,O=Example\, Inc.`, Roles: []string{"send#all"}},
+						},
+					}},
+				},
+				SecurityDomains: brokerv1alpha1.SecurityDomainsType{
+					BrokerDomain: brokerv1alpha1.BrokerDomainType{
+						LoginModules: []brokerv1alpha1.LoginModuleReferenceType{
+							{Name: &ldapName, Flag: &sufficient},
+							{Name: &certName},
+						},
+					},
+				},
+			},
+		}}
+
+		cmds := handler.extraModulesConfig(outputDir, configDir)
+		runCmds(cmds, map[string]string{ldapPasswordEnvVarName(0): `it's "$ecret" \ ` + "`id`"})
+
+		loginConfig, err := ioutil.ReadFile(filepath.Join(configDir, "login.config"))
+		Expect(err).To(BeNil())
+		Expect(string(loginConfig)).To(Equal(`activemq {
+    org.apache.activemq.artemis.spi.core.security.jaas.PropertiesLoginModule sufficient
+        org.apache.activemq.jaas.properties.user="artemis-users.properties"
+        org.apache.activemq.jaas.properties.role="artemis-roles.properties"
+    ;
+    org.apache.activemq.artemis.spi.core.security.jaas.LDAPLoginModule sufficient
+        initialContextFactory="com.sun.jndi.ldap.LdapCtxFactory"
+        connectionURL="ldap://ldap.example.com:389/$HOME"
+        connectionUsername="cn=admin,dc=example,dc=com"
+        connectionPassword="it's \"$ecret\" \\ ` + "`id`" + `"
+        userBase="ou=Users,dc=example,dc=com"
+        userSearchMatching="(uid={0})"
+        roleName="cn's \"name\""
+    ;
+    org.apache.activemq.artemis.spi.core.security.jaas.TextFileCertificateLoginModule required
+        org.apache.activemq.jaas.textfiledn.user="certs-cert-users.properties"
+        org.apache.activemq.jaas.textfiledn.role="certs-cert-roles.properties"
+    ;
+};
+`))
+
+		users, err := ioutil.ReadFile(filepath.Join(configDir, "certs-cert-users.properties"))
+		Expect(err).To(BeNil())
+		Expect(loadProperties(string(users))).To(Equal(map[string]string{"app=1:a b": `CN=app,O=Example\, Inc.`}))
+		roles, err := ioutil.ReadFile(filepath.Join(configDir, "certs-cert-roles.properties"))
+		Expect(err).To(BeNil())
+		Expect(loadProperties(string(roles))).To(Equal(map[string]string{"send#all": "app=1:a b"}))
+	})
+
+	It("keeps the backslashes and the leading whitespace of the subjects and user names", func() {
+		certName := "certs"
+		handler := &ActiveMQArtemisSecurityConfigHandler{SecurityCR: &brokerv1alpha1.ActiveMQArtemisSecurity{
+			Spec: brokerv1alpha1.ActiveMQArtemisSecuritySpec{
+				LoginModules: brokerv1alpha1.LoginModulesType{
+					TextFileCertificateLoginModules: []brokerv1alpha1.TextFileCertificateLoginModuleType{{
+						Name: certName,
+						Users: []brokerv1alpha1.CertificateUserType{
+							{Name: ` a\b`, DN: ` CN=a\+b,O=Example\\ Inc.\`, Roles: []string{"admin"}},
+							{Name: "c", DN: "CN=c", Roles: []string{"admin"}},
+						},
+					}},
+				},
+				SecurityDomains: brokerv1alpha1.SecurityDomainsType{
+					BrokerDomain: brokerv1alpha1.BrokerDomainType{
+						LoginModules: []brokerv1alpha1.LoginModuleReferenceType{{Name: &certName}},
+					},
+				},
+			},
+		}}
+
+		runCmds(handler.extraModulesConfig(outputDir, configDir), nil)
+
+		users, err := ioutil.ReadFile(filepath.Join(configDir, "certs-cert-users.properties"))
+		Expect(err).To(BeNil())
+		Expect(loadProperties(string(users))).To(Equal(map[string]string{` a\b`: ` CN=a\+b,O=Example\\ Inc.\`, "c": "CN=c"}))
+		roles, err := ioutil.ReadFile(filepath.Join(configDir, "certs-cert-roles.properties"))
+		Expect(err).To(BeNil())
+		Expect(loadProperties(string(roles))).To(Equal(map[string]string{"admin": ` a\b,c`}))
+	})
+
+	It("keeps the names of the domains and modules out of the shell and the patterns", func() {
+		certName := "certs;touch pwned"
+		domainName := "a.tivemq"
+		handler := &ActiveMQArtemisSecurityConfigHandler{SecurityCR: &brokerv1alpha1.ActiveMQArtemisSecurity{
+			Spec: brokerv1alpha1.ActiveMQArtemisSecuritySpec{
+				LoginModules: brokerv1alpha1.LoginModulesType{
+					TextFileCertificateLoginModules: []brokerv1alpha1.TextFileCertificateLoginModuleType{{Name: certName}},
+				},
+				SecurityDomains: brokerv1alpha1.SecurityDomainsType{
+					BrokerDomain: brokerv1alpha1.BrokerDomainType{
+						Name:         &domainName,
+						LoginModules: []brokerv1alpha1.LoginModuleReferenceType{{Name: &certName}},
+					},
+				},
+			},
+		}}
+
+		cmds := handler.extraModulesConfig(outputDir, configDir)
+		cmd := exec.Command("bash", "-e", "-c", strings.Join(cmds, "\n"))
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		Expect(err).To(BeNil(), string(output))
+
+		Expect(filepath.Join(dir, "pwned")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(configDir, "certs;touch pwned-cert-users.properties")).To(BeAnExistingFile())
+		loginConfig, err := ioutil.ReadFile(filepath.Join(configDir, "login.config"))
+		Expect(err).To(BeNil())
+		Expect(string(loginConfig)).To(HavePrefix(generatedLoginConfig + "a.tivemq {\n"))
+	})
+
+	It("adds a domain the login config doesn't have", func() {
+		certName := "certs"
+		consoleName := "web"
+		handler := &ActiveMQArtemisSecurityConfigHandler{SecurityCR: &brokerv1alpha1.ActiveMQArtemisSecurity{
+			Spec: brokerv1alpha1.ActiveMQArtemisSecuritySpec{
+				LoginModules: brokerv1alpha1.LoginModulesType{
+					TextFileCertificateLoginModules: []brokerv1alpha1.TextFileCertificateLoginModuleType{{Name: certName}},
+				},
+				SecurityDomains: brokerv1alpha1.SecurityDomainsType{
+					ConsoleDomain: brokerv1alpha1.BrokerDomainType{
+						Name:         &consoleName,
+						LoginModules: []brokerv1alpha1.LoginModuleReferenceType{{Name: &certName}},
+					},
+				},
+			},
+		}}
+
+		runCmds(handler.extraModulesConfig(outputDir, configDir), nil)
+
+		loginConfig, err := ioutil.ReadFile(filepath.Join(configDir, "login.config"))
+		Expect(err).To(BeNil())
+		Expect(string(loginConfig)).To(Equal(generatedLoginConfig + `web {
+    org.apache.activemq.artemis.spi.core.security.jaas.TextFileCertificateLoginModule required
+        org.apache.activemq.jaas.textfiledn.user="certs-cert-users.properties"
+        org.apache.activemq.jaas.textfiledn.role="certs-cert-roles.properties"
+    ;
+};
+`))
+	})
+})
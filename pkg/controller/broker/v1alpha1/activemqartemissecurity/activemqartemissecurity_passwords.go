@@ -0,0 +1,263 @@
+package v1alpha1activemqartemissecurity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	brokerv1alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v1alpha1"
+	v2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/controller/broker/v2alpha5/activemqartemis"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/escape"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// The passwords read from secrets reach the init container as env vars sourced from the
+// secrets, so that a new password leaves the pod template as it is. The digest of the
+// passwords of the modules that are not reloaded is added to the template instead, so
+// that the brokers restart when one of them changes.
+const (
+	passwordEnvVarPrefix     = "SECURITY_USER_PASSWORD_"
+	ldapPasswordEnvVarPrefix = "SECURITY_LDAP_PASSWORD_"
+	passwordDigestEnvVar     = "SECURITY_USER_PASSWORDS_DIGEST"
+)
+
+// secretPassword is a password read from a secret, of a user of a properties login module
+// or of the bind user of an LDAP login module
+type secretPassword struct {
+	// user is nil for the bind user of an LDAP login module
+	user     *brokerv1alpha1.UserType
+	ref      *corev1.SecretKeySelector
+	password string
+	envVar   string
+	// reloaded tells whether the broker domain reloads the login module of the user
+	reloaded bool
+}
+
+func passwordEnvVarName(module int, user int) string {
+	return passwordEnvVarPrefix + strconv.Itoa(module) + "_" + strconv.Itoa(user)
+}
+
+func ldapPasswordEnvVarName(module int) string {
+	return ldapPasswordEnvVarPrefix + strconv.Itoa(module)
+}
+
+// passwordPlaceholder stands for the password held by envVar in the generated config
+func passwordPlaceholder(envVar string) string {
+	return "__" + envVar + "__"
+}
+
+// The quoting of the substituted passwords, as a single quoted yaml scalar or as a double
+// quoted string of a login config
+const (
+	quoteYAML = "yaml"
+	quoteJAAS = "jaas"
+)
+
+// substitutePasswordsCmd returns the command replacing the placeholders of the passwords
+// held by envVars in file, each by the password quoted as told by quote
+func substitutePasswordsCmd(file string, quote string, envVars []string) string {
+	return "awk -v quote=" + quote + " -v names=" + escape.ShellQuote(strings.Join(envVars, " ")) + " " + escape.ShellQuote(substitutePasswordsProgram) +
+		" " + file + " > " + file + ".tmp && mv " + file + ".tmp " + file
+}
+
+// substitutePasswordsProgram is an awk program replacing the placeholders of the env vars
+// listed in names by their values. The values are read from the environment of awk, so they
+// never go through the shell, and are quoted there: yaml doubles the single quotes, the
+// login config escapes double quotes, backslashes and new lines with a backslash.
+const substitutePasswordsProgram = `function quoted(v,   r, c, i) {
+	r = ""
+	for (i = 1; i <= length(v); i++) {
+		c = substr(v, i, 1)
+		if (quote == "yaml" && c == q) {
+			c = q q
+		} else if (quote == "jaas" && (c == "\\" || c == "\"")) {
+			c = "\\" c
+		} else if (quote == "jaas" && c == "\n") {
+			c = "\\n"
+		}
+		r = r c
+	}
+	return quote == "yaml" ? q r q : "\"" r "\""
+}
+BEGIN { q = sprintf("%c", 39); n = split(names, name, " ") }
+{
+	line = $0
+	for (k = 1; k <= n; k++) {
+		t = "__" name[k] "__"
+		v = quoted(ENVIRON[name[k]])
+		out = ""
+		while ((i = index(line, t)) > 0) {
+			out = out substr(line, 1, i - 1) v
+			line = substr(line, i + length(t))
+		}
+		line = out line
+	}
+	print line
+}`
+
+func passwordDigest(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+// reloadedModules returns the names of the login modules the broker domain reloads. Users
+// can only be reset through the management api on the broker domain.
+func (r *ActiveMQArtemisSecurityConfigHandler) reloadedModules() map[string]bool {
+	reloaded := make(map[string]bool)
+	for _, module := range r.SecurityCR.Spec.SecurityDomains.BrokerDomain.LoginModules {
+		if module.Name != nil && module.Reload != nil && *module.Reload {
+			reloaded[*module.Name] = true
+		}
+	}
+	return reloaded
+}
+
+// resolveSecretPasswords reads the password of every user that has one in a secret, keyed
+// by login module and user name, and the bind password of every LDAP login module. The
+// LDAP login config is only read as the broker starts, so a new bind password restarts it.
+func (r *ActiveMQArtemisSecurityConfigHandler) resolveSecretPasswords() (map[string]secretPassword, error) {
+	result := make(map[string]secretPassword)
+	reloaded := r.reloadedModules()
+	for i, pm := range r.SecurityCR.Spec.LoginModules.PropertiesLoginModules {
+		for j := range pm.Users {
+			user := &r.SecurityCR.Spec.LoginModules.PropertiesLoginModules[i].Users[j]
+			if user.PasswordFrom == nil || user.PasswordFrom.SecretKeyRef == nil {
+				continue
+			}
+			password, err := r.getSecretPassword(user.PasswordFrom.SecretKeyRef)
+			if err != nil {
+				return nil, fmt.Errorf("password of user %s of login module %s: %v", user.Name, pm.Name, err)
+			}
+			result[pm.Name+"/"+user.Name] = secretPassword{
+				user:     user,
+				ref:      user.PasswordFrom.SecretKeyRef,
+				password: password,
+				envVar:   passwordEnvVarName(i, j),
+				reloaded: reloaded[pm.Name],
+			}
+		}
+	}
+	for i, lm := range r.SecurityCR.Spec.LoginModules.LDAPLoginModules {
+		if lm.ConnectionPasswordFrom == nil || lm.ConnectionPasswordFrom.SecretKeyRef == nil {
+			continue
+		}
+		ref := r.SecurityCR.Spec.LoginModules.LDAPLoginModules[i].ConnectionPasswordFrom.SecretKeyRef
+		password, err := r.getSecretPassword(ref)
+		if err != nil {
+			return nil, fmt.Errorf("bind password of LDAP login module %s: %v", lm.Name, err)
+		}
+		result[lm.Name] = secretPassword{
+			ref:      ref,
+			password: password,
+			envVar:   ldapPasswordEnvVarName(i),
+		}
+	}
+	return result, nil
+}
+
+func (r *ActiveMQArtemisSecurityConfigHandler) getSecretPassword(ref *corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	if err := r.owner.client.Get(context.TODO(), types.NamespacedName{Namespace: r.NamespacedName.Namespace, Name: ref.Name}, secret); err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %s", ref.Name, ref.Key)
+	}
+	return string(value), nil
+}
+
+// passwordDigests returns the digest of each password read from a secret, these are kept
+// with the last reconciled CR to find out which passwords changed since
+func passwordDigests(passwords map[string]secretPassword) map[string]string {
+	digests := make(map[string]string)
+	for key, p := range passwords {
+		digests[key] = passwordDigest(p.password)
+	}
+	return digests
+}
+
+// restartDigest returns a digest of the passwords of the modules that are not reloaded, ""
+// when there are none
+func restartDigest(passwords map[string]secretPassword) string {
+	var keys []string
+	for key, p := range passwords {
+		if !p.reloaded {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key + "=" + passwordDigest(passwords[key].password) + "\n"))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// changedUsers returns the users of reloaded modules whose password is not the one it was
+// when the digests were taken. Users that had no password from a secret then are left
+// out, the brokers get them with the new config.
+func changedUsers(passwords map[string]secretPassword, previousDigests map[string]string) []v2alpha5.BrokerUser {
+	var users []v2alpha5.BrokerUser
+	for key, p := range passwords {
+		previous, ok := previousDigests[key]
+		if p.user == nil || !p.reloaded || !ok || previous == passwordDigest(p.password) {
+			continue
+		}
+		users = append(users, v2alpha5.BrokerUser{Name: p.user.Name, Password: p.password, Roles: p.user.Roles})
+	}
+	return users
+}
+
+// referencesSecret tells whether the security CR reads a password from the secret
+func referencesSecret(cr *brokerv1alpha1.ActiveMQArtemisSecurity, secretName string) bool {
+	isRef := func(source *brokerv1alpha1.PasswordSourceType) bool {
+		return source != nil && source.SecretKeyRef != nil && source.SecretKeyRef.Name == secretName
+	}
+	for _, pm := range cr.Spec.LoginModules.PropertiesLoginModules {
+		for _, user := range pm.Users {
+			if isRef(user.PasswordFrom) {
+				return true
+			}
+		}
+	}
+	for _, lm := range cr.Spec.LoginModules.LDAPLoginModules {
+		if isRef(lm.ConnectionPasswordFrom) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretToSecurityRequests maps a secret to the security CRs in its namespace that read
+// passwords from it
+func secretToSecurityRequests(c client.Client) handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		list := &brokerv1alpha1.ActiveMQArtemisSecurityList{}
+		if err := c.List(context.TODO(), &client.ListOptions{Namespace: obj.Meta.GetNamespace()}, list); err != nil {
+			log.Error(err, "Failed to list security CRs for secret", "secret", obj.Meta.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range list.Items {
+			if referencesSecret(&list.Items[i], obj.Meta.GetName()) {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: list.Items[i].Namespace,
+					Name:      list.Items[i].Name,
+				}})
+			}
+		}
+		return requests
+	}
+}
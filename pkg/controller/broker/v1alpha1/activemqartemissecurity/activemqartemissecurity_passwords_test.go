@@ -0,0 +1,120 @@
+package v1alpha1activemqartemissecurity
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	brokerv1alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// the passwords the generated config is checked with, each breaks a shell or yaml quoting
+var specialPasswords = []string{
+	`it's`,
+	`say "hi"`,
+	`$HOME and ${PATH}`,
+	"`id`",
+	`key: value # comment`,
+	`back\slash`,
+	`- [list] {map} & *alias !tag | > % @`,
+}
+
+// runCmds runs the init container commands in a shell with the given environment
+func runCmds(cmds []string, env map[string]string) {
+	cmd := exec.Command("bash", "-e", "-c", strings.Join(cmds, "\n"))
+	cmd.Env = os.Environ()
+	for name, value := range env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	output, err := cmd.CombinedOutput()
+	Expect(err).To(BeNil(), string(output))
+}
+
+var _ = Describe("Persisting the security CR", func() {
+
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "security")
+		Expect(err).To(BeNil())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("writes passwords with special characters as they are", func() {
+		module := brokerv1alpha1.PropertiesLoginModuleType{Name: "prop-module"}
+		env := map[string]string{}
+		for i, password := range specialPasswords {
+			literal := password
+			module.Users = append(module.Users,
+				brokerv1alpha1.UserType{Name: "literal" + strconv.Itoa(i), Password: &literal},
+				brokerv1alpha1.UserType{Name: "secret" + strconv.Itoa(i), PasswordFrom: &brokerv1alpha1.PasswordSourceType{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "users"},
+						Key:                  "secret" + strconv.Itoa(i),
+					},
+				}})
+			env[passwordEnvVarName(0, 2*i+1)] = password
+		}
+		handler := &ActiveMQArtemisSecurityConfigHandler{SecurityCR: &brokerv1alpha1.ActiveMQArtemisSecurity{
+			Spec: brokerv1alpha1.ActiveMQArtemisSecuritySpec{
+				LoginModules: brokerv1alpha1.LoginModulesType{
+					PropertiesLoginModules: []brokerv1alpha1.PropertiesLoginModuleType{module},
+				},
+			},
+		}}
+
+		file := filepath.Join(dir, "security-config.yaml")
+		cmds, err := persistCR(file, handler.processCrPasswords())
+		Expect(err).To(BeNil())
+		runCmds(cmds, env)
+
+		data, err := ioutil.ReadFile(file)
+		Expect(err).To(BeNil())
+		written := &brokerv1alpha1.ActiveMQArtemisSecurity{}
+		Expect(yaml.Unmarshal(data, written)).To(Succeed(), string(data))
+
+		users := written.Spec.LoginModules.PropertiesLoginModules[0].Users
+		Expect(users).To(HaveLen(2 * len(specialPasswords)))
+		for i, password := range specialPasswords {
+			Expect(*users[2*i].Password).To(Equal(password))
+			Expect(*users[2*i+1].Password).To(Equal(password))
+		}
+	})
+
+	It("leaves a config without secret passwords alone", func() {
+		password := "$NOT_EXPANDED"
+		cr := &brokerv1alpha1.ActiveMQArtemisSecurity{
+			Spec: brokerv1alpha1.ActiveMQArtemisSecuritySpec{
+				LoginModules: brokerv1alpha1.LoginModulesType{
+					PropertiesLoginModules: []brokerv1alpha1.PropertiesLoginModuleType{{
+						Name:  "prop-module",
+						Users: []brokerv1alpha1.UserType{{Name: "admin", Password: &password}},
+					}},
+				},
+			},
+		}
+
+		file := filepath.Join(dir, "security-config.yaml")
+		cmds, err := persistCR(file, cr)
+		Expect(err).To(BeNil())
+		Expect(cmds).To(HaveLen(1))
+		runCmds(cmds, map[string]string{"NOT_EXPANDED": "expanded"})
+
+		data, err := ioutil.ReadFile(file)
+		Expect(err).To(BeNil())
+		expected, err := yaml.Marshal(cr)
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(Equal(string(expected)))
+	})
+})
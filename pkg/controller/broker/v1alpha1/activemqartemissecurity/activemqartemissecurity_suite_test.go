@@ -0,0 +1,13 @@
+package v1alpha1activemqartemissecurity
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestV1alpha1ActiveMQArtemisSecurity(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "V1alpha1 ActiveMQArtemisSecurity Suite")
+}
@@ -24,6 +24,7 @@ import (
 	kubeinformers "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 )
 
 var log = logf.Log.WithName("controller_v2alpha1activemqartemisscaledown")
@@ -54,7 +55,7 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileActiveMQArtemisScaledown{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	return &ReconcileActiveMQArtemisScaledown{client: mgr.GetClient(), scheme: mgr.GetScheme(), recorder: mgr.GetRecorder("v2alpha1activemqartemisscaledown-controller")}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -90,8 +91,9 @@ var _ reconcile.Reconciler = &ReconcileActiveMQArtemisScaledown{}
 type ReconcileActiveMQArtemisScaledown struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a ActiveMQArtemisScaledown object and makes changes based on the state read
@@ -153,6 +155,9 @@ func (r *ReconcileActiveMQArtemisScaledown) Reconcile(request reconcile.Request)
 
 		reqLogger.Info("==== Running drain controller async so multiple controllers can run...")
 		go runDrainController(drainControllerInstance)
+		if r.recorder != nil {
+			r.recorder.Eventf(instance, corev1.EventTypeNormal, "DrainControllerStarted", "Started the drain controller of namespace %s", namespace)
+		}
 	}
 
 	reqLogger.Info("==== OK, return result")
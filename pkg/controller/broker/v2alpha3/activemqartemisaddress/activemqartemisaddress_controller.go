@@ -4,7 +4,6 @@ import (
 	"context"
 	"strconv"
 
-	mgmt "github.com/artemiscloud/activemq-artemis-management"
 	brokerv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
 	v2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/controller/broker/v2alpha5/activemqartemis"
 	nsoptions "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/namespaces"
@@ -12,6 +11,7 @@ import (
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
 	ss "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/statefulsets"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/brokerclient"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/channels"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/lsrcrs"
@@ -24,10 +24,13 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -35,6 +38,12 @@ import (
 
 var log = logf.Log.WithName("controller_v2alpha3activemqartemisaddress")
 
+const (
+	EventReasonAddressCreated        = "AddressCreated"
+	EventReasonAddressCreationFailed = "AddressCreationFailed"
+	EventReasonJolokiaUnreachable    = "JolokiaUnreachable"
+)
+
 type AddressDeployment struct {
 	AddressResource brokerv2alpha3.ActiveMQArtemisAddress
 	//a 0-len array means all statefulsets
@@ -56,11 +65,12 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	go setupAddressObserver(mgr, channels.AddressListeningCh)
-	return &ReconcileActiveMQArtemisAddress{client: mgr.GetClient(), scheme: mgr.GetScheme()}
+	recorder := mgr.GetRecorder("v2alpha3activemqartemisaddress-controller")
+	go setupAddressObserver(mgr, recorder, channels.AddressListeningCh)
+	return &ReconcileActiveMQArtemisAddress{client: mgr.GetClient(), scheme: mgr.GetScheme(), recorder: recorder}
 }
 
-func setupAddressObserver(mgr manager.Manager, c chan types.NamespacedName) {
+func setupAddressObserver(mgr manager.Manager, recorder record.EventRecorder, c chan types.NamespacedName) {
 	log.Info("Setting up address observer")
 	cfg, err := clientcmd.BuildConfigFromFlags("", "")
 	if err != nil {
@@ -72,7 +82,7 @@ func setupAddressObserver(mgr manager.Manager, c chan types.NamespacedName) {
 		log.Error(err, "Error building kubernetes clientset: %s", err.Error())
 	}
 
-	observer := NewAddressObserver(kubeClient, mgr.GetClient(), mgr.GetScheme())
+	observer := NewAddressObserver(kubeClient, mgr.GetClient(), mgr.GetScheme(), recorder)
 
 	if err = observer.Run(channels.AddressListeningCh); err != nil {
 		log.Error(err, "Error running controller: %s", err.Error())
@@ -90,7 +100,12 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 	}
 
 	// Watch for changes to primary resource ActiveMQArtemisAddress
-	err = c.Watch(&source.Kind{Type: &brokerv2alpha3.ActiveMQArtemisAddress{}}, &handler.EnqueueRequestForObject{})
+	// Status is written back by the controller, only react to spec changes
+	err = c.Watch(&source.Kind{Type: &brokerv2alpha3.ActiveMQArtemisAddress{}}, &handler.EnqueueRequestForObject{}, predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return e.MetaOld.GetGeneration() != e.MetaNew.GetGeneration()
+		},
+	})
 	if err != nil {
 		return err
 	}
@@ -114,8 +129,9 @@ var _ reconcile.Reconciler = &ReconcileActiveMQArtemisAddress{}
 type ReconcileActiveMQArtemisAddress struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	client client.Client
-	scheme *runtime.Scheme
+	client   client.Client
+	scheme   *runtime.Scheme
+	recorder record.EventRecorder
 }
 
 // Reconcile reads that state of the cluster for a ActiveMQArtemisAddress object and makes changes based on the state read
@@ -165,17 +181,36 @@ func (r *ReconcileActiveMQArtemisAddress) Reconcile(request reconcile.Request) (
 		if existingCr := lsrcrs.RetrieveLastSuccessfulReconciledCR(request.NamespacedName, "address", r.client, getLabels(instance)); existingCr != nil {
 			//compare resource version
 			if existingCr.Checksum == instance.ResourceVersion {
-				log.V(1).Info("The incoming address CR is identical to stored CR, don't do reconcile")
-				return reconcile.Result{}, nil
+				// The CR was applied before the operator restarted, but the brokers may
+				// have changed since, so check them rather than skipping the CR
+				log.V(1).Info("The incoming address CR is identical to stored CR, checking it against brokers")
+				addressDeployment := AddressDeployment{
+					AddressResource:      *instance,
+					SsTargetNameBuilders: createNameBuilders(instance),
+				}
+				resyncQueue(&addressDeployment, request, r.client, r.scheme)
+				namespacedNameToAddressName[request.NamespacedName] = addressDeployment
+				instance.Status = addressDeployment.AddressResource.Status
+				updateAddressStatus(r.client, instance)
+				return getResyncResult(instance), nil
 			}
 		}
+	} else if addressInstance.AddressResource.Generation == instance.Generation {
+		// Only status updates are filtered out of the watch, so an unchanged
+		// generation means this is the periodic check requeued below
+		resyncQueue(&addressInstance, request, r.client, r.scheme)
+		instance.Status = addressInstance.AddressResource.Status
+		updateAddressStatus(r.client, instance)
+		return getResyncResult(instance), nil
 	}
 
 	addressDeployment := AddressDeployment{
 		AddressResource:      *instance,
 		SsTargetNameBuilders: createNameBuilders(instance),
 	}
-	err = createQueue(&addressDeployment, request, r.client, r.scheme)
+	err = createQueue(&addressDeployment, request, r.client, r.scheme, r.recorder)
+	instance.Status = addressDeployment.AddressResource.Status
+	updateAddressStatus(r.client, instance)
 	if nil == err {
 		namespacedNameToAddressName[request.NamespacedName] = addressDeployment
 		crstr, merr := common.ToJson(instance)
@@ -185,7 +220,7 @@ func (r *ReconcileActiveMQArtemisAddress) Reconcile(request reconcile.Request) (
 		lsrcrs.StoreLastSuccessfulReconciledCR(instance, instance.Name, instance.Namespace, "address", crstr, "", instance.ResourceVersion, getLabels(instance), r.client, r.scheme)
 	}
 
-	return reconcile.Result{}, nil
+	return getResyncResult(instance), nil
 }
 
 func getLabels(cr *brokerv2alpha3.ActiveMQArtemisAddress) map[string]string {
@@ -228,27 +263,52 @@ func createNameBuilders(instance *brokerv2alpha3.ActiveMQArtemisAddress) []SSInf
 }
 
 // This method deals with creating queues and addresses.
-func createQueue(instance *AddressDeployment, request reconcile.Request, client client.Client, scheme *runtime.Scheme) error {
+func createQueue(instance *AddressDeployment, request reconcile.Request, client client.Client, scheme *runtime.Scheme, recorder record.EventRecorder) error {
 
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Creating ActiveMQArtemisAddress")
 
 	var err error = nil
 	artemisArray := getPodBrokers(instance, request, client, scheme)
+	instance.AddressResource.Status.BrokerStatus = nil
 	if nil != artemisArray {
 		for _, a := range artemisArray {
-			if nil == a {
-				reqLogger.Info("Creating ActiveMQArtemisAddress artemisArray had a nil!")
+			if nil == a.Artemis {
+				reqLogger.Info("Creating ActiveMQArtemisAddress broker pod not available", "pod", a.PodName)
+				instance.AddressResource.Status.BrokerStatus = append(instance.AddressResource.Status.BrokerStatus, newBrokerAddressStatus(a.PodName, false, false, a.LookupError))
 				continue
 			}
-			createAddressResource(a, &instance.AddressResource)
+			createErr := createAddressResource(a.Artemis, &instance.AddressResource)
+			recordCreateEvent(recorder, &instance.AddressResource, a.PodName, createErr)
+			instance.AddressResource.Status.BrokerStatus = append(instance.AddressResource.Status.BrokerStatus, checkBrokerAddressStatus(a.PodName, a.Artemis, &instance.AddressResource, createErr))
 		}
 	}
 
 	return err
 }
 
-func createAddressResource(a *mgmt.Artemis, addressRes *brokerv2alpha3.ActiveMQArtemisAddress) {
+// recordCreateEvent records the outcome of creating the address or queue on a broker pod,
+// doing nothing without a recorder
+func recordCreateEvent(recorder record.EventRecorder, addressRes *brokerv2alpha3.ActiveMQArtemisAddress, podName string, err error) {
+	if recorder == nil {
+		return
+	}
+	name := addressRes.Spec.AddressName
+	if addressRes.Spec.QueueName != nil && *addressRes.Spec.QueueName != "" {
+		name = *addressRes.Spec.QueueName
+	}
+	switch {
+	case err == nil:
+		recorder.Eventf(addressRes, corev1.EventTypeNormal, EventReasonAddressCreated, "Created %s on broker pod %s", name, podName)
+	case brokerclient.IsUnreachable(err):
+		recorder.Eventf(addressRes, corev1.EventTypeWarning, EventReasonJolokiaUnreachable, "Broker pod %s could not be reached over jolokia: %v", podName, err)
+	default:
+		recorder.Eventf(addressRes, corev1.EventTypeWarning, EventReasonAddressCreationFailed, "Failed to create %s on broker pod %s: %v", name, podName, err)
+	}
+}
+
+// createAddressResource creates the address or queue on the broker, returning the last management api error
+func createAddressResource(a *brokerclient.Client, addressRes *brokerv2alpha3.ActiveMQArtemisAddress) error {
 	//Now checking if create queue or address
 	var err error
 	if addressRes.Spec.QueueName == nil || *addressRes.Spec.QueueName == "" {
@@ -256,7 +316,7 @@ func createAddressResource(a *mgmt.Artemis, addressRes *brokerv2alpha3.ActiveMQA
 		_, err = a.CreateAddress(addressRes.Spec.AddressName, *addressRes.Spec.RoutingType)
 		if nil != err {
 			log.Error(err, "Creating ActiveMQArtemisAddress error for address", addressRes.Spec.AddressName)
-			return
+			return err
 		} else {
 			log.Info("Created ActiveMQArtemisAddress for address " + addressRes.Spec.AddressName)
 		}
@@ -270,34 +330,17 @@ func createAddressResource(a *mgmt.Artemis, addressRes *brokerv2alpha3.ActiveMQA
 			_, err := a.CreateQueue(addressRes.Spec.AddressName, *addressRes.Spec.QueueName, routingType)
 			if nil != err {
 				log.Error(err, "Creating ActiveMQArtemisAddress error for "+*addressRes.Spec.QueueName)
-				return
+				return err
 			} else {
 				log.Info("Created ActiveMQArtemisAddress for " + *addressRes.Spec.QueueName)
 			}
 		} else {
-			//create queue using queueconfig
-			queueCfg, ignoreIfExists, err := GetQueueConfig(addressRes)
-			if err != nil {
-				log.Error(err, "Failed to get queue config json string")
-				return
-			}
-			respData, err := a.CreateQueueFromConfig(queueCfg, ignoreIfExists)
-			if nil != err {
-				if mgmt.GetCreationError(respData) == mgmt.QUEUE_ALREADY_EXISTS {
-					log.Info("The queue already exists, updating", "queue", queueCfg)
-					respData, err := a.UpdateQueue(queueCfg)
-					if err != nil {
-						log.Error(err, "Failed to update queue", "details", respData)
-					}
-					return
-				}
-				log.Error(err, "Creating ActiveMQArtemisAddress error for "+*addressRes.Spec.QueueName)
-				return
-			} else {
-				log.Info("Created ActiveMQArtemisAddress for " + *addressRes.Spec.QueueName)
-			}
+			//create or update queue using queueconfig
+			_, err := CreateOrUpdateQueue(a, addressRes)
+			return err
 		}
 	}
+	return nil
 }
 
 // This method deals with deleting queues and addresses.
@@ -308,14 +351,22 @@ func deleteQueue(instance *AddressDeployment, request reconcile.Request, client
 
 	var err error = nil
 	artemisArray := getPodBrokers(instance, request, client, scheme)
+	instance.AddressResource.Status.BrokerStatus = nil
 	if nil != artemisArray {
-		for _, a := range artemisArray {
+		for _, pb := range artemisArray {
+			a := pb.Artemis
+			if nil == a {
+				reqLogger.Info("Deleting ActiveMQArtemisAddress broker pod not available", "pod", pb.PodName)
+				instance.AddressResource.Status.BrokerStatus = append(instance.AddressResource.Status.BrokerStatus, newBrokerAddressStatus(pb.PodName, false, false, pb.LookupError))
+				continue
+			}
 			if instance.AddressResource.Spec.QueueName == nil || *instance.AddressResource.Spec.QueueName == "" {
 				//delete address
 				_, err := a.DeleteAddress(instance.AddressResource.Spec.AddressName)
+				instance.AddressResource.Status.BrokerStatus = append(instance.AddressResource.Status.BrokerStatus, newBrokerAddressStatus(pb.PodName, nil != err, false, err))
 				if nil != err {
 					reqLogger.Error(err, "Deleting ActiveMQArtemisAddress error for address ", instance.AddressResource.Spec.AddressName)
-					break
+					continue
 				}
 				reqLogger.Info("Deleted ActiveMQArtemisAddress for address " + instance.AddressResource.Spec.AddressName)
 			} else {
@@ -323,7 +374,8 @@ func deleteQueue(instance *AddressDeployment, request reconcile.Request, client
 				_, err := a.DeleteQueue(*instance.AddressResource.Spec.QueueName)
 				if nil != err {
 					reqLogger.Error(err, "Deleting ActiveMQArtemisAddress error for queue "+*instance.AddressResource.Spec.QueueName)
-					break
+					instance.AddressResource.Status.BrokerStatus = append(instance.AddressResource.Status.BrokerStatus, checkBrokerAddressStatus(pb.PodName, a, &instance.AddressResource, err))
+					continue
 				} else {
 					reqLogger.Info("Deleted ActiveMQArtemisAddress for queue " + *instance.AddressResource.Spec.QueueName)
 					reqLogger.Info("Checking parent address for bindings " + instance.AddressResource.Spec.AddressName)
@@ -331,25 +383,38 @@ func deleteQueue(instance *AddressDeployment, request reconcile.Request, client
 					if nil == err {
 						if "" == bindingsData.Value {
 							reqLogger.Info("No bindings found removing " + instance.AddressResource.Spec.AddressName)
-							a.DeleteAddress(instance.AddressResource.Spec.AddressName)
+							_, err = a.DeleteAddress(instance.AddressResource.Spec.AddressName)
 						} else {
 							reqLogger.Info("Bindings found, not removing " + instance.AddressResource.Spec.AddressName)
 						}
 					}
+					instance.AddressResource.Status.BrokerStatus = append(instance.AddressResource.Status.BrokerStatus, checkBrokerAddressStatus(pb.PodName, a, &instance.AddressResource, err))
 				}
 			}
 		}
 	}
 
+	for _, brokerStatus := range instance.AddressResource.Status.BrokerStatus {
+		reqLogger.Info("Address removal result", "pod", brokerStatus.PodName, "addressExists", brokerStatus.AddressExists, "queueExists", brokerStatus.QueueExists, "lastError", brokerStatus.LastError)
+	}
+
 	return err
 }
 
-func getPodBrokers(instance *AddressDeployment, request reconcile.Request, client client.Client, scheme *runtime.Scheme) []*mgmt.Artemis {
+// podBroker is the management client for one target broker pod. Artemis is nil
+// when the pod could not be looked up, in which case LookupError says why.
+type podBroker struct {
+	PodName     string
+	Artemis     *brokerclient.Client
+	LookupError error
+}
+
+func getPodBrokers(instance *AddressDeployment, request reconcile.Request, client client.Client, scheme *runtime.Scheme) []podBroker {
 
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
 	reqLogger.Info("Getting Pod Brokers", "instance", instance)
 
-	var artemisArray []*mgmt.Artemis = nil
+	var artemisArray []podBroker = nil
 	var jolokiaSecretName string = request.Name + "-jolokia-secret"
 
 	targetCrNamespacedNames := createTargetCrNamespacedNames(request.Namespace, instance.AddressResource.Spec.ApplyToCrNames)
@@ -388,6 +453,7 @@ func getPodBrokers(instance *AddressDeployment, request reconcile.Request, clien
 					} else {
 						reqLogger.Error(err, "Pod lookup error", "Namespace", request.Namespace, "Name", request.Name)
 					}
+					artemisArray = append(artemisArray, podBroker{PodName: s, LookupError: err})
 				} else {
 					reqLogger.Info("Pod found", "Namespace", request.Namespace, "Name", request.Name)
 					containers := pod.Spec.Containers //get env from this
@@ -395,8 +461,8 @@ func getPodBrokers(instance *AddressDeployment, request reconcile.Request, clien
 					jolokiaUser, jolokiaPassword, jolokiaProtocol := resolveJolokiaRequestParams(request.Namespace, &instance.AddressResource, client, scheme, jolokiaSecretName, &containers, podNamespacedName, statefulset, info.Labels)
 
 					reqLogger.Info("New Jolokia with ", "User: ", jolokiaUser, "Protocol: ", jolokiaProtocol)
-					artemis := mgmt.GetArtemis(pod.Status.PodIP, "8161", "amq-broker", jolokiaUser, jolokiaPassword, jolokiaProtocol)
-					artemisArray = append(artemisArray, artemis)
+					artemis := brokerclient.GetClient(pod.Status.PodIP, "8161", "amq-broker", jolokiaUser, jolokiaPassword, jolokiaProtocol)
+					artemisArray = append(artemisArray, podBroker{PodName: s, Artemis: artemis})
 				}
 			}
 		}
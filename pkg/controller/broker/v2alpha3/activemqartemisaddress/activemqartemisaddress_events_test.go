@@ -0,0 +1,78 @@
+package v2alpha3activemqartemisaddress
+
+import (
+	brokerv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/brokerclient"
+	"github.com/artemiscloud/activemq-artemis-operator/test/utils/fakebroker"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func newEventsAddress() *brokerv2alpha3.ActiveMQArtemisAddress {
+	queueName := "orders"
+	routingType := "anycast"
+	return &brokerv2alpha3.ActiveMQArtemisAddress{
+		Spec: brokerv2alpha3.ActiveMQArtemisAddressSpec{
+			AddressName: "orders",
+			QueueName:   &queueName,
+			RoutingType: &routingType,
+		},
+	}
+}
+
+var _ = Describe("Address creation events", func() {
+
+	var broker *fakebroker.Broker
+	var recorder *record.FakeRecorder
+	var addressRes *brokerv2alpha3.ActiveMQArtemisAddress
+
+	BeforeEach(func() {
+		broker = fakebroker.NewBroker("amq-broker", "admin", "admin")
+		recorder = record.NewFakeRecorder(10)
+		addressRes = newEventsAddress()
+	})
+
+	AfterEach(func() {
+		broker.Close()
+	})
+
+	createOn := func(password string) error {
+		client := brokerclient.GetClient(broker.Host(), broker.Port(), "amq-broker", "admin", password, "http")
+		err := createAddressResource(client, addressRes)
+		recordCreateEvent(recorder, addressRes, "ex-aao-ss-0", err)
+		return err
+	}
+
+	It("records a normal event when the queue is created", func() {
+		Expect(createOn("admin")).To(Succeed())
+
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix(corev1.EventTypeNormal+" "+EventReasonAddressCreated),
+			ContainSubstring("Created orders on broker pod ex-aao-ss-0"))))
+	})
+
+	It("records a warning event when the broker rejects the creation", func() {
+		Expect(createOn("wrong")).NotTo(Succeed())
+
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix(corev1.EventTypeWarning+" "+EventReasonAddressCreationFailed),
+			ContainSubstring("Failed to create orders on broker pod ex-aao-ss-0"))))
+	})
+
+	It("records a warning event when jolokia cannot be reached", func() {
+		broker.Close()
+		err := createOn("admin")
+		Expect(brokerclient.IsUnreachable(err)).To(BeTrue())
+
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix(corev1.EventTypeWarning+" "+EventReasonJolokiaUnreachable),
+			ContainSubstring("Broker pod ex-aao-ss-0 could not be reached over jolokia"))))
+	})
+
+	It("records nothing without a recorder", func() {
+		Expect(func() { recordCreateEvent(nil, addressRes, "ex-aao-ss-0", nil) }).NotTo(Panic())
+		Expect(recorder.Events).NotTo(Receive())
+	})
+})
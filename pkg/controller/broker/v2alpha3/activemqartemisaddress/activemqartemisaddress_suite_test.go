@@ -0,0 +1,13 @@
+package v2alpha3activemqartemisaddress
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestV2alpha3ActiveMQArtemisAddress(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "V2alpha3 ActiveMQArtemisAddress Suite")
+}
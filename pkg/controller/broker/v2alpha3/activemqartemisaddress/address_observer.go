@@ -23,9 +23,9 @@ import (
 
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/namer"
 
-	mgmt "github.com/artemiscloud/activemq-artemis-management"
 	v2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
 	clientv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/client/clientset/versioned/typed/broker/v2alpha3"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/brokerclient"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -46,17 +47,20 @@ type AddressObserver struct {
 	kubeclientset kubernetes.Interface
 	opclient      client.Client
 	opscheme      *runtime.Scheme
+	recorder      record.EventRecorder
 }
 
 func NewAddressObserver(
 	kubeclientset kubernetes.Interface,
 	client client.Client,
-	scheme *runtime.Scheme) *AddressObserver {
+	scheme *runtime.Scheme,
+	recorder record.EventRecorder) *AddressObserver {
 
 	observer := &AddressObserver{
 		kubeclientset: kubeclientset,
 		opclient:      client,
 		opscheme:      scheme,
+		recorder:      recorder,
 	}
 
 	return observer
@@ -174,8 +178,11 @@ func (c *AddressObserver) checkCRsForNewPod(newPod *corev1.Pod, labels map[strin
 				&a, c.opclient, c.opscheme, jolokiaSecretName, &newPod.Spec.Containers, podNamespacedName, statefulset, labels)
 
 			log.Info("New Jolokia with ", "User: ", jolokiaUser, "Protocol: ", jolokiaProtocol)
-			artemis := mgmt.GetArtemis(newPod.Status.PodIP, "8161", "amq-broker", jolokiaUser, jolokiaPassword, jolokiaProtocol)
-			createAddressResource(artemis, &a)
+			artemis := brokerclient.GetClient(newPod.Status.PodIP, "8161", "amq-broker", jolokiaUser, jolokiaPassword, jolokiaProtocol)
+			createErr := createAddressResource(artemis, &a)
+			recordCreateEvent(c.recorder, &a, newPod.Name, createErr)
+			setBrokerAddressStatus(&a, checkBrokerAddressStatus(newPod.Name, artemis, &a, createErr))
+			updateAddressStatus(c.opclient, &a)
 		}
 	}
 }
@@ -0,0 +1,179 @@
+package v2alpha3activemqartemisaddress
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	brokerv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/brokerclient"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const defaultResyncPeriodSeconds = 60
+
+// getResyncPeriod returns how long to wait before checking the address against the
+// brokers again, 0 when no periodic check is wanted. Addresses without a queue have
+// no bindings to compare, so they are only checked in strict mode.
+func getResyncPeriod(addressRes *brokerv2alpha3.ActiveMQArtemisAddress) time.Duration {
+
+	hasQueue := addressRes.Spec.QueueName != nil && *addressRes.Spec.QueueName != ""
+	if !hasQueue && !addressRes.Spec.StrictQueueManagement {
+		return 0
+	}
+
+	var period int32 = defaultResyncPeriodSeconds
+	if addressRes.Spec.ResyncPeriodSeconds != nil {
+		period = *addressRes.Spec.ResyncPeriodSeconds
+	}
+	if period <= 0 {
+		return 0
+	}
+	return time.Duration(period) * time.Second
+}
+
+func getResyncResult(addressRes *brokerv2alpha3.ActiveMQArtemisAddress) reconcile.Result {
+	if period := getResyncPeriod(addressRes); period > 0 {
+		return reconcile.Result{RequeueAfter: period}
+	}
+	return reconcile.Result{}
+}
+
+// getManagedQueues returns the names of the queues declared on the address by any
+// ActiveMQArtemisAddress in the namespace
+func getManagedQueues(addressRes *brokerv2alpha3.ActiveMQArtemisAddress, c client.Client) (map[string]bool, error) {
+
+	managedQueues := make(map[string]bool)
+
+	addressList := &brokerv2alpha3.ActiveMQArtemisAddressList{}
+	if err := c.List(context.TODO(), &client.ListOptions{Namespace: addressRes.Namespace}, addressList); err != nil {
+		return nil, err
+	}
+	for _, item := range addressList.Items {
+		if item.Spec.AddressName == addressRes.Spec.AddressName && item.Spec.QueueName != nil && *item.Spec.QueueName != "" {
+			managedQueues[*item.Spec.QueueName] = true
+		}
+	}
+	// The list may come from a cache that has not seen this address yet
+	if addressRes.Spec.QueueName != nil && *addressRes.Spec.QueueName != "" {
+		managedQueues[*addressRes.Spec.QueueName] = true
+	}
+
+	return managedQueues, nil
+}
+
+// This method checks the address against each target broker, recreating the queue
+// where it has gone missing, restoring its configuration where it has been changed
+// and, in strict mode, removing queues nobody declared.
+func resyncQueue(instance *AddressDeployment, request reconcile.Request, client client.Client, scheme *runtime.Scheme) error {
+
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	reqLogger.V(1).Info("Checking ActiveMQArtemisAddress against brokers")
+
+	addressRes := &instance.AddressResource
+
+	managedQueues, err := getManagedQueues(addressRes, client)
+	if err != nil {
+		reqLogger.Error(err, "Failed to list address resources, skipping check")
+		return err
+	}
+
+	artemisArray := getPodBrokers(instance, request, client, scheme)
+	addressRes.Status.BrokerStatus = nil
+	for _, pb := range artemisArray {
+		if nil == pb.Artemis {
+			addressRes.Status.BrokerStatus = append(addressRes.Status.BrokerStatus, newBrokerAddressStatus(pb.PodName, false, false, pb.LookupError))
+			continue
+		}
+		addressRes.Status.BrokerStatus = append(addressRes.Status.BrokerStatus, resyncBroker(pb, addressRes, managedQueues))
+	}
+
+	return nil
+}
+
+func resyncBroker(pb podBroker, addressRes *brokerv2alpha3.ActiveMQArtemisAddress, managedQueues map[string]bool) brokerv2alpha3.BrokerAddressStatus {
+
+	a := pb.Artemis
+	bindingsData, err := a.ListBindingsForAddress(addressRes.Spec.AddressName)
+	if err != nil {
+		log.Error(err, "Failed to list bindings", "pod", pb.PodName, "address", addressRes.Spec.AddressName)
+		return newBrokerAddressStatus(pb.PodName, false, false, err)
+	}
+	queueNames := queueNamesFromBindings(bindingsData.Value)
+
+	driftDetected := false
+	var opErr error = nil
+
+	if addressRes.Spec.QueueName != nil && *addressRes.Spec.QueueName != "" {
+		found := false
+		for _, name := range queueNames {
+			if name == *addressRes.Spec.QueueName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			log.Info("Queue missing on broker, recreating", "pod", pb.PodName, "queue", *addressRes.Spec.QueueName)
+			driftDetected = true
+			opErr = createAddressResource(a, addressRes)
+		} else if addressRes.Spec.QueueConfiguration != nil {
+			// queue attributes may have been changed behind our back through the console
+			updated, err := CreateOrUpdateQueue(a, addressRes)
+			driftDetected = updated
+			opErr = err
+		}
+	}
+
+	var unmanagedQueues []string = nil
+	for _, name := range queueNames {
+		if managedQueues[name] {
+			continue
+		}
+		unmanagedQueues = append(unmanagedQueues, name)
+		if addressRes.Spec.StrictQueueManagement {
+			removed, err := RemoveUnmanagedQueue(a, addressRes.Spec.AddressName, name)
+			if err != nil {
+				log.Error(err, "Failed to remove unmanaged queue", "pod", pb.PodName, "queue", name)
+				opErr = err
+			}
+			driftDetected = driftDetected || removed
+		}
+	}
+
+	brokerStatus := checkBrokerAddressStatus(pb.PodName, a, addressRes, opErr)
+	brokerStatus.DriftDetected = driftDetected
+	brokerStatus.UnmanagedQueues = unmanagedQueues
+	return brokerStatus
+}
+
+// RemoveUnmanagedQueue removes a queue of the address nobody declared, provided it is a
+// durable anycast queue without consumers. Subscription queues of multicast addresses,
+// temporary and non durable queues belong to the clients that created them and are left
+// alone, as are queues being consumed from. It tells whether the queue was removed.
+func RemoveUnmanagedQueue(a *brokerclient.Client, addressName string, queueName string) (bool, error) {
+
+	queue, err := a.FindQueue(queueName)
+	if err != nil || queue == nil || queue.Address != addressName {
+		return false, err
+	}
+	attributes, err := a.GetQueueAttributes(*queue)
+	if err != nil {
+		return false, err
+	}
+	if !strings.EqualFold(attributes.RoutingType, "ANYCAST") || !attributes.Durable || attributes.Temporary {
+		log.V(1).Info("Keeping unmanaged client queue", "queue", queueName, "routingType", attributes.RoutingType, "durable", attributes.Durable, "temporary", attributes.Temporary)
+		return false, nil
+	}
+	if attributes.ConsumerCount > 0 {
+		log.Info("Keeping unmanaged queue with consumers", "queue", queueName, "consumers", attributes.ConsumerCount)
+		return false, nil
+	}
+
+	log.Info("Removing unmanaged queue from broker", "queue", queueName)
+	if _, err := a.DeleteQueue(queueName); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package v2alpha3activemqartemisaddress
+
+import (
+	"context"
+	"regexp"
+
+	brokerv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/brokerclient"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Queue bindings are listed by the broker as ..., queue=(QueueImpl[name=<queue>, postOffice=...
+var queueBindingNameRegexp = regexp.MustCompile(`QueueImpl\[name=([^,\]]+)`)
+
+// queueNamesFromBindings extracts the queue names from the value returned by
+// listBindingsForAddress
+func queueNamesFromBindings(bindings string) []string {
+	var names []string = nil
+	for _, match := range queueBindingNameRegexp.FindAllStringSubmatch(bindings, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+func newBrokerAddressStatus(podName string, addressExists bool, queueExists bool, err error) brokerv2alpha3.BrokerAddressStatus {
+	brokerStatus := brokerv2alpha3.BrokerAddressStatus{
+		PodName:       podName,
+		AddressExists: addressExists,
+		QueueExists:   queueExists,
+		LastUpdated:   metav1.Now(),
+	}
+	if err != nil {
+		brokerStatus.LastError = err.Error()
+	}
+	return brokerStatus
+}
+
+// checkBrokerAddressStatus asks the broker which of the address and queue are present
+// after an operation that returned opErr
+func checkBrokerAddressStatus(podName string, a *brokerclient.Client, addressRes *brokerv2alpha3.ActiveMQArtemisAddress, opErr error) brokerv2alpha3.BrokerAddressStatus {
+
+	queueName := ""
+	if addressRes.Spec.QueueName != nil {
+		queueName = *addressRes.Spec.QueueName
+	}
+
+	// The address is looked up on its own, an address without bindings yet is still there
+	addressNames, err := a.ListAddresses()
+	if err != nil {
+		log.Error(err, "Failed to list addresses", "pod", podName, "address", addressRes.Spec.AddressName)
+		if opErr == nil {
+			opErr = err
+		}
+		return newBrokerAddressStatus(podName, false, false, opErr)
+	}
+	addressExists := false
+	for _, name := range addressNames {
+		if name == addressRes.Spec.AddressName {
+			addressExists = true
+			break
+		}
+	}
+
+	queueExists := false
+	if addressExists && queueName != "" {
+		bindingsData, err := a.ListBindingsForAddress(addressRes.Spec.AddressName)
+		if err != nil {
+			log.Error(err, "Failed to list bindings", "pod", podName, "address", addressRes.Spec.AddressName)
+			if opErr == nil {
+				opErr = err
+			}
+			return newBrokerAddressStatus(podName, addressExists, false, opErr)
+		}
+		for _, name := range queueNamesFromBindings(bindingsData.Value) {
+			if name == queueName {
+				queueExists = true
+				break
+			}
+		}
+	}
+
+	return newBrokerAddressStatus(podName, addressExists, queueExists, opErr)
+}
+
+// setBrokerAddressStatus replaces the status entry for the pod, adding it if not present
+func setBrokerAddressStatus(addressRes *brokerv2alpha3.ActiveMQArtemisAddress, brokerStatus brokerv2alpha3.BrokerAddressStatus) {
+	for i := range addressRes.Status.BrokerStatus {
+		if addressRes.Status.BrokerStatus[i].PodName == brokerStatus.PodName {
+			addressRes.Status.BrokerStatus[i] = brokerStatus
+			return
+		}
+	}
+	addressRes.Status.BrokerStatus = append(addressRes.Status.BrokerStatus, brokerStatus)
+}
+
+func updateAddressStatus(client client.Client, addressRes *brokerv2alpha3.ActiveMQArtemisAddress) error {
+	err := client.Status().Update(context.TODO(), addressRes)
+	if err != nil {
+		log.Error(err, "Failed to update address status", "address", addressRes.Name)
+	}
+	return err
+}
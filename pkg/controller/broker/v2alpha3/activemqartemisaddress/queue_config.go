@@ -44,6 +44,19 @@ type ActiveMQArtemisQueueConfiguration struct {
 
 // convert QueueConfiguration to json string
 func GetQueueConfig(addressRes *brokerv2alpha3.ActiveMQArtemisAddress) (string, bool, error) {
+	artemisQueueConfig, ignoreIfExists := getArtemisQueueConfig(addressRes)
+
+	bytes, err := json.Marshal(artemisQueueConfig)
+	if err != nil {
+		log.Error(err, "Error marshalling queue config", "config", artemisQueueConfig)
+		return "", false, err
+	}
+	return string(bytes), ignoreIfExists, nil
+}
+
+// getArtemisQueueConfig maps the QueueConfiguration of the address to the configuration
+// understood by the broker, together with the ignoreIfExists flag
+func getArtemisQueueConfig(addressRes *brokerv2alpha3.ActiveMQArtemisAddress) (ActiveMQArtemisQueueConfiguration, bool) {
 	ignoreIfExists := false
 	addressSpec := addressRes.Spec
 	configSpec := addressRes.Spec.QueueConfiguration
@@ -87,10 +100,5 @@ func GetQueueConfig(addressRes *brokerv2alpha3.ActiveMQArtemisAddress) (string,
 	artemisQueueConfig.Temporary = configSpec.Temporary
 	artemisQueueConfig.AutoCreateAddress = configSpec.AutoCreateAddress
 
-	bytes, err := json.Marshal(artemisQueueConfig)
-	if err != nil {
-		log.Error(err, "Error marshalling queue config", "config", artemisQueueConfig)
-		return "", false, err
-	}
-	return string(bytes), ignoreIfExists, nil
+	return artemisQueueConfig, ignoreIfExists
 }
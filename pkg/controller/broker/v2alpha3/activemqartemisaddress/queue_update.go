@@ -0,0 +1,135 @@
+package v2alpha3activemqartemisaddress
+
+import (
+	"fmt"
+	"strings"
+
+	mgmt "github.com/artemiscloud/activemq-artemis-management"
+	brokerv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/brokerclient"
+)
+
+// DiffQueueConfig compares the configured queue with the attributes read from the broker.
+// Only the settings given in the configuration are compared. It returns the settings that
+// updateQueue can apply and the ones that would need the queue to be recreated.
+func DiffQueueConfig(config ActiveMQArtemisQueueConfiguration, current *brokerclient.QueueAttributes) ([]string, []string) {
+
+	var changed []string = nil
+	var notUpdatable []string = nil
+
+	if config.Address != nil && *config.Address != current.Address {
+		notUpdatable = append(notUpdatable, "address")
+	}
+	if config.RoutingType != nil && !strings.EqualFold(*config.RoutingType, current.RoutingType) {
+		notUpdatable = append(notUpdatable, "routing-type")
+	}
+	if config.Durable != nil && *config.Durable != current.Durable {
+		notUpdatable = append(notUpdatable, "durable")
+	}
+	if config.Temporary != nil && *config.Temporary != current.Temporary {
+		notUpdatable = append(notUpdatable, "temporary")
+	}
+
+	diffString := func(name string, desired *string, actual string) {
+		if desired != nil && *desired != actual {
+			changed = append(changed, name)
+		}
+	}
+	diffBool := func(name string, desired *bool, actual bool) {
+		if desired != nil && *desired != actual {
+			changed = append(changed, name)
+		}
+	}
+	diffInt := func(name string, desired *int64, actual int64) {
+		if desired != nil && *desired != actual {
+			changed = append(changed, name)
+		}
+	}
+	int32Of := func(value *int32) *int64 {
+		if value == nil {
+			return nil
+		}
+		v := int64(*value)
+		return &v
+	}
+
+	diffString("filter-string", config.FilterString, current.Filter)
+	diffString("user", config.User, current.User)
+	diffInt("max-consumers", int32Of(config.MaxConsumers), int64(current.MaxConsumers))
+	diffBool("exclusive", config.Exclusive, current.Exclusive)
+	diffBool("group-rebalance", config.GroupRebalance, current.GroupRebalance)
+	diffInt("group-buckets", int32Of(config.GroupBuckets), int64(current.GroupBuckets))
+	diffString("group-first-key", config.GroupFirstKey, current.GroupFirstKey)
+	diffBool("last-value", config.LastValue, current.LastValue)
+	diffString("last-value-key", config.LastValueKey, current.LastValueKey)
+	diffBool("non-destructive", config.NonDestructive, current.NonDestructive)
+	diffBool("purge-on-no-consumers", config.PurgeOnNoConsumers, current.PurgeOnNoConsumers)
+	diffBool("enabled", config.Enabled, current.Enabled)
+	diffInt("consumers-before-dispatch", int32Of(config.ConsumersBeforeDispatch), int64(current.ConsumersBeforeDispatch))
+	diffInt("delay-before-dispatch", config.DelayBeforeDispatch, current.DelayBeforeDispatch)
+	diffInt("ring-size", config.RingSize, current.RingSize)
+	diffBool("configuration-managed", config.ConfigurationManaged, current.ConfigurationManaged)
+
+	return changed, notUpdatable
+}
+
+// CreateOrUpdateQueue makes the queue on the broker match the QueueConfiguration of the
+// address. A missing queue is created, an existing one is updated in place when its
+// attributes differ. Settings that can't be changed in place are returned as an error
+// and nothing is updated. It returns true when the broker was changed.
+func CreateOrUpdateQueue(a *brokerclient.Client, addressRes *brokerv2alpha3.ActiveMQArtemisAddress) (bool, error) {
+
+	queueCfg, ignoreIfExists, err := GetQueueConfig(addressRes)
+	if err != nil {
+		log.Error(err, "Failed to get queue config json string")
+		return false, err
+	}
+
+	queue, err := a.FindQueue(*addressRes.Spec.QueueName)
+	if err != nil {
+		log.Error(err, "Failed to look up queue", "queue", *addressRes.Spec.QueueName)
+		return false, err
+	}
+
+	if queue == nil {
+		respData, err := a.CreateQueueFromConfig(queueCfg, ignoreIfExists)
+		if nil != err {
+			if respData != nil && mgmt.GetCreationError(respData) == mgmt.QUEUE_ALREADY_EXISTS {
+				// created in the meantime, compare it on the next check
+				log.Info("The queue already exists", "queue", *addressRes.Spec.QueueName)
+				return false, nil
+			}
+			log.Error(err, "Creating ActiveMQArtemisAddress error for "+*addressRes.Spec.QueueName)
+			return false, err
+		}
+		log.Info("Created ActiveMQArtemisAddress for " + *addressRes.Spec.QueueName)
+		return true, nil
+	}
+
+	attributes, err := a.GetQueueAttributes(*queue)
+	if err != nil {
+		log.Error(err, "Failed to read queue attributes", "queue", *addressRes.Spec.QueueName)
+		return false, err
+	}
+
+	config, _ := getArtemisQueueConfig(addressRes)
+	changed, notUpdatable := DiffQueueConfig(config, attributes)
+	if len(notUpdatable) > 0 {
+		err = fmt.Errorf("queue %s can't be updated in place, changed %s: remove and recreate the queue to apply them",
+			*addressRes.Spec.QueueName, strings.Join(notUpdatable, ", "))
+		log.Error(err, "Not updating queue")
+		return false, err
+	}
+	if len(changed) == 0 {
+		log.V(1).Info("Queue is up to date", "queue", *addressRes.Spec.QueueName)
+		return false, nil
+	}
+
+	log.Info("Updating queue", "queue", *addressRes.Spec.QueueName, "changed", changed)
+	respData, err := a.UpdateQueue(queueCfg)
+	if err != nil {
+		log.Error(err, "Failed to update queue", "details", respData)
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,398 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/certificates"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/random"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/selectors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+const (
+	// The PKCS#12 stores converted from the pem certificate and key of an ssl secret, and
+	// their password, kept in a secret of the operator
+	keyStoreFile      = "keystore.p12"
+	trustStoreFile    = "truststore.p12"
+	storesPasswordKey = "storePassword"
+	storeType         = "PKCS12"
+
+	// The digest of the pem certificate the stores were converted from
+	storesSourceAnnotation = "broker.amq.io/stores-source"
+)
+
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// The fields of a certificate spec the operator sets
+var certificateSpecFields = []string{"secretName", "secretTemplate", "issuerRef", "dnsNames", "duration", "renewBefore"}
+
+// ProcessCertificates requests the cert-manager certificates of the acceptors, connectors
+// and console, and converts the pem certificates of their ssl secrets to the stores the
+// broker loads. The cert-manager api is not vendored, its certificates are unstructured.
+// The stores get a new password each time they are converted, which changes the acceptor
+// and console arguments and so restarts the brokers once a certificate is renewed.
+func (reconciler *ActiveMQArtemisReconciler) ProcessCertificates(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme) {
+
+	customResource := fsm.customResource
+	for _, acceptor := range customResource.Spec.Acceptors {
+		secretName := sslSecretName(customResource, acceptor.Name, acceptor.SSLSecret)
+		syncSSLSecret(fsm, client, scheme, acceptor.Name, acceptor.SSLEnabled, secretName, acceptor.CertManager)
+	}
+
+	for _, connector := range customResource.Spec.Connectors {
+		secretName := sslSecretName(customResource, connector.Name, connector.SSLSecret)
+		syncSSLSecret(fsm, client, scheme, connector.Name, connector.SSLEnabled, secretName, connector.CertManager)
+	}
+
+	console := customResource.Spec.Console
+	secretName := sslSecretName(customResource, "console", console.SSLSecret)
+	syncSSLSecret(fsm, client, scheme, "console", console.SSLEnabled, secretName, console.CertManager)
+}
+
+// sslSecretName returns the secret of an acceptor, connector or the console holding its
+// certificate, <cr>-<name>-secret unless named in the spec
+func sslSecretName(customResource *brokerv2alpha5.ActiveMQArtemis, itemName string, sslSecret string) string {
+	if "" != sslSecret {
+		return sslSecret
+	}
+	return customResource.Name + "-" + itemName + "-secret"
+}
+
+// referencesSSLSecret tells whether an acceptor, connector or the console of the broker
+// has ssl enabled with the certificate of the secret
+func referencesSSLSecret(customResource *brokerv2alpha5.ActiveMQArtemis, secretName string) bool {
+	for _, acceptor := range customResource.Spec.Acceptors {
+		if acceptor.SSLEnabled && sslSecretName(customResource, acceptor.Name, acceptor.SSLSecret) == secretName {
+			return true
+		}
+	}
+	for _, connector := range customResource.Spec.Connectors {
+		if connector.SSLEnabled && sslSecretName(customResource, connector.Name, connector.SSLSecret) == secretName {
+			return true
+		}
+	}
+	console := customResource.Spec.Console
+	return console.SSLEnabled && sslSecretName(customResource, "console", console.SSLSecret) == secretName
+}
+
+// storesSecretName returns the secret the operator keeps the stores converted from the pem
+// certificate of an acceptor, connector or the console in
+func storesSecretName(customResource *brokerv2alpha5.ActiveMQArtemis, itemName string) string {
+	return customResource.Name + "-" + itemName + "-stores"
+}
+
+// sslSecretPredicate only lets through the events of the secrets labelled with the broker
+// they belong to. Cert-manager labels the secrets it issues for the operator, pem secrets
+// made by hand are to be labelled for their renewals to be picked up before the resync.
+func sslSecretPredicate() predicate.Funcs {
+	labelled := func(labels map[string]string) bool {
+		return labels[selectors.LabelResourceKey] != ""
+	}
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return labelled(e.Meta.GetLabels())
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return labelled(e.MetaNew.GetLabels())
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return labelled(e.Meta.GetLabels())
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return labelled(e.Meta.GetLabels())
+		},
+	}
+}
+
+// secretToBrokerRequests maps an ssl secret, as issued or renewed by cert-manager, to the
+// brokers using its certificate
+func secretToBrokerRequests(c client.Client) handler.ToRequestsFunc {
+	return func(obj handler.MapObject) []reconcile.Request {
+		list := &brokerv2alpha5.ActiveMQArtemisList{}
+		if err := c.List(context.TODO(), &client.ListOptions{Namespace: obj.Meta.GetNamespace()}, list); err != nil {
+			log.Error(err, "Failed to list brokers for secret", "secret", obj.Meta.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for i := range list.Items {
+			if referencesSSLSecret(&list.Items[i], obj.Meta.GetName()) {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+					Namespace: list.Items[i].Namespace,
+					Name:      list.Items[i].Name,
+				}})
+			}
+		}
+		return requests
+	}
+}
+
+func syncSSLSecret(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, itemName string, sslEnabled bool, secretName string, certManager *brokerv2alpha5.CertManagerType) {
+
+	var requested *unstructured.Unstructured
+	if sslEnabled && certManager != nil {
+		requested = NewCertificateForCR(fsm, itemName, secretName, certManager)
+	}
+	syncCertificate(fsm, client, scheme, itemName, requested)
+
+	syncKeyStores(fsm, client, scheme, itemName, sslEnabled, secretName)
+}
+
+func syncCertificate(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, itemName string, requested *unstructured.Unstructured) {
+
+	namespacedName := types.NamespacedName{
+		Name:      certificateName(fsm, itemName),
+		Namespace: fsm.customResource.Namespace,
+	}
+	deployed := &unstructured.Unstructured{}
+	deployed.SetGroupVersionKind(certificateGVK)
+	err := client.Get(context.TODO(), namespacedName, deployed)
+	switch {
+	case meta.IsNoMatchError(err):
+		if requested != nil {
+			log.Info("Certificate not created, cert-manager is not installed", "name", namespacedName.Name)
+		}
+	case errors.IsNotFound(err):
+		if requested != nil {
+			resources.Create(fsm.customResource, namespacedName, client, scheme, requested)
+		}
+	case err != nil:
+		log.Error(err, "Failed to get certificate", "name", namespacedName.Name)
+	case requested == nil:
+		log.Info("Certificate is no longer requested, deleting it", "name", namespacedName.Name)
+		resources.Delete(namespacedName, client, deployed)
+	default:
+		changed := false
+		for _, field := range certificateSpecFields {
+			requestedValue, requestedFound, _ := unstructured.NestedFieldCopy(requested.Object, "spec", field)
+			deployedValue, deployedFound, _ := unstructured.NestedFieldCopy(deployed.Object, "spec", field)
+			if requestedFound == deployedFound && equality.Semantic.DeepEqual(requestedValue, deployedValue) {
+				continue
+			}
+			changed = true
+			if requestedFound {
+				unstructured.SetNestedField(deployed.Object, requestedValue, "spec", field)
+			} else {
+				unstructured.RemoveNestedField(deployed.Object, "spec", field)
+			}
+		}
+		if changed {
+			log.Info("Certificate changed, updating it", "name", namespacedName.Name)
+			resources.Update(namespacedName, client, deployed)
+		}
+	}
+}
+
+// NewCertificateForCR returns the cert-manager certificate of an acceptor, connector or the
+// console, issued into its ssl secret
+func NewCertificateForCR(fsm *ActiveMQArtemisFSM, itemName string, secretName string, certManager *brokerv2alpha5.CertManagerType) *unstructured.Unstructured {
+
+	issuerKind := certManager.IssuerRef.Kind
+	if issuerKind == "" {
+		issuerKind = "Issuer"
+	}
+	issuerGroup := certManager.IssuerRef.Group
+	if issuerGroup == "" {
+		issuerGroup = certificateGVK.Group
+	}
+
+	dnsNames := certManager.DNSNames
+	if len(dnsNames) == 0 {
+		headless := fsm.GetHeadlessServiceName() + "." + fsm.customResource.Namespace
+		dnsNames = []string{"*." + headless + ".svc", "*." + headless + ".svc.cluster.local"}
+	}
+	var dnsNameValues []interface{}
+	for _, dnsName := range dnsNames {
+		dnsNameValues = append(dnsNameValues, dnsName)
+	}
+
+	spec := map[string]interface{}{
+		"secretName": secretName,
+		"issuerRef": map[string]interface{}{
+			"name":  certManager.IssuerRef.Name,
+			"kind":  issuerKind,
+			"group": issuerGroup,
+		},
+		"dnsNames": dnsNameValues,
+		// The labels let the issued secret through the watch of the operator
+		"secretTemplate": map[string]interface{}{
+			"labels": map[string]interface{}{
+				selectors.LabelResourceKey: fsm.customResource.Name,
+			},
+		},
+	}
+	if certManager.Duration != "" {
+		spec["duration"] = certManager.Duration
+	}
+	if certManager.RenewBefore != "" {
+		spec["renewBefore"] = certManager.RenewBefore
+	}
+
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	certificate.SetGroupVersionKind(certificateGVK)
+	certificate.SetName(certificateName(fsm, itemName))
+	certificate.SetNamespace(fsm.customResource.Namespace)
+	certificate.SetLabels(fsm.namers.LabelBuilder.Labels())
+	return certificate
+}
+
+func certificateName(fsm *ActiveMQArtemisFSM, itemName string) string {
+	return fsm.customResource.Name + "-" + itemName + "-cert"
+}
+
+// syncKeyStores converts the pem certificate and key of an ssl secret to the PKCS#12 key
+// and trust stores of a secret the custom resource owns, converting them again with a new
+// password once the certificate is renewed. The ssl secret itself is left as it is.
+func syncKeyStores(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, itemName string, sslEnabled bool, secretName string) {
+
+	storesNamespacedName := types.NamespacedName{
+		Name:      storesSecretName(fsm.customResource, itemName),
+		Namespace: fsm.customResource.Namespace,
+	}
+	stores := secrets.NewSecret(storesNamespacedName, storesNamespacedName.Name, map[string]string{}, fsm.namers.LabelBuilder.Labels())
+
+	secret := &corev1.Secret{}
+	if sslEnabled {
+		namespacedName := types.NamespacedName{
+			Name:      secretName,
+			Namespace: fsm.customResource.Namespace,
+		}
+		if err := client.Get(context.TODO(), namespacedName, secret); err != nil {
+			if !errors.IsNotFound(err) {
+				log.Error(err, "Failed to get ssl secret", "name", secretName)
+				return
+			}
+		}
+	}
+	if !isPEMSecret(secret) {
+		resources.Disable(fsm.customResource, client, scheme, storesNamespacedName, stores)
+		return
+	}
+
+	err := client.Get(context.TODO(), storesNamespacedName, stores)
+	if err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get stores secret", "name", storesNamespacedName.Name)
+		return
+	}
+	found := err == nil
+
+	source := pemSourceDigest(secret)
+	if found && stores.Annotations[storesSourceAnnotation] == source && len(stores.Data[keyStoreFile]) > 0 && len(stores.Data[trustStoreFile]) > 0 && len(stores.Data[storesPasswordKey]) > 0 {
+		return
+	}
+
+	password := random.GenerateRandomString(32)
+	keyStore, trustStore, err := newKeyStores(secret, password)
+	if err != nil {
+		log.Error(err, "Failed to convert the pem certificate of the ssl secret", "name", secretName)
+		return
+	}
+	log.Info("Converting the pem certificate of the ssl secret", "name", secretName, "stores", storesNamespacedName.Name)
+	if stores.Data == nil {
+		stores.Data = make(map[string][]byte)
+	}
+	stores.Data[keyStoreFile] = keyStore
+	stores.Data[trustStoreFile] = trustStore
+	stores.Data[storesPasswordKey] = []byte(password)
+	if stores.Annotations == nil {
+		stores.Annotations = make(map[string]string)
+	}
+	stores.Annotations[storesSourceAnnotation] = source
+
+	if !found {
+		err = resources.Create(fsm.customResource, storesNamespacedName, client, scheme, stores)
+	} else {
+		err = resources.Update(storesNamespacedName, client, stores)
+	}
+	if err != nil {
+		log.Error(err, "Failed to save the stores secret", "name", storesNamespacedName.Name)
+	}
+}
+
+// convertedStores returns the password of the stores converted from the pem certificate of
+// an acceptor, connector or the console, and whether they were
+func convertedStores(fsm *ActiveMQArtemisFSM, client client.Client, itemName string) (string, bool) {
+	namespacedName := types.NamespacedName{
+		Name:      storesSecretName(fsm.customResource, itemName),
+		Namespace: fsm.customResource.Namespace,
+	}
+	stores := &corev1.Secret{}
+	if err := client.Get(context.TODO(), namespacedName, stores); err != nil {
+		return "", false
+	}
+	password := string(stores.Data[storesPasswordKey])
+	return password, password != ""
+}
+
+// isPEMSecret tells whether the ssl secret holds a pem certificate and key, as secrets of
+// type kubernetes.io/tls do, rather than java stores
+func isPEMSecret(secret *corev1.Secret) bool {
+	return len(secret.Data[corev1.TLSCertKey]) > 0 && len(secret.Data[corev1.TLSPrivateKeyKey]) > 0
+}
+
+// pemSourceDigest returns the digest of the pem certificate, key and ca of an ssl secret,
+// telling when the stores are to be converted again
+func pemSourceDigest(secret *corev1.Secret) string {
+	digest := sha256.New()
+	digest.Write(secret.Data[corev1.TLSCertKey])
+	digest.Write(secret.Data[corev1.TLSPrivateKeyKey])
+	digest.Write(secret.Data[corev1.ServiceAccountRootCAKey])
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+// newKeyStores returns the key store of the certificate and key of an ssl secret, and the
+// trust store of its ca certificates, the chain of the certificate when it has none
+func newKeyStores(secret *corev1.Secret, password string) ([]byte, []byte, error) {
+
+	chain, err := certificates.ParseCertificates(secret.Data[corev1.TLSCertKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(chain) == 0 {
+		return nil, nil, fmt.Errorf("no certificate in %s", corev1.TLSCertKey)
+	}
+	key, err := certificates.ParseKey(secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %v", corev1.TLSPrivateKeyKey, err)
+	}
+	cas, err := certificates.ParseCertificates(secret.Data[corev1.ServiceAccountRootCAKey])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	keyStore, err := pkcs12.Encode(rand.Reader, key, chain[0], chain[1:], password)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	trusted := cas
+	if len(trusted) == 0 && len(chain) > 1 {
+		trusted = chain[1:]
+	} else if len(trusted) == 0 {
+		trusted = chain
+	}
+	trustStore, err := pkcs12.EncodeTrustStore(rand.Reader, trusted, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	return keyStore, trustStore, nil
+}
@@ -0,0 +1,213 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"time"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/certificates"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+// newPEMSecret returns an ssl secret holding a pem certificate and key issued by a new ca
+func newPEMSecret(name string) *corev1.Secret {
+	ca, caKey, err := certificates.NewCA("ex-aao-ca", time.Now(), time.Hour)
+	Expect(err).NotTo(HaveOccurred())
+	certificate, key, err := certificates.NewCertificate(ca, caKey, "ex-aao", []string{"ex-aao.test.svc"}, time.Now(), time.Hour)
+	Expect(err).NotTo(HaveOccurred())
+	keyPem, err := certificates.EncodeKey(key)
+	Expect(err).NotTo(HaveOccurred())
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+		Type:       corev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			corev1.TLSCertKey:              certificates.EncodeCertificates(certificate),
+			corev1.TLSPrivateKeyKey:        keyPem,
+			corev1.ServiceAccountRootCAKey: certificates.EncodeCertificates(ca),
+		},
+	}
+}
+
+var _ = Describe("Certificates", func() {
+
+	var scheme *runtime.Scheme
+	var fsm *ActiveMQArtemisFSM
+
+	storesName := types.NamespacedName{Name: "ex-aao-amqp-stores", Namespace: "test"}
+	sslName := types.NamespacedName{Name: "ex-aao-amqp-secret", Namespace: "test"}
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(brokerv2alpha5.SchemeBuilder.AddToScheme(scheme)).To(Succeed())
+		cr := newTestCR()
+		cr.UID = "ex-aao-uid"
+		cr.Spec.Acceptors = []brokerv2alpha5.AcceptorType{{Name: "amqp", Port: 5672, SSLEnabled: true}}
+		fsm = newTestFSM(cr, nil)
+	})
+
+	deployedStores := func(c client.Client) *corev1.Secret {
+		stores := &corev1.Secret{}
+		Expect(c.Get(context.TODO(), storesName, stores)).To(Succeed())
+		return stores
+	}
+
+	Context("converting a pem ssl secret", func() {
+
+		It("keeps the stores in a secret the custom resource owns", func() {
+			ssl := newPEMSecret(sslName.Name)
+			c := fake.NewFakeClientWithScheme(scheme, ssl.DeepCopy())
+
+			syncKeyStores(fsm, c, scheme, "amqp", true, sslName.Name)
+
+			stores := deployedStores(c)
+			Expect(stores.OwnerReferences).To(HaveLen(1))
+			Expect(stores.OwnerReferences[0].Name).To(Equal("ex-aao"))
+			Expect(*stores.OwnerReferences[0].Controller).To(BeTrue())
+
+			password := string(stores.Data[storesPasswordKey])
+			Expect(password).To(HaveLen(32))
+			_, certificate, err := pkcs12.Decode(stores.Data[keyStoreFile], password)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(certificates.EncodeCertificates(certificate)).To(Equal(ssl.Data[corev1.TLSCertKey]))
+			trusted, err := pkcs12.DecodeTrustStore(stores.Data[trustStoreFile], password)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(certificates.EncodeCertificates(trusted...)).To(Equal(ssl.Data[corev1.ServiceAccountRootCAKey]))
+
+			unchanged := &corev1.Secret{}
+			Expect(c.Get(context.TODO(), sslName, unchanged)).To(Succeed())
+			Expect(unchanged.Data).To(Equal(ssl.Data))
+			Expect(unchanged.Annotations).To(BeEmpty())
+		})
+
+		It("keeps the stores and their password until the certificate is renewed", func() {
+			c := fake.NewFakeClientWithScheme(scheme, newPEMSecret(sslName.Name))
+
+			syncKeyStores(fsm, c, scheme, "amqp", true, sslName.Name)
+			converted := deployedStores(c)
+
+			syncKeyStores(fsm, c, scheme, "amqp", true, sslName.Name)
+			Expect(deployedStores(c).Data).To(Equal(converted.Data))
+
+			renewed := newPEMSecret(sslName.Name)
+			ssl := &corev1.Secret{}
+			Expect(c.Get(context.TODO(), sslName, ssl)).To(Succeed())
+			ssl.Data = renewed.Data
+			Expect(c.Update(context.TODO(), ssl)).To(Succeed())
+
+			syncKeyStores(fsm, c, scheme, "amqp", true, sslName.Name)
+			reconverted := deployedStores(c)
+			Expect(reconverted.Data[storesPasswordKey]).NotTo(Equal(converted.Data[storesPasswordKey]))
+			_, certificate, err := pkcs12.Decode(reconverted.Data[keyStoreFile], string(reconverted.Data[storesPasswordKey]))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(certificates.EncodeCertificates(certificate)).To(Equal(renewed.Data[corev1.TLSCertKey]))
+		})
+
+		It("deletes the stores once ssl is disabled or the secret holds java stores", func() {
+			c := fake.NewFakeClientWithScheme(scheme, newPEMSecret(sslName.Name))
+			syncKeyStores(fsm, c, scheme, "amqp", true, sslName.Name)
+			deployedStores(c)
+
+			syncKeyStores(fsm, c, scheme, "amqp", false, sslName.Name)
+			Expect(errors.IsNotFound(c.Get(context.TODO(), storesName, &corev1.Secret{}))).To(BeTrue())
+
+			jks := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "ex-aao-jks-secret", Namespace: "test"},
+				Data:       map[string][]byte{"broker.ks": []byte("jks"), "client.ts": []byte("jks")},
+			}
+			Expect(c.Create(context.TODO(), jks)).To(Succeed())
+			syncKeyStores(fsm, c, scheme, "amqp", true, jks.Name)
+			Expect(errors.IsNotFound(c.Get(context.TODO(), storesName, &corev1.Secret{}))).To(BeTrue())
+		})
+	})
+
+	Context("configuring the brokers", func() {
+
+		It("points the acceptors at the converted stores by their type", func() {
+			c := fake.NewFakeClientWithScheme(scheme, newPEMSecret(sslName.Name))
+			syncKeyStores(fsm, c, scheme, "amqp", true, sslName.Name)
+			password := string(deployedStores(c).Data[storesPasswordKey])
+
+			arguments := generateAcceptorConnectorSSLArguments(fsm, c, "amqp", sslName.Name)
+
+			Expect(arguments).To(ContainSubstring("keyStorePath=\\/etc\\/ex-aao-amqp-stores-volume\\/keystore.p12"))
+			Expect(arguments).To(ContainSubstring("trustStorePath=\\/etc\\/ex-aao-amqp-stores-volume\\/truststore.p12"))
+			Expect(arguments).To(ContainSubstring("keyStorePassword=" + password))
+			Expect(arguments).To(ContainSubstring("keyStoreType=PKCS12;trustStoreType=PKCS12"))
+			Expect(arguments).NotTo(ContainSubstring("Provider"))
+		})
+
+		It("points the console at the converted stores by their type", func() {
+			consoleSSL := newPEMSecret("ex-aao-console-secret")
+			c := fake.NewFakeClientWithScheme(scheme, consoleSSL)
+			syncKeyStores(fsm, c, scheme, "console", true, consoleSSL.Name)
+
+			flags := generateConsoleSSLFlags(fsm, c, consoleSSL.Name)
+
+			Expect(flags).To(ContainSubstring("--ssl-key /etc/ex-aao-console-stores-volume/keystore.p12"))
+			Expect(flags).To(ContainSubstring("--ssl-trust /etc/ex-aao-console-stores-volume/truststore.p12"))
+			Expect(flags).To(ContainSubstring("--ssl-key-type PKCS12 --ssl-trust-type PKCS12"))
+		})
+
+		It("keeps the java stores of the ssl secret without a type", func() {
+			c := fake.NewFakeClientWithScheme(scheme)
+
+			arguments := generateAcceptorConnectorSSLArguments(fsm, c, "amqp", sslName.Name)
+
+			Expect(arguments).To(ContainSubstring("keyStorePath=\\/etc\\/ex-aao-amqp-secret-volume\\/broker.ks"))
+			Expect(arguments).NotTo(ContainSubstring("StoreType"))
+		})
+
+		It("mounts the stores secret of each ssl acceptor, which may not exist yet", func() {
+			podVolumes := MakeVolumes(fsm)
+			Expect(podVolumes).To(ContainElement(corev1.Volume{
+				Name: "ex-aao-amqp-stores-volume",
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+					SecretName: "ex-aao-amqp-stores",
+					Optional:   boolPtr(true),
+				}},
+			}))
+			Expect(MakeVolumeMounts(fsm)).To(ContainElement(corev1.VolumeMount{
+				Name:      "ex-aao-amqp-stores-volume",
+				ReadOnly:  true,
+				MountPath: "/etc/ex-aao-amqp-stores-volume",
+			}))
+		})
+	})
+
+	It("labels the secret cert-manager issues with the broker", func() {
+		certManager := &brokerv2alpha5.CertManagerType{IssuerRef: brokerv2alpha5.IssuerReference{Name: "ca-issuer"}}
+
+		certificate := NewCertificateForCR(fsm, "amqp", sslName.Name, certManager)
+
+		labels, found, err := unstructured.NestedStringMap(certificate.Object, "spec", "secretTemplate", "labels")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(labels).To(Equal(map[string]string{"ActiveMQArtemis": "ex-aao"}))
+	})
+
+	It("only watches the secrets labelled with their broker", func() {
+		predicate := sslSecretPredicate()
+		labelled := newPEMSecret(sslName.Name)
+		labelled.Labels = map[string]string{"ActiveMQArtemis": "ex-aao"}
+		unlabelled := newPEMSecret("other")
+
+		Expect(predicate.Create(event.CreateEvent{Meta: labelled, Object: labelled})).To(BeTrue())
+		Expect(predicate.Update(event.UpdateEvent{MetaOld: labelled, ObjectOld: labelled, MetaNew: labelled, ObjectNew: labelled})).To(BeTrue())
+		Expect(predicate.Create(event.CreateEvent{Meta: unlabelled, Object: unlabelled})).To(BeFalse())
+		Expect(predicate.Update(event.UpdateEvent{MetaOld: unlabelled, ObjectOld: unlabelled, MetaNew: unlabelled, ObjectNew: unlabelled})).To(BeFalse())
+		Expect(predicate.Delete(event.DeleteEvent{Meta: unlabelled, Object: unlabelled})).To(BeFalse())
+	})
+})
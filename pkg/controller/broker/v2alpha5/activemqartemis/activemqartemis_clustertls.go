@@ -0,0 +1,331 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"fmt"
+	"strconv"
+	"time"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/metrics"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/environments"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/volumes"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/certificates"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/random"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+const (
+	clusterTLSCAValidity          = 5 * 365 * 24 * time.Hour
+	clusterTLSCertificateValidity = 90 * 24 * time.Hour
+
+	// The keys of the cluster ca secret, only the operator reads it
+	clusterTLSCACert     = "ca.crt"
+	clusterTLSCAKey      = "ca.key"
+	clusterTLSNextCACert = "next-ca.crt"
+	clusterTLSNextCAKey  = "next-ca.key"
+
+	// The key of the password of the cluster tls secret, mounted in the brokers along with
+	// the trust store and the key store of each pod, <pod>.p12
+	clusterTLSPassword = "password"
+
+	// The init container reads the password of the stores from this env var
+	clusterTLSPasswordEnvVar = "AMQ_CLUSTER_TLS_PASSWORD"
+)
+
+func isClusterTLSEnabled(customResource *brokerv2alpha5.ActiveMQArtemis) bool {
+	return customResource.Spec.DeploymentPlan.ClusterTLS && isClustered(customResource)
+}
+
+// ProcessClusterTLS issues the certificates of the brokers of a clustered deployment with
+// cluster tls enabled, and rotates them before they expire. The brokers only read their
+// stores on start, they are restarted once the certificates are rotated. The custom
+// resources are resynced every few hours, well within the lifetime of the certificates.
+func (reconciler *ActiveMQArtemisReconciler) ProcessClusterTLS(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) uint32 {
+
+	caName := types.NamespacedName{
+		Name:      fsm.GetClusterCASecretName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	storesName := types.NamespacedName{
+		Name:      fsm.GetClusterTLSSecretName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	caSecret := secrets.NewSecret(caName, caName.Name, map[string]string{}, fsm.namers.LabelBuilder.Labels())
+	storesSecret := secrets.NewSecret(storesName, storesName.Name, map[string]string{}, fsm.namers.LabelBuilder.Labels())
+
+	if !isClusterTLSEnabled(fsm.customResource) {
+		resources.Disable(fsm.customResource, client, scheme, storesName, storesSecret)
+		resources.Disable(fsm.customResource, client, scheme, caName, caSecret)
+		return statefulSetNotUpdated
+	}
+
+	caFound, err := getClusterTLSSecret(client, caName, caSecret)
+	if err != nil {
+		log.Error(err, "Failed to get cluster ca secret", "name", caName.Name)
+		return statefulSetNotUpdated
+	}
+	storesFound, err := getClusterTLSSecret(client, storesName, storesSecret)
+	if err != nil {
+		log.Error(err, "Failed to get cluster tls secret", "name", storesName.Name)
+		return statefulSetNotUpdated
+	}
+
+	changed, rotated, err := issueClusterCertificates(fsm, caSecret.Data, storesSecret.Data, time.Now())
+	if err != nil {
+		log.Error(err, "Failed to issue the cluster certificates", "name", storesName.Name)
+		return statefulSetNotUpdated
+	}
+	if !changed {
+		return statefulSetNotUpdated
+	}
+
+	// The ca is saved first, stores it did not sign or trust are reissued if saving them fails
+	if err = saveClusterTLSSecret(fsm, client, scheme, caName, caSecret, caFound); err != nil {
+		log.Error(err, "Failed to save cluster ca secret", "name", caName.Name)
+		return statefulSetNotUpdated
+	}
+	if err = saveClusterTLSSecret(fsm, client, scheme, storesName, storesSecret, storesFound); err != nil {
+		log.Error(err, "Failed to save cluster tls secret", "name", storesName.Name)
+		return statefulSetNotUpdated
+	}
+	// Brokers yet to be deployed read the stores as they are
+	if !rotated || currentStatefulSet.ResourceVersion == "" {
+		return statefulSetNotUpdated
+	}
+
+	log.Info("Cluster certificates rotated, restarting the brokers", "name", storesName.Name)
+	recordEvent(fsm, fsm.customResource, corev1.EventTypeNormal, EventReasonClusterCertificatesRotated, "Rotated the cluster certificates in secret %s", storesName.Name)
+	environments.IncrementTriggeredRollCount(currentStatefulSet.Spec.Template.Spec.Containers)
+	metrics.StatefulSetRollTriggered(fsm.customResource.Namespace, fsm.customResource.Name)
+	return statefulSetClusterConfigUpdated
+}
+
+func getClusterTLSSecret(client client.Client, namespacedName types.NamespacedName, secret *corev1.Secret) (bool, error) {
+	err := client.Get(context.TODO(), namespacedName, secret)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	if secret.Data == nil {
+		secret.Data = make(map[string][]byte)
+	}
+	return err == nil, nil
+}
+
+func saveClusterTLSSecret(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, namespacedName types.NamespacedName, secret *corev1.Secret, found bool) error {
+	if found {
+		return resources.Update(namespacedName, client, secret)
+	}
+	log.Info("Creating cluster tls secret", "name", namespacedName.Name)
+	return resources.Create(fsm.customResource, namespacedName, client, scheme, secret)
+}
+
+// issueClusterCertificates issues the missing and expiring certificates of the brokers,
+// signed by the ca of the ca data, into the stores data. It tells whether the data changed,
+// and whether the stores of the running brokers were replaced, with a new password.
+//
+// A new ca is added to the trust stores of the brokers a rotation before it signs their
+// certificates, so that the brokers restarted first still trust the ones restarted last.
+func issueClusterCertificates(fsm *ActiveMQArtemisFSM, caData map[string][]byte, storesData map[string][]byte, now time.Time) (bool, bool, error) {
+
+	var err error
+	reissue := false
+	password := string(storesData[clusterTLSPassword])
+
+	ca, caKey := parseClusterCA(caData[clusterTLSCACert], caData[clusterTLSCAKey])
+	nextCA, nextCAKey := parseClusterCA(caData[clusterTLSNextCACert], caData[clusterTLSNextCAKey])
+	trusted, _ := pkcs12.DecodeTrustStore(storesData[trustStoreFile], password)
+
+	if ca == nil || now.After(ca.NotAfter) {
+		if ca, caKey, err = certificates.NewCA(fsm.customResource.Name+"-cluster-ca", now, clusterTLSCAValidity); err != nil {
+			return false, false, err
+		}
+		nextCA, nextCAKey, trusted = nil, nil, nil
+		reissue = true
+	} else if nextCA == nil && certificates.NeedsRenewal(ca, now) {
+		if nextCA, nextCAKey, err = certificates.NewCA(fsm.customResource.Name+"-cluster-ca", now, clusterTLSCAValidity); err != nil {
+			return false, false, err
+		}
+		reissue = true
+	} else {
+		nextTrusted := nextCA != nil && containsCertificate(trusted, nextCA)
+		reissue = !containsCertificate(trusted, ca) || (nextCA != nil && !nextTrusted)
+		for i := int32(0); i < fsm.customResource.Spec.DeploymentPlan.Size; i++ {
+			keyStore := storesData[fsm.GetStatefulSetName()+"-"+strconv.Itoa(int(i))+".p12"]
+			if len(keyStore) == 0 {
+				continue
+			}
+			_, certificate, _, err := pkcs12.DecodeChain(keyStore, password)
+			if err != nil || certificates.NeedsRenewal(certificate, now) || certificate.CheckSignatureFrom(ca) != nil {
+				reissue = true
+			}
+		}
+		if reissue && nextTrusted {
+			// The next ca was trusted by all the brokers the previous rotation
+			ca, caKey, nextCA, nextCAKey = nextCA, nextCAKey, nil, nil
+		}
+	}
+
+	if reissue || password == "" {
+		reissue = true
+		password = random.GenerateRandomString(32)
+		for key := range storesData {
+			delete(storesData, key)
+		}
+
+		stillTrusted := []*x509.Certificate{ca}
+		if nextCA != nil {
+			stillTrusted = append(stillTrusted, nextCA)
+		}
+		for _, certificate := range trusted {
+			if now.Before(certificate.NotAfter) && !containsCertificate(stillTrusted, certificate) {
+				stillTrusted = append(stillTrusted, certificate)
+			}
+		}
+		trustStore, err := pkcs12.EncodeTrustStore(rand.Reader, stillTrusted, password)
+		if err != nil {
+			return false, false, err
+		}
+
+		caKeyPem, err := certificates.EncodeKey(caKey)
+		if err != nil {
+			return false, false, err
+		}
+		caData[clusterTLSCACert] = certificates.EncodeCertificates(ca)
+		caData[clusterTLSCAKey] = caKeyPem
+		delete(caData, clusterTLSNextCACert)
+		delete(caData, clusterTLSNextCAKey)
+		if nextCA != nil {
+			nextCAKeyPem, err := certificates.EncodeKey(nextCAKey)
+			if err != nil {
+				return false, false, err
+			}
+			caData[clusterTLSNextCACert] = certificates.EncodeCertificates(nextCA)
+			caData[clusterTLSNextCAKey] = nextCAKeyPem
+		}
+		storesData[trustStoreFile] = trustStore
+		storesData[clusterTLSPassword] = []byte(password)
+	}
+
+	issued := false
+	for i := int32(0); i < fsm.customResource.Spec.DeploymentPlan.Size; i++ {
+		podName := fsm.GetStatefulSetName() + "-" + strconv.Itoa(int(i))
+		if len(storesData[podName+".p12"]) > 0 {
+			continue
+		}
+		certificate, key, err := certificates.NewCertificate(ca, caKey, podName, clusterTLSDNSNames(fsm, podName), now, clusterTLSCertificateValidity)
+		if err != nil {
+			return false, false, err
+		}
+		keyStore, err := pkcs12.Encode(rand.Reader, key, certificate, []*x509.Certificate{ca}, password)
+		if err != nil {
+			return false, false, err
+		}
+		storesData[podName+".p12"] = keyStore
+		issued = true
+	}
+
+	return reissue || issued, reissue, nil
+}
+
+func containsCertificate(cas []*x509.Certificate, certificate *x509.Certificate) bool {
+	for _, ca := range cas {
+		if ca.Equal(certificate) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseClusterCA(certData []byte, keyData []byte) (*x509.Certificate, crypto.Signer) {
+	if len(certData) == 0 || len(keyData) == 0 {
+		return nil, nil
+	}
+	cas, err := certificates.ParseCertificates(certData)
+	if err != nil || len(cas) == 0 {
+		log.Info("Ignoring the invalid cluster ca certificate")
+		return nil, nil
+	}
+	key, err := certificates.ParseKey(keyData)
+	if err != nil {
+		log.Info("Ignoring the invalid cluster ca key")
+		return nil, nil
+	}
+	return cas[0], key
+}
+
+// clusterTLSDNSNames returns the names of a broker pod in the headless service
+func clusterTLSDNSNames(fsm *ActiveMQArtemisFSM, podName string) []string {
+	host := podName + "." + fsm.GetHeadlessServiceName()
+	return []string{
+		host,
+		host + "." + fsm.customResource.Namespace,
+		host + "." + fsm.customResource.Namespace + ".svc",
+		host + "." + fsm.customResource.Namespace + ".svc.cluster.local",
+	}
+}
+
+// clusterTLSConfigCmds returns the init commands adding the cluster tls acceptor to the
+// broker configuration and pointing the cluster connector at it, the other acceptors keep
+// serving the clients. The stores of the pod are copied
+// along with the configuration, so that a restarted broker container reads the ones its
+// configuration has the password of, rather than the ones rotated since.
+func clusterTLSConfigCmds(fsm *ActiveMQArtemisFSM) []string {
+	if !isClusterTLSEnabled(fsm.customResource) {
+		return nil
+	}
+
+	secretPath := "/etc/" + fsm.GetClusterTLSSecretName() + "-volume"
+	storesPath := "$CONFIG_INSTANCE_DIR/cluster-tls"
+	storeArgs := "sslEnabled=true" +
+		";keyStoreType=" + storeType + ";keyStorePath=" + storesPath + "/" + keyStoreFile + ";keyStorePassword=${" + clusterTLSPasswordEnvVar + "}" +
+		";trustStoreType=" + storeType + ";trustStorePath=" + storesPath + "/" + trustStoreFile + ";trustStorePassword=${" + clusterTLSPasswordEnvVar + "}"
+	port := fmt.Sprintf("%d", brokerv2alpha5.ClusterTLSAcceptorPort)
+	host := "${HOSTNAME}." + fsm.GetHeadlessServiceName() + "." + fsm.customResource.Namespace + ".svc"
+
+	copyCmd := "mkdir -p " + storesPath +
+		" && cp " + secretPath + "/${HOSTNAME}.p12 " + storesPath + "/" + keyStoreFile +
+		" && cp " + secretPath + "/" + trustStoreFile + " " + storesPath + "/" + trustStoreFile
+	acceptor := "<acceptor name=\\\"cluster\\\">tcp://0.0.0.0:" + port + "?protocols=CORE;needClientAuth=true;" + storeArgs + "</acceptor>"
+	connector := "<connector name=\\\"artemis\\\">tcp://" + host + ":" + port + "?verifyHost=true;" + storeArgs + "</connector>"
+	configCmd := "for f in $(find $CONFIG_INSTANCE_DIR -name broker.xml 2>/dev/null); do sed -i" +
+		" -e \"s#</acceptors>#" + acceptor + "</acceptors>#\"" +
+		" -e \"s#<connector name=\\\"artemis\\\">[^<]*</connector>#" + connector + "#\"" +
+		" \"$f\"; done"
+
+	return []string{copyCmd, configCmd}
+}
+
+// configClusterTLS gives the init container the cluster tls secret, holding nothing but the
+// stores of the brokers and their password, the keys of the ca never leave the operator
+func configClusterTLS(fsm *ActiveMQArtemisFSM, podSpec *corev1.PodSpec) {
+	if !isClusterTLSEnabled(fsm.customResource) {
+		return
+	}
+
+	secretName := fsm.GetClusterTLSSecretName()
+	podSpec.Volumes = append(podSpec.Volumes, volumes.MakeVolume(secretName))
+	podSpec.InitContainers[0].VolumeMounts = append(podSpec.InitContainers[0].VolumeMounts, volumes.MakeVolumeMount(secretName+"-volume"))
+
+	passwordEnvVar := corev1.EnvVar{
+		Name: clusterTLSPasswordEnvVar,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  clusterTLSPassword,
+			},
+		},
+	}
+	environments.Create(podSpec.InitContainers, &passwordEnvVar)
+}
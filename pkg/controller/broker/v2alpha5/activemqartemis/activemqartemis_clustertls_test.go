@@ -0,0 +1,251 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"crypto/x509"
+	"strconv"
+	"time"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/certificates"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	pkcs12 "software.sslmate.com/src/go-pkcs12"
+)
+
+var _ = Describe("Cluster TLS", func() {
+
+	const day = 24 * time.Hour
+	const year = 365 * day
+	// The clock advances a resync at a time, well within the margins of the renewals
+	const resync = 5 * day
+
+	var fsm *ActiveMQArtemisFSM
+
+	BeforeEach(func() {
+		cr := newTestCR()
+		cr.UID = "ex-aao-uid"
+		cr.Spec.DeploymentPlan.Size = 2
+		cr.Spec.DeploymentPlan.ClusterTLS = true
+		fsm = newTestFSM(cr, nil)
+	})
+
+	// decodeStores returns the certificates of the key stores of the brokers, by pod, and the
+	// cas of their trust store, checking the stores hold nothing else
+	decodeStores := func(storesData map[string][]byte) (map[string]*x509.Certificate, []*x509.Certificate) {
+		password := string(storesData[clusterTLSPassword])
+		Expect(password).NotTo(BeEmpty())
+		Expect(storesData).To(HaveLen(int(fsm.customResource.Spec.DeploymentPlan.Size) + 2))
+
+		trusted, err := pkcs12.DecodeTrustStore(storesData[trustStoreFile], password)
+		Expect(err).NotTo(HaveOccurred())
+		brokers := map[string]*x509.Certificate{}
+		for i := 0; i < int(fsm.customResource.Spec.DeploymentPlan.Size); i++ {
+			podName := fsm.GetStatefulSetName() + "-" + strconv.Itoa(i)
+			_, certificate, _, err := pkcs12.DecodeChain(storesData[podName+".p12"], password)
+			Expect(err).NotTo(HaveOccurred())
+			brokers[podName] = certificate
+		}
+		return brokers, trusted
+	}
+
+	verifyBroker := func(podName string, certificate *x509.Certificate, trusted []*x509.Certificate, now time.Time) error {
+		roots := x509.NewCertPool()
+		for _, ca := range trusted {
+			roots.AddCert(ca)
+		}
+		_, err := certificate.Verify(x509.VerifyOptions{
+			DNSName:     clusterTLSDNSNames(fsm, podName)[2],
+			Roots:       roots,
+			CurrentTime: now,
+			KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		})
+		return err
+	}
+
+	table.DescribeTable("issueClusterCertificates keeps the brokers trusting each other as the clock advances",
+		func(horizon time.Duration, signingCAs int, nextCAPending bool) {
+			caData := map[string][]byte{}
+			storesData := map[string][]byte{}
+			start := time.Now()
+
+			changed, rotated, err := issueClusterCertificates(fsm, caData, storesData, start)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(BeTrue())
+			Expect(rotated).To(BeTrue())
+			brokers, trusted := decodeStores(storesData)
+
+			signers := map[string]bool{}
+			for now := start.Add(resync); !now.After(start.Add(horizon)); now = now.Add(resync) {
+				changed, rotated, err = issueClusterCertificates(fsm, caData, storesData, now)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(changed).To(Equal(rotated))
+				nextBrokers, nextTrusted := brokers, trusted
+				if changed {
+					nextBrokers, nextTrusted = decodeStores(storesData)
+				}
+
+				cas, err := certificates.ParseCertificates(caData[clusterTLSCACert])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(containsCertificate(nextTrusted, cas[0])).To(BeTrue(), "the ca is trusted on day %v", int(now.Sub(start)/day))
+				if nextCAs, _ := certificates.ParseCertificates(caData[clusterTLSNextCACert]); len(nextCAs) > 0 {
+					Expect(containsCertificate(nextTrusted, nextCAs[0])).To(BeTrue(), "the next ca is trusted on day %v", int(now.Sub(start)/day))
+				}
+				for podName, certificate := range nextBrokers {
+					Expect(certificate.CheckSignatureFrom(cas[0])).To(Succeed(), "%s is signed by the ca on day %v", podName, int(now.Sub(start)/day))
+					Expect(verifyBroker(podName, certificate, nextTrusted, now)).To(Succeed(), "%s chains to the trust store on day %v", podName, int(now.Sub(start)/day))
+					signers[string(certificate.AuthorityKeyId)] = true
+				}
+
+				if rotated {
+					// The brokers restarted first and the ones restarted last trust each other
+					for podName, certificate := range nextBrokers {
+						Expect(verifyBroker(podName, certificate, trusted, now)).To(Succeed(), "the restarted %s is trusted on day %v", podName, int(now.Sub(start)/day))
+					}
+					for podName, certificate := range brokers {
+						Expect(verifyBroker(podName, certificate, nextTrusted, now)).To(Succeed(), "the running %s is trusted on day %v", podName, int(now.Sub(start)/day))
+					}
+				}
+				brokers, trusted = nextBrokers, nextTrusted
+			}
+
+			Expect(signers).To(HaveLen(signingCAs))
+			Expect(caData).To(HaveKey(clusterTLSCAKey))
+			if nextCAPending {
+				Expect(caData).To(HaveKey(clusterTLSNextCAKey))
+			} else {
+				Expect(caData).NotTo(HaveKey(clusterTLSNextCAKey))
+			}
+		},
+		table.Entry("across the renewal of the broker certificates at 60 days", 60*day, 1, false),
+		table.Entry("up to 3.3 years, before the renewal of the ca", 33*year/10, 1, false),
+		table.Entry("across the renewal of the ca at 3.4 years", 34*year/10, 1, true),
+		table.Entry("across the expiry of the first ca at 5 years", 5*year, 2, false),
+	)
+
+	It("keeps the stores of the brokers as they are until they need renewal", func() {
+		caData := map[string][]byte{}
+		storesData := map[string][]byte{}
+		now := time.Now()
+		_, _, err := issueClusterCertificates(fsm, caData, storesData, now)
+		Expect(err).NotTo(HaveOccurred())
+		password := string(storesData[clusterTLSPassword])
+
+		changed, rotated, err := issueClusterCertificates(fsm, caData, storesData, now.Add(day))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(rotated).To(BeFalse())
+		Expect(string(storesData[clusterTLSPassword])).To(Equal(password))
+	})
+
+	It("issues the stores of added brokers without rotating the others", func() {
+		caData := map[string][]byte{}
+		storesData := map[string][]byte{}
+		now := time.Now()
+		_, _, err := issueClusterCertificates(fsm, caData, storesData, now)
+		Expect(err).NotTo(HaveOccurred())
+		keyStore := storesData[fsm.GetStatefulSetName()+"-0.p12"]
+
+		fsm.customResource.Spec.DeploymentPlan.Size = 3
+		changed, rotated, err := issueClusterCertificates(fsm, caData, storesData, now.Add(day))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(rotated).To(BeFalse())
+		Expect(storesData[fsm.GetStatefulSetName()+"-0.p12"]).To(Equal(keyStore))
+		decodeStores(storesData)
+	})
+
+	It("trusts the next ca again if the stores trusting it were lost", func() {
+		caData := map[string][]byte{}
+		storesData := map[string][]byte{}
+		now := time.Now()
+		_, _, err := issueClusterCertificates(fsm, caData, storesData, now)
+		Expect(err).NotTo(HaveOccurred())
+		lostStores := map[string][]byte{}
+		for key, value := range storesData {
+			lostStores[key] = value
+		}
+
+		renewal := now.Add(34 * year / 10)
+		_, _, err = issueClusterCertificates(fsm, caData, storesData, renewal)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(caData).To(HaveKey(clusterTLSNextCACert))
+		nextCAs, err := certificates.ParseCertificates(caData[clusterTLSNextCACert])
+		Expect(err).NotTo(HaveOccurred())
+
+		// Saving the stores failed after saving the ca
+		changed, rotated, err := issueClusterCertificates(fsm, caData, lostStores, renewal.Add(day))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(rotated).To(BeTrue())
+		_, trusted := decodeStores(lostStores)
+		Expect(containsCertificate(trusted, nextCAs[0])).To(BeTrue())
+		Expect(caData[clusterTLSNextCACert]).To(Equal(certificates.EncodeCertificates(nextCAs[0])))
+	})
+
+	Context("ProcessClusterTLS", func() {
+
+		var scheme *runtime.Scheme
+
+		caName := types.NamespacedName{Name: "ex-aao-cluster-ca-secret", Namespace: "test"}
+		storesName := types.NamespacedName{Name: "ex-aao-cluster-tls-secret", Namespace: "test"}
+
+		BeforeEach(func() {
+			scheme = runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+			Expect(brokerv2alpha5.SchemeBuilder.AddToScheme(scheme)).To(Succeed())
+		})
+
+		It("keeps the keys of the ca out of the secret of the brokers", func() {
+			c := fake.NewFakeClientWithScheme(scheme, fsm.customResource)
+			reconciler := &ActiveMQArtemisReconciler{}
+			Expect(reconciler.ProcessClusterTLS(fsm, c, scheme, &appsv1.StatefulSet{})).To(Equal(uint32(statefulSetNotUpdated)))
+
+			caSecret := &corev1.Secret{}
+			Expect(c.Get(context.TODO(), caName, caSecret)).To(Succeed())
+			Expect(caSecret.Data).To(HaveKey(clusterTLSCAKey))
+			Expect(caSecret.OwnerReferences).To(HaveLen(1))
+
+			storesSecret := &corev1.Secret{}
+			Expect(c.Get(context.TODO(), storesName, storesSecret)).To(Succeed())
+			Expect(storesSecret.OwnerReferences).To(HaveLen(1))
+			keys := []string{}
+			for key := range storesSecret.Data {
+				keys = append(keys, key)
+			}
+			Expect(keys).To(ConsistOf("ex-aao-ss-0.p12", "ex-aao-ss-1.p12", trustStoreFile, clusterTLSPassword))
+		})
+
+		It("mounts the secret of the brokers only", func() {
+			podSpec := &corev1.PodSpec{InitContainers: []corev1.Container{{Name: "ex-aao-container-init"}}}
+			configClusterTLS(fsm, podSpec)
+
+			Expect(podSpec.Volumes).To(HaveLen(1))
+			Expect(podSpec.Volumes[0].Secret.SecretName).To(Equal(storesName.Name))
+			Expect(podSpec.InitContainers[0].Env).To(HaveLen(1))
+			Expect(podSpec.InitContainers[0].Env[0].ValueFrom.SecretKeyRef.Name).To(Equal(storesName.Name))
+		})
+
+		It("deletes both secrets once cluster tls is disabled", func() {
+			c := fake.NewFakeClientWithScheme(scheme, fsm.customResource)
+			reconciler := &ActiveMQArtemisReconciler{}
+			reconciler.ProcessClusterTLS(fsm, c, scheme, &appsv1.StatefulSet{})
+
+			fsm.customResource.Spec.DeploymentPlan.ClusterTLS = false
+			reconciler.ProcessClusterTLS(fsm, c, scheme, &appsv1.StatefulSet{})
+
+			for _, name := range []types.NamespacedName{caName, storesName} {
+				err := c.Get(context.TODO(), name, &corev1.Secret{})
+				Expect(errors.IsNotFound(err)).To(BeTrue(), "%s is deleted", name.Name)
+			}
+		})
+	})
+})
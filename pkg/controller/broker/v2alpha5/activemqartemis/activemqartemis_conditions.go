@@ -0,0 +1,115 @@
+package v2alpha5activemqartemis
+
+import (
+	"fmt"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/fsm"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reasons used for the status conditions
+const (
+	ReasonStatefulSetCreated  = "StatefulSetCreated"
+	ReasonStatefulSetNotFound = "StatefulSetNotFound"
+	ReasonAllBrokersReady     = "AllBrokersReady"
+	ReasonWaitingForBrokers   = "WaitingForBrokers"
+	ReasonScaledToZero        = "ScaledToZero"
+	ReasonRollingUpdate       = "RollingUpdate"
+	ReasonConfigApplied       = "AllBrokersUpdated"
+	ReasonImageChanged        = "ImageChanged"
+	ReasonNoUpgrade           = "NoUpgradeInProgress"
+	ReasonValidationSucceeded = "ValidationSucceeded"
+	ReasonUnsupportedVersion  = "UnsupportedVersion"
+	ReasonInvalidSize         = "InvalidSize"
+	ReasonInvalidPodTemplate  = "InvalidPodTemplate"
+)
+
+// Reasons used for the StorageResized condition
+const (
+	ReasonStorageResized          = "StorageResized"
+	ReasonStorageResizing         = "ResizingStorage"
+	ReasonFileSystemResizePending = "FileSystemResizePending"
+	ReasonStorageResizeFailed     = "StorageResizeFailed"
+)
+
+// GetStateName returns the name of the current state, or an empty string
+// when the machine has not been entered yet
+func (amqbfsm *ActiveMQArtemisFSM) GetStateName() string {
+
+	machine, ok := amqbfsm.m.(*fsm.Machine)
+	if !ok {
+		return ""
+	}
+
+	switch machine.GetIDCurrentState() {
+	case CreatingK8sResourcesID:
+		return CreatingK8sResources
+	case ContainerRunningID:
+		return ContainerRunning
+	case ScalingID:
+		return Scaling
+	}
+
+	return ""
+}
+
+// SetCondition records a condition against the generation of the current custom resource.
+// It is written out together with the rest of the status by UpdateStatus
+func (amqbfsm *ActiveMQArtemisFSM) SetCondition(conditionType brokerv2alpha5.ConditionType, status corev1.ConditionStatus, reason string, message string) {
+	brokerv2alpha5.SetCondition(&amqbfsm.customResource.Status.Conditions, brokerv2alpha5.Condition{
+		Type:               conditionType,
+		Status:             status,
+		ObservedGeneration: amqbfsm.customResource.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+func (amqbfsm *ActiveMQArtemisFSM) updateReadyCondition(currentStatefulSet *appsv1.StatefulSet) {
+
+	size := amqbfsm.customResource.Spec.DeploymentPlan.Size
+	readyReplicas := currentStatefulSet.Status.ReadyReplicas
+	message := fmt.Sprintf("%d/%d broker pods ready", readyReplicas, size)
+
+	if size == 0 {
+		amqbfsm.SetCondition(brokerv2alpha5.ConditionTypeReady, corev1.ConditionFalse, ReasonScaledToZero, message)
+	} else if readyReplicas >= size {
+		amqbfsm.SetCondition(brokerv2alpha5.ConditionTypeReady, corev1.ConditionTrue, ReasonAllBrokersReady, message)
+	} else {
+		amqbfsm.SetCondition(brokerv2alpha5.ConditionTypeReady, corev1.ConditionFalse, ReasonWaitingForBrokers, message)
+	}
+}
+
+func (amqbfsm *ActiveMQArtemisFSM) updateValidCondition() {
+
+	spec := &amqbfsm.customResource.Spec
+
+	if spec.DeploymentPlan.Size < 0 {
+		amqbfsm.SetCondition(brokerv2alpha5.ConditionTypeValid, corev1.ConditionFalse, ReasonInvalidSize,
+			fmt.Sprintf("deploymentPlan.size %d must not be negative", spec.DeploymentPlan.Size))
+		return
+	}
+
+	if len(spec.Version) > 0 && !isVersionSupported(spec.Version) {
+		amqbfsm.SetCondition(brokerv2alpha5.ConditionTypeValid, corev1.ConditionFalse, ReasonUnsupportedVersion,
+			fmt.Sprintf("version %s is not supported by this operator, the default version will be used", spec.Version))
+		return
+	}
+
+	if errs := podTemplateOverrideErrors(amqbfsm.customResource); len(errs) > 0 {
+		amqbfsm.SetCondition(brokerv2alpha5.ConditionTypeValid, corev1.ConditionFalse, ReasonInvalidPodTemplate,
+			fmt.Sprintf("deploymentPlan.podTemplate is not applied: %s", errs.ToAggregate().Error()))
+		return
+	}
+
+	amqbfsm.SetCondition(brokerv2alpha5.ConditionTypeValid, corev1.ConditionTrue, ReasonValidationSucceeded, "")
+}
+
+func brokerImageOf(statefulSet *appsv1.StatefulSet) string {
+	if statefulSet == nil || len(statefulSet.Spec.Template.Spec.Containers) == 0 {
+		return ""
+	}
+	return statefulSet.Spec.Template.Spec.Containers[0].Image
+}
@@ -0,0 +1,144 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/fsm"
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newStatefulSet returns the statefulset of the test custom resource with the given replicas,
+// ready replicas and revisions
+func newStatefulSet(replicas int32, readyReplicas int32, currentRevision string, updateRevision string) *appsv1.StatefulSet {
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao-ss", Namespace: "test"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(replicas)},
+		Status: appsv1.StatefulSetStatus{
+			ReadyReplicas:   readyReplicas,
+			UpdatedReplicas: readyReplicas,
+			CurrentRevision: currentRevision,
+			UpdateRevision:  updateRevision,
+		},
+	}
+}
+
+var _ = Describe("Status", func() {
+
+	var scheme *runtime.Scheme
+	var cr *brokerv2alpha5.ActiveMQArtemis
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(brokerv2alpha5.SchemeBuilder.AddToScheme(scheme)).To(Succeed())
+		cr = newTestCR()
+		cr.Generation = 3
+		cr.Spec.DeploymentPlan.Size = 2
+	})
+
+	// newStatusFSM returns an fsm of the custom resource in the given state, reconciled with a
+	// client holding the objects
+	newStatusFSM := func(stateID int, objs ...runtime.Object) (*ActiveMQArtemisFSM, client.Client) {
+		c := fake.NewFakeClientWithScheme(scheme, append(objs, cr.DeepCopy())...)
+		statusFSM := newTestFSM(cr, nil)
+		statusFSM.r = &ReconcileActiveMQArtemis{client: c, scheme: scheme, recorder: record.NewFakeRecorder(10)}
+		machine := statusFSM.m.(*fsm.Machine)
+		machine.SetCurrentState(*machine.GetState(stateID))
+		return statusFSM, c
+	}
+
+	It("records the conditions against the generation of the custom resource", func() {
+		statusFSM := newTestFSM(cr, nil)
+		statusFSM.SetCondition(brokerv2alpha5.ConditionTypeReady, corev1.ConditionTrue, ReasonAllBrokersReady, "")
+		ready := brokerv2alpha5.FindCondition(cr.Status.Conditions, brokerv2alpha5.ConditionTypeReady)
+		Expect(ready.ObservedGeneration).To(BeEquivalentTo(3))
+		transitioned := ready.LastTransitionTime
+
+		cr.Generation = 4
+		statusFSM.SetCondition(brokerv2alpha5.ConditionTypeReady, corev1.ConditionTrue, ReasonAllBrokersReady, "")
+
+		Expect(cr.Status.Conditions).To(HaveLen(1))
+		Expect(cr.Status.Conditions[0].ObservedGeneration).To(BeEquivalentTo(4))
+		Expect(cr.Status.Conditions[0].LastTransitionTime).To(Equal(transitioned))
+	})
+
+	table.DescribeTable("the conditions each state reports",
+		func(stateID int, statefulSet *appsv1.StatefulSet, nextStateID int, expected map[brokerv2alpha5.ConditionType]string) {
+			var objs []runtime.Object
+			if statefulSet != nil {
+				objs = append(objs, statefulSet)
+			}
+			statusFSM, _ := newStatusFSM(stateID, objs...)
+
+			err, next := (*statusFSM.m.(*fsm.Machine).GetState(stateID)).Update()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(next).To(Equal(nextStateID))
+			reasons := map[brokerv2alpha5.ConditionType]string{}
+			for _, condition := range cr.Status.Conditions {
+				Expect(condition.ObservedGeneration).To(BeEquivalentTo(3))
+				reasons[condition.Type] = string(condition.Status) + "/" + condition.Reason
+			}
+			Expect(reasons).To(Equal(expected))
+		},
+		table.Entry("creating, without a statefulset", CreatingK8sResourcesID, nil, CreatingK8sResourcesID,
+			map[brokerv2alpha5.ConditionType]string{
+				brokerv2alpha5.ConditionTypeDeployed: "False/" + ReasonStatefulSetNotFound,
+			}),
+		table.Entry("running, brokers not ready", ContainerRunningID, newStatefulSet(2, 1, "rev-1", "rev-1"), ScalingID,
+			map[brokerv2alpha5.ConditionType]string{}),
+		table.Entry("scaling, brokers rolling to a new revision", ScalingID, newStatefulSet(2, 1, "rev-1", "rev-2"), ScalingID,
+			map[brokerv2alpha5.ConditionType]string{
+				brokerv2alpha5.ConditionTypeReady:         "False/" + ReasonWaitingForBrokers,
+				brokerv2alpha5.ConditionTypeConfigApplied: "False/" + ReasonRollingUpdate,
+			}),
+		table.Entry("scaling, all brokers ready at the same revision", ScalingID, newStatefulSet(2, 2, "rev-2", "rev-2"), ContainerRunningID,
+			map[brokerv2alpha5.ConditionType]string{
+				brokerv2alpha5.ConditionTypeReady:         "True/" + ReasonAllBrokersReady,
+				brokerv2alpha5.ConditionTypeConfigApplied: "True/" + ReasonConfigApplied,
+				brokerv2alpha5.ConditionTypeUpgrading:     "False/" + ReasonNoUpgrade,
+			}),
+	)
+
+	It("reports the ready condition of a deployment scaled to zero", func() {
+		cr.Spec.DeploymentPlan.Size = 0
+		statusFSM, _ := newStatusFSM(ScalingID, newStatefulSet(0, 0, "rev-1", "rev-1"))
+
+		err, next := (*statusFSM.m.(*fsm.Machine).GetState(ScalingID)).Update()
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next).To(Equal(CreatingK8sResourcesID))
+		ready := brokerv2alpha5.FindCondition(cr.Status.Conditions, brokerv2alpha5.ConditionTypeReady)
+		Expect(ready.Status).To(Equal(corev1.ConditionFalse))
+		Expect(ready.Reason).To(Equal(ReasonScaledToZero))
+	})
+
+	It("writes the state and the generation it observed", func() {
+		statusFSM, c := newStatusFSM(ScalingID)
+		Expect(UpdateStatus(statusFSM)).To(Succeed())
+
+		current := &brokerv2alpha5.ActiveMQArtemis{}
+		Expect(c.Get(context.TODO(), statusFSM.namespacedName, current)).To(Succeed())
+		Expect(current.Status.FSMState).To(Equal(Scaling))
+		Expect(current.Status.ObservedGeneration).To(BeEquivalentTo(3))
+
+		cr.Generation = 4
+		statusFSM.m.SetCurrentState(*statusFSM.m.(*fsm.Machine).GetState(ContainerRunningID))
+		Expect(UpdateStatus(statusFSM)).To(Succeed())
+
+		Expect(c.Get(context.TODO(), statusFSM.namespacedName, current)).To(Succeed())
+		Expect(current.Status.FSMState).To(Equal(ContainerRunning))
+		Expect(current.Status.ObservedGeneration).To(BeEquivalentTo(4))
+	})
+})
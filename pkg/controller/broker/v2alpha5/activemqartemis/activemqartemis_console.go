@@ -0,0 +1,369 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/environments"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/routes"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/escape"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/random"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	defaultOpenShiftOAuthProxyImage = "quay.io/openshift/origin-oauth-proxy:4.9"
+	defaultOIDCOAuthProxyImage      = "quay.io/oauth2-proxy/oauth2-proxy:v7.2.1"
+
+	// The keys of the console oauth secret the operator generates
+	consoleOAuthCookieSecret = "cookie-secret"
+	consoleOAuthPassword     = "password"
+
+	// The proxy and the init container read the secrets from these env vars
+	consoleOAuthCookieSecretEnvVar = "OAUTH_PROXY_COOKIE_SECRET"
+	consoleOAuthClientSecretEnvVar = "OAUTH_PROXY_CLIENT_SECRET"
+	consoleOAuthPasswordEnvVar     = "AMQ_CONSOLE_OAUTH_PASSWORD"
+
+	// The JAAS domain the console logs the users passed on by the proxy in with, they all
+	// share the generated password only the proxy knows
+	consoleOAuthDomain     = "console-oauth"
+	consoleOAuthUsersFile  = "console-oauth-users.properties"
+	consoleOAuthRolesFile  = "console-oauth-roles.properties"
+	consoleOAuthLoginFile  = "console-oauth-login.config"
+	consoleOAuthConfigPath = "$CONFIG_INSTANCE_DIR/console-oauth"
+
+	// the files and the role the brokers give the admin user the operator logs in with
+	consoleAdminUsersFile = "artemis-users.properties"
+	consoleAdminRolesFile = "artemis-roles.properties"
+	consoleAdminRole      = "admin"
+
+	oauthRedirectReferenceAnnotation = "serviceaccounts.openshift.io/oauth-redirectreference."
+
+	// The openshift proxy verifies the api server and the oauth server against these
+	serviceAccountCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+	systemCAFile         = "/etc/pki/tls/cert.pem"
+)
+
+func isConsoleOAuthProxyEnabled(customResource *brokerv2alpha5.ActiveMQArtemis) bool {
+	return customResource.Spec.Console.Expose && customResource.Spec.Console.OAuthProxy != nil
+}
+
+// usesConsoleOAuthServiceAccount tells whether the pods run with the service account the
+// operator registers the openshift proxy through
+func usesConsoleOAuthServiceAccount(customResource *brokerv2alpha5.ActiveMQArtemis) bool {
+	return isConsoleOAuthProxyEnabled(customResource) &&
+		customResource.Spec.Console.OAuthProxy.Provider == brokerv2alpha5.ConsoleOAuthProviderOpenShift &&
+		customResource.Spec.DeploymentPlan.PodSecurity.ServiceAccountName == nil
+}
+
+// isConsoleTLS tells whether the console itself serves tls, on kubernetes an exposed
+// console is terminated at the ingress instead
+func isConsoleTLS(customResource *brokerv2alpha5.ActiveMQArtemis, isOpenshift bool) bool {
+	return customResource.Spec.Console.SSLEnabled && (isOpenshift || !customResource.Spec.Console.Expose)
+}
+
+// syncConsoleOAuth generates the secrets the proxy of the console shares with the brokers
+// and, for the openshift provider, the service account it is registered through
+func syncConsoleOAuth(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme) {
+
+	secretNamespacedName := types.NamespacedName{
+		Name:      fsm.GetConsoleOAuthSecretName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	secret := secrets.NewSecret(secretNamespacedName, secretNamespacedName.Name, map[string]string{}, fsm.namers.LabelBuilder.Labels())
+
+	if !isConsoleOAuthProxyEnabled(fsm.customResource) {
+		resources.Disable(fsm.customResource, client, scheme, secretNamespacedName, secret)
+	} else if err := client.Get(context.TODO(), secretNamespacedName, secret); errors.IsNotFound(err) {
+		secret.StringData = map[string]string{
+			consoleOAuthCookieSecret: random.GenerateRandomString(32),
+			consoleOAuthPassword:     random.GenerateRandomString(32),
+		}
+		log.Info("Creating console oauth secret", "name", secretNamespacedName.Name)
+		if err = resources.Create(fsm.customResource, secretNamespacedName, client, scheme, secret); err != nil {
+			log.Error(err, "Failed to create console oauth secret", "name", secretNamespacedName.Name)
+		}
+	} else if err != nil {
+		log.Error(err, "Failed to get console oauth secret", "name", secretNamespacedName.Name)
+	}
+
+	syncConsoleOAuthServiceAccount(fsm, client, scheme)
+	checkConsoleRoles(fsm)
+}
+
+// syncConsoleOAuthServiceAccount keeps the oauth redirect references of the service account
+// pointing at the console routes of the brokers, the openshift oauth server only redirects
+// back to them
+func syncConsoleOAuthServiceAccount(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme) {
+
+	namespacedName := types.NamespacedName{
+		Name:      fsm.GetConsoleOAuthServiceAccountName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	requested := &corev1.ServiceAccount{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ServiceAccount",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        namespacedName.Name,
+			Namespace:   namespacedName.Namespace,
+			Labels:      fsm.namers.LabelBuilder.Labels(),
+			Annotations: make(map[string]string),
+		},
+	}
+
+	if !usesConsoleOAuthServiceAccount(fsm.customResource) {
+		resources.Disable(fsm.customResource, client, scheme, namespacedName, requested)
+		return
+	}
+
+	for i := int32(0); i < fsm.customResource.Spec.DeploymentPlan.Size; i++ {
+		ordinal := strconv.Itoa(int(i))
+		routeName := routes.RouteName(consoleServiceName(fsm, ordinal))
+		requested.Annotations[oauthRedirectReferenceAnnotation+ordinal] =
+			`{"kind":"OAuthRedirectReference","apiVersion":"v1","reference":{"kind":"Route","name":"` + routeName + `"}}`
+	}
+
+	deployed := &corev1.ServiceAccount{}
+	err := client.Get(context.TODO(), namespacedName, deployed)
+	if errors.IsNotFound(err) {
+		log.Info("Creating console oauth service account", "name", namespacedName.Name)
+		if err = resources.Create(fsm.customResource, namespacedName, client, scheme, requested); err != nil {
+			log.Error(err, "Failed to create console oauth service account", "name", namespacedName.Name)
+		}
+	} else if err != nil {
+		log.Error(err, "Failed to get console oauth service account", "name", namespacedName.Name)
+	} else if !equality.Semantic.DeepEqual(deployed.Annotations, requested.Annotations) {
+		log.Info("Console routes changed, updating the oauth service account", "name", namespacedName.Name)
+		deployed.Annotations = requested.Annotations
+		if err = resources.Update(namespacedName, client, deployed); err != nil {
+			log.Error(err, "Failed to update console oauth service account", "name", namespacedName.Name)
+		}
+	}
+}
+
+// checkConsoleRoles warns of the roles the users are mapped to that are not hawtio roles of
+// the security applied to the brokers, the console refuses those users
+func checkConsoleRoles(fsm *ActiveMQArtemisFSM) {
+	if !isConsoleOAuthProxyEnabled(fsm.customResource) {
+		return
+	}
+	hawtioRoles := consoleHawtioRoles(fsm)
+	if len(hawtioRoles) == 0 {
+		return
+	}
+	known := make(map[string]bool)
+	for _, role := range hawtioRoles {
+		known[role] = true
+	}
+	for _, mapping := range fsm.customResource.Spec.Console.OAuthProxy.RoleMappings {
+		if !known[mapping.Role] {
+			recordEvent(fsm, fsm.customResource, corev1.EventTypeWarning, EventReasonConsoleRoleUnknown,
+				"Console role %s is not one of the hawtio roles %s", mapping.Role, strings.Join(hawtioRoles, ","))
+		}
+	}
+}
+
+// consoleHawtioRoles returns the hawtio roles of the security applied to the brokers, if any
+func consoleHawtioRoles(fsm *ActiveMQArtemisFSM) []string {
+	namespacedName := types.NamespacedName{
+		Name:      fsm.customResource.Name,
+		Namespace: fsm.customResource.Namespace,
+	}
+	if handler := GetBrokerConfigHandler(namespacedName); handler != nil {
+		return handler.HawtioRoles()
+	}
+	return nil
+}
+
+// consoleOAuthConfigCmds returns the commands adding the JAAS domain of the users the proxy
+// passes on to the login.config files found under the given directories, after the
+// security config handler ran, and switching the console to it. The domain checks the
+// admin user of the brokers first, for the operator to keep reaching jolokia. The console
+// admits the mapped roles and the admin role unless the security applied to the brokers
+// set hawtio roles of its own.
+func consoleOAuthConfigCmds(fsm *ActiveMQArtemisFSM, hawtioRoles []string, configDirs ...string) []string {
+	if !isConsoleOAuthProxyEnabled(fsm.customResource) {
+		return nil
+	}
+
+	usersByRole := make(map[string][]string)
+	var users []string
+	seen := make(map[string]bool)
+	for _, mapping := range fsm.customResource.Spec.Console.OAuthProxy.RoleMappings {
+		usersByRole[mapping.Role] = append(usersByRole[mapping.Role], mapping.Users...)
+		for _, user := range mapping.Users {
+			if !seen[user] {
+				seen[user] = true
+				users = append(users, user)
+			}
+		}
+	}
+	var roles []string
+	for role := range usersByRole {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	var userLines []string
+	for _, user := range users {
+		userLines = append(userLines, escape.ShellQuote(escape.PropertyKey(user)+"=")+"\"$"+consoleOAuthPasswordEnvVar+"\"")
+	}
+	var roleLines []string
+	for _, role := range roles {
+		var escaped []string
+		for _, user := range usersByRole[role] {
+			escaped = append(escaped, escape.PropertyKey(user))
+		}
+		roleLines = append(roleLines, escape.ShellQuote(escape.PropertyKey(role)+"="+strings.Join(escaped, ",")))
+	}
+	domainLines := []string{
+		escape.ShellQuote(consoleOAuthDomain + " {"),
+		escape.ShellQuote("    org.apache.activemq.artemis.spi.core.security.jaas.PropertiesLoginModule sufficient"),
+		escape.ShellQuote("        reload=false"),
+		escape.ShellQuote("        org.apache.activemq.jaas.properties.user=\"" + consoleAdminUsersFile + "\""),
+		escape.ShellQuote("        org.apache.activemq.jaas.properties.role=\"" + consoleAdminRolesFile + "\";"),
+		escape.ShellQuote("    org.apache.activemq.artemis.spi.core.security.jaas.PropertiesLoginModule required"),
+		escape.ShellQuote("        reload=false"),
+		escape.ShellQuote("        org.apache.activemq.jaas.properties.user=\"" + consoleOAuthUsersFile + "\""),
+		escape.ShellQuote("        org.apache.activemq.jaas.properties.role=\"" + consoleOAuthRolesFile + "\";"),
+		escape.ShellQuote("};"),
+	}
+
+	profileArgs := " -e \"s#-Dhawtio.realm=[^ \\\"]*#-Dhawtio.realm=" + consoleOAuthDomain + "#\""
+	if len(hawtioRoles) == 0 {
+		consoleRoles := []string{consoleAdminRole}
+		for _, role := range roles {
+			if role != consoleAdminRole {
+				consoleRoles = append(consoleRoles, role)
+			}
+		}
+		profileArgs += " -e \"s#\\(-Dhawtio.roles\\?=\\)[^ \\\"]*#\\1" + strings.Join(consoleRoles, ",") + "#\""
+	}
+	dirs := strings.Join(configDirs, " ")
+
+	return []string{
+		"mkdir -p " + consoleOAuthConfigPath,
+		"printf '%s\\n' " + strings.Join(userLines, " ") + " > " + consoleOAuthConfigPath + "/" + consoleOAuthUsersFile,
+		"printf '%s\\n' " + strings.Join(roleLines, " ") + " > " + consoleOAuthConfigPath + "/" + consoleOAuthRolesFile,
+		"printf '%s\\n' " + strings.Join(domainLines, " ") + " > " + consoleOAuthConfigPath + "/" + consoleOAuthLoginFile,
+		"for f in $(find " + dirs + " -name login.config 2>/dev/null); do" +
+			" cat " + consoleOAuthConfigPath + "/" + consoleOAuthLoginFile + " >> \"$f\"" +
+			" && cp " + consoleOAuthConfigPath + "/*.properties \"$(dirname \"$f\")\"; done",
+		"for f in $(find " + dirs + " -name artemis.profile 2>/dev/null); do sed -i" + profileArgs + " \"$f\"; done",
+	}
+}
+
+// configConsoleOAuthProxy adds the proxy sidecar to the pods and gives the init container
+// the password the proxy passes the users on with. The console keeps listening on the pod
+// ip, the operator and the metrics scrapes reach jolokia there with the admin user, only
+// its service and route lead to the proxy.
+func configConsoleOAuthProxy(fsm *ActiveMQArtemisFSM, podSpec *corev1.PodSpec) {
+	if !isConsoleOAuthProxyEnabled(fsm.customResource) {
+		return
+	}
+
+	proxy := fsm.customResource.Spec.Console.OAuthProxy
+	secretName := fsm.GetConsoleOAuthSecretName()
+	passwordEnvVar := corev1.EnvVar{
+		Name:      consoleOAuthPasswordEnvVar,
+		ValueFrom: secretKeyRef(secretName, consoleOAuthPassword),
+	}
+	environments.Create(podSpec.InitContainers, &passwordEnvVar)
+
+	isOpenshift, _ := environments.DetectOpenshift()
+	upstream := fmt.Sprintf("http://localhost:%d", 8161)
+	if isConsoleTLS(fsm.customResource, isOpenshift) {
+		upstream = fmt.Sprintf("https://localhost:%d", 8161)
+	}
+	// the route is edge terminated on openshift
+	cookieSecure := isOpenshift || fsm.customResource.Spec.Console.IngressTLSSecret != ""
+
+	args := []string{
+		fmt.Sprintf("--http-address=0.0.0.0:%d", brokerv2alpha5.ConsoleOAuthProxyPort),
+		"--upstream=" + upstream,
+		"--cookie-secret=$(" + consoleOAuthCookieSecretEnvVar + ")",
+		"--cookie-secure=" + strconv.FormatBool(cookieSecure),
+		"--pass-basic-auth=true",
+		"--basic-auth-password=$(" + consoleOAuthPasswordEnvVar + ")",
+		"--email-domain=*",
+		"--skip-provider-button=true",
+	}
+	env := []corev1.EnvVar{
+		{Name: consoleOAuthCookieSecretEnvVar, ValueFrom: secretKeyRef(secretName, consoleOAuthCookieSecret)},
+		passwordEnvVar,
+	}
+	image := proxy.Image
+	switch proxy.Provider {
+	case brokerv2alpha5.ConsoleOAuthProviderOpenShift:
+		if image == "" {
+			image = defaultOpenShiftOAuthProxyImage
+		}
+		serviceAccountName := fsm.GetConsoleOAuthServiceAccountName()
+		if usesConsoleOAuthServiceAccount(fsm.customResource) {
+			podSpec.ServiceAccountName = serviceAccountName
+		} else {
+			serviceAccountName = *fsm.customResource.Spec.DeploymentPlan.PodSecurity.ServiceAccountName
+		}
+		args = append(args,
+			"--provider=openshift",
+			"--https-address=",
+			"--openshift-service-account="+serviceAccountName,
+			"--openshift-ca="+systemCAFile,
+			"--openshift-ca="+serviceAccountCAFile)
+	case brokerv2alpha5.ConsoleOAuthProviderOIDC:
+		if image == "" {
+			image = defaultOIDCOAuthProxyImage
+		}
+		args = append(args,
+			"--provider=oidc",
+			"--oidc-issuer-url="+proxy.IssuerURL,
+			"--client-id="+proxy.ClientID,
+			"--client-secret=$("+consoleOAuthClientSecretEnvVar+")",
+			"--prefer-email-to-user=true",
+			"--reverse-proxy=true",
+			"--ssl-upstream-insecure-skip-verify=true")
+		if proxy.ClientSecret != nil {
+			env = append(env, corev1.EnvVar{
+				Name:      consoleOAuthClientSecretEnvVar,
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: proxy.ClientSecret.DeepCopy()},
+			})
+		}
+	}
+
+	podSpec.Containers = append(podSpec.Containers, corev1.Container{
+		Name:  fsm.customResource.Name + "-console-oauth-proxy",
+		Image: image,
+		Args:  args,
+		Env:   env,
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "oauth-proxy",
+				ContainerPort: brokerv2alpha5.ConsoleOAuthProxyPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+	})
+}
+
+func secretKeyRef(secretName string, key string) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{
+		SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+			Key:                  key,
+		},
+	}
+}
@@ -0,0 +1,243 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// brokerProfile, brokerLoginConfig, brokerUsers and brokerRoles are the files of an instance
+// the broker image creates, logging the admin user in to the console
+const (
+	brokerProfile     = `JAVA_ARGS=" -XX:+PrintClassHistogram -Dhawtio.realm=activemq -Dhawtio.offline=true -Dhawtio.role=admin -Dhawtio.rolePrincipalClasses=org.apache.activemq.artemis.spi.core.security.jaas.RolePrincipal"` + "\n"
+	brokerLoginConfig = `activemq {
+   org.apache.activemq.artemis.spi.core.security.jaas.PropertiesLoginModule sufficient
+       reload=true
+       org.apache.activemq.jaas.properties.user="artemis-users.properties"
+       org.apache.activemq.jaas.properties.role="artemis-roles.properties";
+};
+`
+	brokerUsers = "operator = s3cr3t\n"
+	brokerRoles = "admin = operator\n"
+)
+
+// loadPropertiesFile reads the keys and values of a properties file, unescaping the keys
+func loadPropertiesFile(path string) map[string]string {
+	data, err := ioutil.ReadFile(path)
+	Expect(err).NotTo(HaveOccurred())
+	properties := make(map[string]string)
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		var key strings.Builder
+		i := 0
+		for ; i < len(line) && line[i] != '=' && line[i] != ':'; i++ {
+			if line[i] == '\\' {
+				i++
+			}
+			key.WriteByte(line[i])
+		}
+		properties[strings.TrimSpace(key.String())] = strings.TrimSpace(line[i+1:])
+	}
+	return properties
+}
+
+// consoleLogin logs a user in to the console of the instance the way hawtio does, with the
+// login modules of the realm of the profile, and returns the hawtio roles it is admitted
+// with, none when it is refused
+func consoleLogin(etcDir string, user string, password string) []string {
+	profile, err := ioutil.ReadFile(filepath.Join(etcDir, "artemis.profile"))
+	Expect(err).NotTo(HaveOccurred())
+	realm := regexp.MustCompile(`-Dhawtio.realm=([^ "]*)`).FindStringSubmatch(string(profile))[1]
+	hawtioRoles := strings.Split(regexp.MustCompile(`-Dhawtio.roles?=([^ "]*)`).FindStringSubmatch(string(profile))[1], ",")
+
+	loginConfig, err := ioutil.ReadFile(filepath.Join(etcDir, "login.config"))
+	Expect(err).NotTo(HaveOccurred())
+	domain := regexp.MustCompile(`(?ms)^` + regexp.QuoteMeta(realm) + ` \{$(.*?)^\};`).FindStringSubmatch(string(loginConfig))
+	Expect(domain).NotTo(BeNil(), "the %s domain is in the login config", realm)
+
+	authenticated := false
+	var principals []string
+	for _, module := range regexp.MustCompile(`(?s)PropertiesLoginModule (\w+)(.*?);`).FindAllStringSubmatch(domain[1], -1) {
+		options := make(map[string]string)
+		for _, option := range regexp.MustCompile(`(\S+)="([^"]*)"`).FindAllStringSubmatch(module[2], -1) {
+			options[option[1]] = option[2]
+		}
+		users := loadPropertiesFile(filepath.Join(etcDir, options["org.apache.activemq.jaas.properties.user"]))
+		if stored, ok := users[user]; !ok || stored != password {
+			if module[1] == "required" {
+				return nil
+			}
+			continue
+		}
+		authenticated = true
+		for role, names := range loadPropertiesFile(filepath.Join(etcDir, options["org.apache.activemq.jaas.properties.role"])) {
+			for _, name := range strings.Split(names, ",") {
+				if strings.Replace(name, `\`, "", -1) == user {
+					principals = append(principals, role)
+				}
+			}
+		}
+		if module[1] == "sufficient" {
+			break
+		}
+	}
+	if !authenticated {
+		return nil
+	}
+
+	var admitted []string
+	for _, role := range hawtioRoles {
+		for _, principal := range principals {
+			if role == principal {
+				admitted = append(admitted, role)
+			}
+		}
+	}
+	return admitted
+}
+
+var _ = Describe("Console oauth proxy", func() {
+
+	var fsm *ActiveMQArtemisFSM
+
+	BeforeEach(func() {
+		cr := newTestCR()
+		cr.UID = "ex-aao-uid"
+		cr.Spec.DeploymentPlan.Size = 2
+		cr.Spec.Console.Expose = true
+		cr.Spec.Console.OAuthProxy = &brokerv2alpha5.ConsoleOAuthProxyType{
+			Provider: brokerv2alpha5.ConsoleOAuthProviderOpenShift,
+			RoleMappings: []brokerv2alpha5.ConsoleRoleMappingType{
+				{Role: "viewer", Users: []string{"o'brien", "a=b"}},
+				{Role: "admin", Users: []string{"a=b", "c:d"}},
+			},
+		}
+		fsm = newTestFSM(cr, nil)
+	})
+
+	Context("consoleOAuthConfigCmds", func() {
+
+		It("escapes the user names for the properties files and the shell", func() {
+			cmds := consoleOAuthConfigCmds(fsm, nil, "$CONFIG_INSTANCE_DIR")
+
+			Expect(cmds).To(HaveLen(6))
+			Expect(cmds[0]).To(Equal(`mkdir -p $CONFIG_INSTANCE_DIR/console-oauth`))
+			Expect(cmds[1]).To(Equal(`printf '%s\n'` +
+				` 'o'\''brien='"$AMQ_CONSOLE_OAUTH_PASSWORD"` +
+				` 'a\=b='"$AMQ_CONSOLE_OAUTH_PASSWORD"` +
+				` 'c\:d='"$AMQ_CONSOLE_OAUTH_PASSWORD"` +
+				` > $CONFIG_INSTANCE_DIR/console-oauth/console-oauth-users.properties`))
+			Expect(cmds[2]).To(Equal(`printf '%s\n'` +
+				` 'admin=a\=b,c\:d'` +
+				` 'viewer=o'\''brien,a\=b'` +
+				` > $CONFIG_INSTANCE_DIR/console-oauth/console-oauth-roles.properties`))
+			Expect(cmds[5]).To(Equal(`for f in $(find $CONFIG_INSTANCE_DIR -name artemis.profile 2>/dev/null); do sed -i` +
+				` -e "s#-Dhawtio.realm=[^ \"]*#-Dhawtio.realm=console-oauth#"` +
+				` -e "s#\(-Dhawtio.roles\?=\)[^ \"]*#\1admin,viewer#"` +
+				` "$f"; done`))
+		})
+
+		It("keeps the admin user of the brokers logging in to the console", func() {
+			fsm.customResource.Spec.Console.OAuthProxy.RoleMappings = []brokerv2alpha5.ConsoleRoleMappingType{
+				{Role: "viewer", Users: []string{"o'brien"}},
+			}
+			dir, err := ioutil.TempDir("", "console-oauth")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+			etcDir := filepath.Join(dir, "etc")
+			Expect(os.Mkdir(etcDir, 0755)).To(Succeed())
+			for name, content := range map[string]string{
+				"artemis.profile":          brokerProfile,
+				"login.config":             brokerLoginConfig,
+				"artemis-users.properties": brokerUsers,
+				"artemis-roles.properties": brokerRoles,
+			} {
+				Expect(ioutil.WriteFile(filepath.Join(etcDir, name), []byte(content), 0644)).To(Succeed())
+			}
+
+			for _, cmd := range consoleOAuthConfigCmds(fsm, nil, "$CONFIG_INSTANCE_DIR") {
+				sh := exec.Command("sh", "-c", cmd)
+				sh.Env = []string{"PATH=" + os.Getenv("PATH"), "CONFIG_INSTANCE_DIR=" + dir, "AMQ_CONSOLE_OAUTH_PASSWORD=pr0xy"}
+				out, err := sh.CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(out))
+			}
+
+			Expect(consoleLogin(etcDir, "operator", "s3cr3t")).To(Equal([]string{"admin"}))
+			Expect(consoleLogin(etcDir, "o'brien", "pr0xy")).To(Equal([]string{"viewer"}))
+			Expect(consoleLogin(etcDir, "operator", "pr0xy")).To(BeEmpty())
+			Expect(consoleLogin(etcDir, "o'brien", "s3cr3t")).To(BeEmpty())
+			profile, err := ioutil.ReadFile(filepath.Join(etcDir, "artemis.profile"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(profile)).To(ContainSubstring("-Dhawtio.rolePrincipalClasses=org.apache.activemq.artemis.spi.core.security.jaas.RolePrincipal"))
+		})
+
+		It("leaves the hawtio roles of the security applied to the brokers", func() {
+			cmds := consoleOAuthConfigCmds(fsm, []string{"admin"}, "$CONFIG_INSTANCE_DIR")
+
+			Expect(cmds[5]).To(Equal(`for f in $(find $CONFIG_INSTANCE_DIR -name artemis.profile 2>/dev/null); do sed -i` +
+				` -e "s#-Dhawtio.realm=[^ \"]*#-Dhawtio.realm=console-oauth#"` +
+				` "$f"; done`))
+		})
+
+		It("writes the properties files the console reads the users from", func() {
+			dir, err := ioutil.TempDir("", "console-oauth")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			for _, cmd := range consoleOAuthConfigCmds(fsm, nil, "$CONFIG_INSTANCE_DIR")[:3] {
+				sh := exec.Command("sh", "-c", cmd)
+				sh.Env = []string{"CONFIG_INSTANCE_DIR=" + dir, "AMQ_CONSOLE_OAUTH_PASSWORD=s3cr=t"}
+				out, err := sh.CombinedOutput()
+				Expect(err).NotTo(HaveOccurred(), string(out))
+			}
+
+			users, err := ioutil.ReadFile(filepath.Join(dir, "console-oauth", "console-oauth-users.properties"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(users)).To(Equal("o'brien=s3cr=t\na\\=b=s3cr=t\nc\\:d=s3cr=t\n"))
+			roles, err := ioutil.ReadFile(filepath.Join(dir, "console-oauth", "console-oauth-roles.properties"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(roles)).To(Equal("admin=a\\=b,c\\:d\nviewer=o'brien,a\\=b\n"))
+		})
+	})
+
+	It("verifies the openshift servers against the service account ca", func() {
+		podSpec := &corev1.PodSpec{InitContainers: []corev1.Container{{Name: "ex-aao-container-init"}}}
+		configConsoleOAuthProxy(fsm, podSpec)
+
+		Expect(podSpec.Containers).To(HaveLen(1))
+		Expect(podSpec.Containers[0].Args).To(ContainElement("--openshift-ca=/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"))
+		Expect(podSpec.Containers[0].Args).NotTo(ContainElement("--ssl-insecure-skip-verify=true"))
+	})
+
+	It("redirects the users back to the console routes of the brokers", func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(brokerv2alpha5.SchemeBuilder.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewFakeClientWithScheme(scheme, fsm.customResource)
+
+		syncConsoleOAuthServiceAccount(fsm, c, scheme)
+
+		serviceAccount := &corev1.ServiceAccount{}
+		Expect(c.Get(context.TODO(), types.NamespacedName{Name: fsm.GetConsoleOAuthServiceAccountName(), Namespace: "test"}, serviceAccount)).To(Succeed())
+		Expect(serviceAccount.Annotations).To(HaveKeyWithValue(oauthRedirectReferenceAnnotation+"0",
+			`{"kind":"OAuthRedirectReference","apiVersion":"v1","reference":{"kind":"Route","name":"ex-aao-wconsj-0-svc-rte"}}`))
+		Expect(serviceAccount.Annotations).To(HaveKeyWithValue(oauthRedirectReferenceAnnotation+"1",
+			`{"kind":"OAuthRedirectReference","apiVersion":"v1","reference":{"kind":"Route","name":"ex-aao-wconsj-1-svc-rte"}}`))
+	})
+})
@@ -2,19 +2,27 @@ package v2alpha5activemqartemis
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"time"
 
 	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/client/clientset/versioned"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/metrics"
 	nsoptions "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/namespaces"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/common"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/lsrcrs"
 
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/selectors"
+	jsonpatch "github.com/evanphx/json-patch"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -26,11 +34,19 @@ import (
 
 var log = logf.Log.WithName("controller_v2alpha5activemqartemis")
 
+// The annotations recording the images the operator stored in the spec of a CR, which it
+// replaces as the resolved images change
+const (
+	DefaultImageAnnotation     = "broker.amq.io/default-image"
+	DefaultInitImageAnnotation = "broker.amq.io/default-init-image"
+)
+
 var namespacedNameToFSM = make(map[types.NamespacedName]*ActiveMQArtemisFSM)
 
 type ActiveMQArtemisConfigHandler interface {
 	IsApplicableFor(brokerNamespacedName types.NamespacedName) bool
 	Config(initContainers []corev1.Container, outputDirRoot string, yacfgProfileVersion string, yacfgProfileName string) (value []string)
+	HawtioRoles() []string
 }
 
 var namespaceToConfigHandler = make(map[types.NamespacedName]ActiveMQArtemisConfigHandler)
@@ -53,6 +69,9 @@ func UpdatePodForSecurity(securityHandlerNamespacedName types.NamespacedName, ha
 			if err, _ := fsm.Update(); err != nil {
 				success = false
 				log.Error(err, "error in updating security", "cr", fsm.namespacedName)
+				recordEvent(fsm, fsm.customResource, corev1.EventTypeWarning, EventReasonSecurityFailed, "Failed to apply security %s: %v", securityHandlerNamespacedName.Name, err)
+			} else {
+				recordEvent(fsm, fsm.customResource, corev1.EventTypeNormal, EventReasonSecurityApplied, "Applied security %s to the broker pods", securityHandlerNamespacedName.Name)
 			}
 		}
 	}
@@ -99,7 +118,7 @@ func Add(mgr manager.Manager) error {
 
 // newReconciler returns a new reconcile.Reconciler
 func newReconciler(mgr manager.Manager) reconcile.Reconciler {
-	return &ReconcileActiveMQArtemis{client: mgr.GetClient(), scheme: mgr.GetScheme(), result: reconcile.Result{Requeue: false}}
+	return &ReconcileActiveMQArtemis{client: mgr.GetClient(), clientset: versioned.NewForConfigOrDie(mgr.GetConfig()), scheme: mgr.GetScheme(), recorder: mgr.GetRecorder("v2alpha5activemqartemis-controller"), result: reconcile.Result{Requeue: false}}
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
@@ -135,6 +154,34 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// Watch for changes to the services, to report the addresses of the load balancers
+	// exposing the acceptors once these are assigned
+	err = c.Watch(&source.Kind{Type: &corev1.Service{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &brokerv2alpha5.ActiveMQArtemis{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the ssl secrets labelled with their broker, to convert the
+	// certificates cert-manager issues and renews to the stores the brokers load
+	err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+		ToRequests: secretToBrokerRequests(mgr.GetClient()),
+	}, sslSecretPredicate())
+	if err != nil {
+		return err
+	}
+
+	// Watch for changes to the pod disruption budget, it is recreated once deleted to be changed
+	err = c.Watch(&source.Kind{Type: &policyv1beta1.PodDisruptionBudget{}}, &handler.EnqueueRequestForOwner{
+		IsController: true,
+		OwnerType:    &brokerv2alpha5.ActiveMQArtemis{},
+	})
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -145,8 +192,11 @@ type ReconcileActiveMQArtemis struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
-	scheme *runtime.Scheme
-	result reconcile.Result
+	// clientset patches the CRs, which the client can't do
+	clientset versioned.Interface
+	scheme    *runtime.Scheme
+	recorder  record.EventRecorder
+	result    reconcile.Result
 }
 
 // Reconcile reads that state of the cluster for a ActiveMQArtemis object and makes changes based on the state read
@@ -156,7 +206,8 @@ type ReconcileActiveMQArtemis struct {
 // Note:
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
-func (r *ReconcileActiveMQArtemis) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+// The error is a named result for the deferred metrics to observe the one returned.
+func (r *ReconcileActiveMQArtemis) Reconcile(request reconcile.Request) (result reconcile.Result, err error) {
 
 	// Log where we are and what we're doing
 	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
@@ -167,7 +218,6 @@ func (r *ReconcileActiveMQArtemis) Reconcile(request reconcile.Request) (reconci
 		return reconcile.Result{}, nil
 	}
 
-	var err error = nil
 	var namespacedNameFSM *ActiveMQArtemisFSM = nil
 	var amqbfsm *ActiveMQArtemisFSM = nil
 
@@ -177,6 +227,15 @@ func (r *ReconcileActiveMQArtemis) Reconcile(request reconcile.Request) (reconci
 		Namespace: request.Namespace,
 	}
 
+	// The metrics of a deleted CR are removed rather than observed
+	deleted := false
+	start := time.Now()
+	defer func() {
+		if !deleted {
+			metrics.ObserveReconcile(request.Namespace, request.Name, time.Since(start), err)
+		}
+	}()
+
 	// Fetch the ActiveMQArtemis instance
 	// When first creating this will have err == nil
 	// When deleting after creation this will have err NotFound
@@ -196,6 +255,8 @@ func (r *ReconcileActiveMQArtemis) Reconcile(request reconcile.Request) (reconci
 				delete(namespacedNameToFSM, namespacedName)
 				amqbfsm = nil
 			}
+			metrics.DeleteBroker(request.Namespace, request.Name)
+			deleted = true
 
 			// Setting err to nil to prevent requeue
 			err = nil
@@ -208,6 +269,11 @@ func (r *ReconcileActiveMQArtemis) Reconcile(request reconcile.Request) (reconci
 		return r.result, err
 	}
 
+	// Store the defaults in the CR, the update brings us back here with them in place
+	if updated, err := r.persistDefaults(customResource); updated || err != nil {
+		return r.result, err
+	}
+
 	// Do lookup to see if we have a fsm for the incoming name in the incoming namespace
 	// if not, create it
 	// for the given fsm, do an update
@@ -280,6 +346,89 @@ func (r *ReconcileActiveMQArtemis) Reconcile(request reconcile.Request) (reconci
 	return r.result, err
 }
 
+// persistDefaults writes the defaults the reconciler relies on, such as the acceptor
+// ports and the images, into the stored spec when the defaulting webhook hasn't done so
+// already. It returns true when the CR was patched.
+func (r *ReconcileActiveMQArtemis) persistDefaults(customResource *brokerv2alpha5.ActiveMQArtemis) (bool, error) {
+
+	patch, err := defaultsPatch(customResource)
+	if err != nil || patch == nil {
+		return false, err
+	}
+
+	log.Info("Storing defaults in the spec", "name", customResource.Name, "namespace", customResource.Namespace)
+	_, err = r.clientset.BrokerV2alpha5().ActiveMQArtemises(customResource.Namespace).Patch(customResource.Name, types.MergePatchType, patch)
+	if err != nil {
+		if errors.IsConflict(err) {
+			// a newer version is on its way, the defaults are applied to that one
+			return true, nil
+		}
+		log.Error(err, "Failed to store defaults, carrying on with the spec as is")
+		return false, nil
+	}
+	return true, nil
+}
+
+// defaultsPatch returns the merge patch storing the defaults of the CR, nil when they are
+// all in place. The patch only touches the defaulted fields, so that fields of the stored
+// CR this operator doesn't know of are kept, and it carries the resource version, as the
+// lists it replaces may have changed in the meantime.
+func defaultsPatch(customResource *brokerv2alpha5.ActiveMQArtemis) ([]byte, error) {
+
+	defaulted := customResource.DeepCopy()
+	brokerv2alpha5.SetObjectDefaults_ActiveMQArtemis(defaulted)
+	defaultImage(defaulted, &defaulted.Spec.DeploymentPlan.Image, DefaultImageAnnotation, "Kubernetes")
+	defaultImage(defaulted, &defaulted.Spec.DeploymentPlan.InitImage, DefaultInitImageAnnotation, "Init")
+	if reflect.DeepEqual(defaulted.Spec, customResource.Spec) && reflect.DeepEqual(defaulted.Annotations, customResource.Annotations) {
+		return nil, nil
+	}
+
+	original, err := json.Marshal(customResource)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := json.Marshal(defaulted)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := jsonpatch.CreateMergePatch(original, modified)
+	if err != nil {
+		return nil, err
+	}
+
+	versioned := map[string]interface{}{}
+	if err := json.Unmarshal(patch, &versioned); err != nil {
+		return nil, err
+	}
+	metadata, _ := versioned["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		versioned["metadata"] = metadata
+	}
+	metadata["resourceVersion"] = customResource.ResourceVersion
+	return json.Marshal(versioned)
+}
+
+// defaultImage stores the image the operator resolves for the CR in the spec. An image it
+// stored before, as told by the annotation, is resolved again so that it keeps following
+// the version of the CR and the operator upgrades. An image set by the user, including
+// "placeholder", is left alone.
+func defaultImage(customResource *brokerv2alpha5.ActiveMQArtemis, image *string, annotation string, imageTypeName string) {
+
+	if *image != "" && *image != customResource.Annotations[annotation] {
+		return
+	}
+	resolved := determineImageToUse(customResource, imageTypeName)
+	if resolved == "" {
+		return
+	}
+	*image = resolved
+	if customResource.Annotations == nil {
+		customResource.Annotations = map[string]string{}
+	}
+	customResource.Annotations[annotation] = resolved
+}
+
 func GetDefaultLabels(cr *brokerv2alpha5.ActiveMQArtemis) map[string]string {
 	defaultLabelData := selectors.LabelerData{}
 	defaultLabelData.Base(cr.Name).Suffix("app").Generate()
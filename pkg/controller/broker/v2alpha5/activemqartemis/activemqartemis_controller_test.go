@@ -0,0 +1,49 @@
+package v2alpha5activemqartemis
+
+import (
+	nsoptions "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/namespaces"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reconcileErrors returns the reconcile errors counted for the CR
+func reconcileErrors(namespace string, name string) float64 {
+	families, err := crmetrics.Registry.Gather()
+	Expect(err).NotTo(HaveOccurred())
+	for _, family := range families {
+		if family.GetName() != "activemq_artemis_operator_reconcile_errors_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["namespace"] == namespace && labels["name"] == name {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+var _ = Describe("Reconcile", func() {
+
+	It("counts the error it returns", func() {
+		nsoptions.SetWatchNamespace("metrics")
+		defer nsoptions.SetWatchList(nil)
+		// a client that knows no kinds fails to get the CR
+		r := &ReconcileActiveMQArtemis{client: fake.NewFakeClientWithScheme(runtime.NewScheme())}
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "metrics", Name: "ex-aao"}}
+
+		_, err := r.Reconcile(request)
+
+		Expect(err).To(HaveOccurred())
+		Expect(reconcileErrors("metrics", "ex-aao")).To(BeEquivalentTo(1))
+	})
+})
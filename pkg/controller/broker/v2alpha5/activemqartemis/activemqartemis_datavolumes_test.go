@@ -0,0 +1,95 @@
+package v2alpha5activemqartemis
+
+import (
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func claimTemplateNames(statefulSet *appsv1.StatefulSet) []string {
+	var names []string
+	for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+		names = append(names, template.Name)
+	}
+	return names
+}
+
+// mountPaths returns the mount paths of the broker container by volume name
+func mountPaths(statefulSet *appsv1.StatefulSet) map[string]string {
+	paths := make(map[string]string)
+	for _, mount := range statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts {
+		paths[mount.Name] = mount.MountPath
+	}
+	return paths
+}
+
+// initArgs returns the commands the init container runs
+func initArgs(statefulSet *appsv1.StatefulSet) string {
+	args := statefulSet.Spec.Template.Spec.InitContainers[0].Args
+	return args[len(args)-1]
+}
+
+var _ = Describe("Data volumes", func() {
+
+	newVolumesCR := func(directories ...string) *brokerv2alpha5.ActiveMQArtemis {
+		cr := newPersistentCR("2Gi")
+		for _, directory := range directories {
+			cr.Spec.DeploymentPlan.Storage.Volumes = append(cr.Spec.DeploymentPlan.Storage.Volumes,
+				brokerv2alpha5.DataVolumeType{Directory: directory, Size: "10Gi"})
+		}
+		return cr
+	}
+
+	It("puts the directories of a new CR on claims of their own", func() {
+		statefulSet := NewStatefulSetForCR(newTestFSM(newVolumesCR("journal", "largeMessages"), nil))
+
+		Expect(claimTemplateNames(statefulSet)).To(Equal([]string{"ex-aao", "ex-aao-journal", "ex-aao-large-messages"}))
+		Expect(storageSize(&statefulSet.Spec.VolumeClaimTemplates[1])).To(Equal("10Gi"))
+		paths := mountPaths(statefulSet)
+		Expect(paths).To(HaveKeyWithValue("ex-aao-journal", "/opt/ex-aao/journal"))
+		Expect(paths).To(HaveKeyWithValue("ex-aao-large-messages", "/opt/ex-aao/large-messages"))
+		Expect(initArgs(statefulSet)).To(ContainSubstring("<journal-directory>/opt/ex-aao/journal</journal-directory>"))
+		Expect(initArgs(statefulSet)).To(ContainSubstring("<large-messages-directory>/opt/ex-aao/large-messages</large-messages-directory>"))
+		Expect(initArgs(statefulSet)).NotTo(ContainSubstring("paging-directory"))
+	})
+
+	It("keeps the deployed directories when the volumes of the CR change", func() {
+		deployed := NewStatefulSetForCR(newTestFSM(newVolumesCR("journal"), nil))
+		recorder := record.NewFakeRecorder(10)
+
+		cr := newVolumesCR("paging")
+		fsm := newTestFSM(cr, nil)
+		fsm.r = &ReconcileActiveMQArtemis{recorder: recorder}
+		fsm.deployedClaimTemplates = deployed.Spec.VolumeClaimTemplates
+
+		statefulSet := NewStatefulSetForCR(fsm)
+		paths := mountPaths(statefulSet)
+		Expect(paths).To(HaveKey("ex-aao-journal"))
+		Expect(paths).NotTo(HaveKey("ex-aao-paging"))
+		Expect(initArgs(statefulSet)).To(ContainSubstring("<journal-directory>/opt/ex-aao/journal</journal-directory>"))
+		Expect(initArgs(statefulSet)).NotTo(ContainSubstring("paging-directory"))
+
+		current, recreated := recreateForClaimTemplates(fsm, fake.NewFakeClient(deployed.DeepCopy()), deployed)
+		Expect(recreated).To(BeFalse())
+		Expect(current).To(BeIdenticalTo(deployed))
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix(corev1.EventTypeWarning+" "+EventReasonStorageNotApplied),
+			ContainSubstring("addition of claim template ex-aao-paging, removal of claim template ex-aao-journal"))))
+	})
+
+	It("keeps the deployed directories on a statefulset recreated for another change", func() {
+		deployed := NewStatefulSetForCR(newTestFSM(newVolumesCR("journal"), nil))
+		fsm := newTestFSM(newVolumesCR(), nil)
+		fsm.deployedClaimTemplates = deployed.Spec.VolumeClaimTemplates
+
+		statefulSet := NewStatefulSetForCR(fsm)
+		keepClaimTemplates(statefulSet, deployed.Spec.VolumeClaimTemplates)
+
+		Expect(claimTemplateNames(statefulSet)).To(Equal([]string{"ex-aao", "ex-aao-journal"}))
+		Expect(mountPaths(statefulSet)).To(HaveKey("ex-aao-journal"))
+	})
+})
@@ -0,0 +1,127 @@
+package v2alpha5activemqartemis
+
+import (
+	"encoding/json"
+	"os"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/client/clientset/versioned/fake"
+	"github.com/artemiscloud/activemq-artemis-operator/version"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Storing the defaults", func() {
+
+	imageEnv := "RELATED_IMAGE_ActiveMQ_Artemis_Broker_Kubernetes_" + version.CompactLatestVersion
+	initImageEnv := "RELATED_IMAGE_ActiveMQ_Artemis_Broker_Init_" + version.CompactLatestVersion
+
+	BeforeEach(func() {
+		os.Setenv(imageEnv, "quay.io/artemiscloud/broker:1")
+		os.Setenv(initImageEnv, "quay.io/artemiscloud/init:1")
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(imageEnv)
+		os.Unsetenv(initImageEnv)
+	})
+
+	newCR := func() *brokerv2alpha5.ActiveMQArtemis {
+		return &brokerv2alpha5.ActiveMQArtemis{
+			ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test", ResourceVersion: "7"},
+			Spec: brokerv2alpha5.ActiveMQArtemisSpec{
+				Acceptors: []brokerv2alpha5.AcceptorType{{Name: "amqp", Protocols: "amqp"}},
+			},
+		}
+	}
+
+	decode := func(patch []byte) map[string]interface{} {
+		decoded := map[string]interface{}{}
+		Expect(json.Unmarshal(patch, &decoded)).To(Succeed())
+		return decoded
+	}
+
+	It("patches the acceptor ports and images into a new CR", func() {
+		patch, err := defaultsPatch(newCR())
+		Expect(err).To(BeNil())
+
+		decoded := decode(patch)
+		metadata := decoded["metadata"].(map[string]interface{})
+		Expect(metadata["resourceVersion"]).To(Equal("7"))
+		Expect(metadata["annotations"]).To(HaveKeyWithValue(DefaultImageAnnotation, "quay.io/artemiscloud/broker:1"))
+		Expect(metadata["annotations"]).To(HaveKeyWithValue(DefaultInitImageAnnotation, "quay.io/artemiscloud/init:1"))
+
+		spec := decoded["spec"].(map[string]interface{})
+		plan := spec["deploymentPlan"].(map[string]interface{})
+		Expect(plan["image"]).To(Equal("quay.io/artemiscloud/broker:1"))
+		Expect(plan["initImage"]).To(Equal("quay.io/artemiscloud/init:1"))
+		acceptor := spec["acceptors"].([]interface{})[0].(map[string]interface{})
+		Expect(acceptor["port"]).To(BeEquivalentTo(brokerv2alpha5.DefaultAcceptorPortBase))
+	})
+
+	It("leaves a defaulted CR alone", func() {
+		defaulted := newCR()
+		brokerv2alpha5.SetObjectDefaults_ActiveMQArtemis(defaulted)
+		defaultImage(defaulted, &defaulted.Spec.DeploymentPlan.Image, DefaultImageAnnotation, "Kubernetes")
+		defaultImage(defaulted, &defaulted.Spec.DeploymentPlan.InitImage, DefaultInitImageAnnotation, "Init")
+
+		patch, err := defaultsPatch(defaulted)
+		Expect(err).To(BeNil())
+		Expect(patch).To(BeNil())
+	})
+
+	It("keeps the images set by the user", func() {
+		cr := newCR()
+		cr.Spec.DeploymentPlan.Image = "registry.example.com/broker:custom"
+		cr.Spec.DeploymentPlan.InitImage = "placeholder"
+
+		patch, err := defaultsPatch(cr)
+		Expect(err).To(BeNil())
+
+		decoded := decode(patch)
+		plan := decoded["spec"].(map[string]interface{})["deploymentPlan"].(map[string]interface{})
+		Expect(plan).NotTo(HaveKey("image"))
+		Expect(plan).NotTo(HaveKey("initImage"))
+		Expect(decoded["metadata"]).NotTo(HaveKey("annotations"))
+	})
+
+	It("replaces a stored default image once the resolved image changes", func() {
+		cr := newCR()
+		brokerv2alpha5.SetObjectDefaults_ActiveMQArtemis(cr)
+		defaultImage(cr, &cr.Spec.DeploymentPlan.Image, DefaultImageAnnotation, "Kubernetes")
+		defaultImage(cr, &cr.Spec.DeploymentPlan.InitImage, DefaultInitImageAnnotation, "Init")
+
+		os.Setenv(imageEnv, "quay.io/artemiscloud/broker:2")
+		patch, err := defaultsPatch(cr)
+		Expect(err).To(BeNil())
+
+		decoded := decode(patch)
+		plan := decoded["spec"].(map[string]interface{})["deploymentPlan"].(map[string]interface{})
+		Expect(plan).To(Equal(map[string]interface{}{"image": "quay.io/artemiscloud/broker:2"}))
+		Expect(decoded["metadata"].(map[string]interface{})["annotations"]).To(
+			Equal(map[string]interface{}{DefaultImageAnnotation: "quay.io/artemiscloud/broker:2"}))
+	})
+
+	It("sends a merge patch rather than updating the CR", func() {
+		cr := newCR()
+		clientset := fake.NewSimpleClientset(cr)
+		var patchType types.PatchType
+		clientset.PrependReactor("patch", "activemqartemises", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			patchType = action.(clienttesting.PatchAction).GetPatchType()
+			return true, cr, nil
+		})
+		r := &ReconcileActiveMQArtemis{clientset: clientset}
+
+		updated, err := r.persistDefaults(cr)
+		Expect(err).To(BeNil())
+		Expect(updated).To(BeTrue())
+		Expect(patchType).To(Equal(types.MergePatchType))
+		for _, action := range clientset.Actions() {
+			Expect(action.GetVerb()).NotTo(Equal("update"))
+		}
+	})
+})
@@ -0,0 +1,27 @@
+package v2alpha5activemqartemis
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Reasons of the events recorded on the ActiveMQArtemis CRs
+const (
+	EventReasonStatefulSetRolled = "StatefulSetRolled"
+	EventReasonSecurityApplied   = "SecurityApplied"
+	EventReasonSecurityFailed    = "SecurityFailed"
+
+	EventReasonClusterCertificatesRotated = "ClusterCertificatesRotated"
+	EventReasonConsoleRoleUnknown         = "ConsoleRoleUnknown"
+	EventReasonSSLSecretMissing           = "SSLSecretMissing"
+	EventReasonPodTemplateRejected        = "PodTemplateRejected"
+	EventReasonStorageNotApplied          = "StorageNotApplied"
+)
+
+// recordEvent records an event on the object when the reconciler has a recorder, the
+// fsms made outside the controller have none
+func recordEvent(fsm *ActiveMQArtemisFSM, object runtime.Object, eventType string, reason string, messageFmt string, args ...interface{}) {
+	if fsm.r == nil || fsm.r.recorder == nil {
+		return
+	}
+	fsm.r.recorder.Eventf(object, eventType, reason, messageFmt, args...)
+}
@@ -0,0 +1,131 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"strconv"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// acceptorExposeMode returns how an exposed acceptor is reached from outside the cluster,
+// routes only being available on OpenShift
+func acceptorExposeMode(acceptor brokerv2alpha5.AcceptorType, isOpenshift bool) brokerv2alpha5.ExposeMode {
+	switch acceptor.ExposeMode {
+	case brokerv2alpha5.ExposeModeIngress, brokerv2alpha5.ExposeModeLoadBalancer, brokerv2alpha5.ExposeModeNodePort:
+		return acceptor.ExposeMode
+	case brokerv2alpha5.ExposeModeRoute:
+		if !isOpenshift {
+			log.Info("Acceptor exposed by ingress, routes are only available on OpenShift", "acceptor", acceptor.Name)
+			return brokerv2alpha5.ExposeModeIngress
+		}
+		return acceptor.ExposeMode
+	}
+	if isOpenshift {
+		return brokerv2alpha5.ExposeModeRoute
+	}
+	return brokerv2alpha5.ExposeModeIngress
+}
+
+// exposeServiceType returns the type of the service of an acceptor of a broker
+func exposeServiceType(exposeMode brokerv2alpha5.ExposeMode) corev1.ServiceType {
+	switch exposeMode {
+	case brokerv2alpha5.ExposeModeLoadBalancer:
+		return corev1.ServiceTypeLoadBalancer
+	case brokerv2alpha5.ExposeModeNodePort:
+		return corev1.ServiceTypeNodePort
+	}
+	return corev1.ServiceTypeClusterIP
+}
+
+// requestService adds the service to the requested resources, updating the type of the
+// deployed one when the acceptor changed expose mode
+func requestService(fsm *ActiveMQArtemisFSM, client client.Client, requested *corev1.Service) {
+
+	namespacedName := types.NamespacedName{
+		Name:      requested.Name,
+		Namespace: fsm.customResource.Namespace,
+	}
+	deployed := &corev1.Service{}
+	err := client.Get(context.TODO(), namespacedName, deployed)
+	if err == nil {
+		if deployed.Spec.Type != requested.Spec.Type {
+			log.Info("Service type changed, updating it", "name", namespacedName.Name, "type", requested.Spec.Type)
+			deployed.Spec.Type = requested.Spec.Type
+			if requested.Spec.Type == corev1.ServiceTypeClusterIP {
+				// Only node port and load balancer services may have these set
+				for i := range deployed.Spec.Ports {
+					deployed.Spec.Ports[i].NodePort = 0
+				}
+				deployed.Spec.ExternalTrafficPolicy = ""
+				deployed.Spec.HealthCheckNodePort = 0
+			}
+			if err = resources.Update(namespacedName, client, deployed); err != nil {
+				log.Error(err, "Failed to update service", "name", namespacedName.Name)
+			}
+			return
+		}
+	} else if !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get service", "name", namespacedName.Name)
+	}
+
+	requestedResources = append(requestedResources, requested)
+}
+
+// externalAddresses returns the addresses of the acceptors of each broker exposed by load
+// balancer or node port, the ones not assigned yet being left out
+func externalAddresses(fsm *ActiveMQArtemisFSM, client client.Client) []brokerv2alpha5.ExternalAddress {
+
+	var addresses []brokerv2alpha5.ExternalAddress
+	for i := int32(0); i < fsm.customResource.Spec.DeploymentPlan.Size; i++ {
+		ordinalString := strconv.Itoa(int(i))
+		podName := fsm.GetStatefulSetName() + "-" + ordinalString
+		var pod *corev1.Pod
+
+		for _, acceptor := range fsm.customResource.Spec.Acceptors {
+			if !acceptor.Expose {
+				continue
+			}
+			if acceptor.ExposeMode != brokerv2alpha5.ExposeModeLoadBalancer && acceptor.ExposeMode != brokerv2alpha5.ExposeModeNodePort {
+				continue
+			}
+
+			service := &corev1.Service{}
+			serviceNamespacedName := types.NamespacedName{
+				Name:      fsm.customResource.Name + "-" + acceptor.Name + "-" + ordinalString + "-svc",
+				Namespace: fsm.customResource.Namespace,
+			}
+			if err := client.Get(context.TODO(), serviceNamespacedName, service); err != nil || len(service.Spec.Ports) == 0 {
+				continue
+			}
+			port := service.Spec.Ports[0]
+
+			switch service.Spec.Type {
+			case corev1.ServiceTypeLoadBalancer:
+				for _, ingress := range service.Status.LoadBalancer.Ingress {
+					host := ingress.Hostname
+					if host == "" {
+						host = ingress.IP
+					}
+					addresses = append(addresses, brokerv2alpha5.ExternalAddress{Acceptor: acceptor.Name, Pod: podName, Host: host, Port: port.Port})
+				}
+			case corev1.ServiceTypeNodePort:
+				if pod == nil {
+					pod = &corev1.Pod{}
+					podNamespacedName := types.NamespacedName{Name: podName, Namespace: fsm.customResource.Namespace}
+					if err := client.Get(context.TODO(), podNamespacedName, pod); err != nil {
+						log.V(1).Info("Broker pod not found for its node port address", "pod", podName)
+					}
+				}
+				if pod.Status.HostIP != "" && port.NodePort != 0 {
+					addresses = append(addresses, brokerv2alpha5.ExternalAddress{Acceptor: acceptor.Name, Pod: podName, Host: pod.Status.HostIP, Port: port.NodePort})
+				}
+			}
+		}
+	}
+	return addresses
+}
@@ -0,0 +1,156 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newAcceptorService(name string, serviceType corev1.ServiceType, port int32, nodePort int32) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+		Spec: corev1.ServiceSpec{
+			Type:  serviceType,
+			Ports: []corev1.ServicePort{{Name: "amqp", Port: port, NodePort: nodePort}},
+		},
+	}
+}
+
+func newBrokerPod(name string, hostIP string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+		Status:     corev1.PodStatus{HostIP: hostIP},
+	}
+}
+
+var _ = Describe("Acceptor exposure", func() {
+
+	BeforeEach(func() {
+		requestedResources = nil
+	})
+
+	It("falls back to an ingress for routes outside OpenShift", func() {
+		acceptor := brokerv2alpha5.AcceptorType{Name: "amqp", Expose: true}
+		Expect(acceptorExposeMode(acceptor, true)).To(Equal(brokerv2alpha5.ExposeModeRoute))
+		Expect(acceptorExposeMode(acceptor, false)).To(Equal(brokerv2alpha5.ExposeModeIngress))
+
+		acceptor.ExposeMode = brokerv2alpha5.ExposeModeRoute
+		Expect(acceptorExposeMode(acceptor, false)).To(Equal(brokerv2alpha5.ExposeModeIngress))
+
+		acceptor.ExposeMode = brokerv2alpha5.ExposeModeNodePort
+		Expect(acceptorExposeMode(acceptor, true)).To(Equal(brokerv2alpha5.ExposeModeNodePort))
+		Expect(exposeServiceType(acceptor.ExposeMode)).To(Equal(corev1.ServiceTypeNodePort))
+		Expect(exposeServiceType(brokerv2alpha5.ExposeModeLoadBalancer)).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(exposeServiceType(brokerv2alpha5.ExposeModeIngress)).To(Equal(corev1.ServiceTypeClusterIP))
+	})
+
+	Context("requesting the service of an acceptor", func() {
+
+		name := types.NamespacedName{Name: "ex-aao-amqp-0-svc", Namespace: "test"}
+
+		It("requests a service that is not deployed", func() {
+			requested := newAcceptorService(name.Name, corev1.ServiceTypeLoadBalancer, 5672, 0)
+
+			requestService(newTestFSM(newTestCR(), nil), fake.NewFakeClient(), requested)
+
+			Expect(requestedResources).To(ConsistOf(requested))
+		})
+
+		It("requests the deployed service when its type is unchanged", func() {
+			deployed := newAcceptorService(name.Name, corev1.ServiceTypeNodePort, 5672, 30672)
+			requested := newAcceptorService(name.Name, corev1.ServiceTypeNodePort, 5672, 0)
+
+			requestService(newTestFSM(newTestCR(), nil), fake.NewFakeClient(deployed), requested)
+
+			Expect(requestedResources).To(ConsistOf(requested))
+		})
+
+		It("updates the type of the deployed service", func() {
+			c := fake.NewFakeClient(newAcceptorService(name.Name, corev1.ServiceTypeClusterIP, 5672, 0))
+
+			requestService(newTestFSM(newTestCR(), nil), c, newAcceptorService(name.Name, corev1.ServiceTypeLoadBalancer, 5672, 0))
+
+			Expect(requestedResources).To(BeEmpty())
+			updated := &corev1.Service{}
+			Expect(c.Get(context.TODO(), name, updated)).To(Succeed())
+			Expect(updated.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		})
+
+		It("clears the node ports when going back to a cluster ip", func() {
+			deployed := newAcceptorService(name.Name, corev1.ServiceTypeNodePort, 5672, 30672)
+			deployed.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeLocal
+			deployed.Spec.HealthCheckNodePort = 31000
+			c := fake.NewFakeClient(deployed)
+
+			requestService(newTestFSM(newTestCR(), nil), c, newAcceptorService(name.Name, corev1.ServiceTypeClusterIP, 5672, 0))
+
+			updated := &corev1.Service{}
+			Expect(c.Get(context.TODO(), name, updated)).To(Succeed())
+			Expect(updated.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			Expect(updated.Spec.Ports[0].NodePort).To(BeZero())
+			Expect(updated.Spec.ExternalTrafficPolicy).To(BeEmpty())
+			Expect(updated.Spec.HealthCheckNodePort).To(BeZero())
+		})
+	})
+
+	Context("reporting the external addresses", func() {
+
+		newExposedCR := func(size int32) *brokerv2alpha5.ActiveMQArtemis {
+			cr := newTestCR()
+			cr.Spec.DeploymentPlan.Size = size
+			cr.Spec.Acceptors = []brokerv2alpha5.AcceptorType{
+				{Name: "amqp", Port: 5672, Expose: true, ExposeMode: brokerv2alpha5.ExposeModeLoadBalancer},
+				{Name: "core", Port: 61616, Expose: true, ExposeMode: brokerv2alpha5.ExposeModeNodePort},
+				{Name: "mqtt", Port: 1883, Expose: true, ExposeMode: brokerv2alpha5.ExposeModeIngress},
+				{Name: "stomp", Port: 61613, ExposeMode: brokerv2alpha5.ExposeModeNodePort},
+			}
+			return cr
+		}
+
+		It("reports the load balancer ingress and the node ports of each broker", func() {
+			balanced := newAcceptorService("ex-aao-amqp-0-svc", corev1.ServiceTypeLoadBalancer, 5672, 0)
+			balanced.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "203.0.113.10"}}
+			balancedHost := newAcceptorService("ex-aao-amqp-1-svc", corev1.ServiceTypeLoadBalancer, 5672, 0)
+			balancedHost.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "amqp-1.example.com", IP: "203.0.113.11"}}
+			c := fake.NewFakeClient(
+				balanced,
+				balancedHost,
+				newAcceptorService("ex-aao-core-0-svc", corev1.ServiceTypeNodePort, 61616, 30616),
+				newAcceptorService("ex-aao-core-1-svc", corev1.ServiceTypeNodePort, 61616, 31616),
+				newAcceptorService("ex-aao-mqtt-0-svc", corev1.ServiceTypeClusterIP, 1883, 0),
+				newAcceptorService("ex-aao-stomp-0-svc", corev1.ServiceTypeNodePort, 61613, 30613),
+				newBrokerPod("ex-aao-ss-0", "192.0.2.1"),
+				newBrokerPod("ex-aao-ss-1", "192.0.2.2"),
+			)
+
+			Expect(externalAddresses(newTestFSM(newExposedCR(2), nil), c)).To(Equal([]brokerv2alpha5.ExternalAddress{
+				{Acceptor: "amqp", Pod: "ex-aao-ss-0", Host: "203.0.113.10", Port: 5672},
+				{Acceptor: "core", Pod: "ex-aao-ss-0", Host: "192.0.2.1", Port: 30616},
+				{Acceptor: "amqp", Pod: "ex-aao-ss-1", Host: "amqp-1.example.com", Port: 5672},
+				{Acceptor: "core", Pod: "ex-aao-ss-1", Host: "192.0.2.2", Port: 31616},
+			}))
+		})
+
+		It("leaves out the addresses not assigned yet", func() {
+			c := fake.NewFakeClient(
+				newAcceptorService("ex-aao-amqp-0-svc", corev1.ServiceTypeLoadBalancer, 5672, 0),
+				newAcceptorService("ex-aao-core-0-svc", corev1.ServiceTypeNodePort, 61616, 30616),
+				newBrokerPod("ex-aao-ss-0", ""),
+			)
+
+			Expect(externalAddresses(newTestFSM(newExposedCR(1), nil), c)).To(BeEmpty())
+		})
+
+		It("leaves out the brokers whose services or pods are not found", func() {
+			c := fake.NewFakeClient(newAcceptorService("ex-aao-core-0-svc", corev1.ServiceTypeNodePort, 61616, 30616))
+
+			Expect(externalAddresses(newTestFSM(newExposedCR(1), nil), c)).To(BeEmpty())
+		})
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/fsm"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/namer"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/selectors"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -59,16 +60,25 @@ const (
 )
 
 type Namers struct {
-	SsGlobalName                  string
-	SsNameBuilder                 namer.NamerData
-	SvcHeadlessNameBuilder        namer.NamerData
-	SvcPingNameBuilder            namer.NamerData
-	PodsNameBuilder               namer.NamerData
-	SecretsCredentialsNameBuilder namer.NamerData
-	SecretsConsoleNameBuilder     namer.NamerData
-	SecretsNettyNameBuilder       namer.NamerData
-	LabelBuilder                  selectors.LabelerData
-	GLOBAL_DATA_PATH              string
+	SsGlobalName                   string
+	SsNameBuilder                  namer.NamerData
+	SvcHeadlessNameBuilder         namer.NamerData
+	SvcPingNameBuilder             namer.NamerData
+	PodsNameBuilder                namer.NamerData
+	SecretsCredentialsNameBuilder  namer.NamerData
+	SecretsConsoleNameBuilder      namer.NamerData
+	SecretsNettyNameBuilder        namer.NamerData
+	PdbNameBuilder                 namer.NamerData
+	HpaNameBuilder                 namer.NamerData
+	SvcMetricsNameBuilder          namer.NamerData
+	SmNameBuilder                  namer.NamerData
+	AlertsNameBuilder              namer.NamerData
+	SecretsClusterTLSNameBuilder   namer.NamerData
+	SecretsClusterCANameBuilder    namer.NamerData
+	SecretsConsoleOAuthNameBuilder namer.NamerData
+	SaConsoleOAuthNameBuilder      namer.NamerData
+	LabelBuilder                   selectors.LabelerData
+	GLOBAL_DATA_PATH               string
 }
 
 type ActiveMQArtemisFSM struct {
@@ -79,9 +89,12 @@ type ActiveMQArtemisFSM struct {
 	r                  *ReconcileActiveMQArtemis
 	namers             *Namers
 	podInvalid         bool
+	// claim templates of the deployed statefulset, the data volumes of the brokers follow
+	// them as the claims can't change
+	deployedClaimTemplates []corev1.PersistentVolumeClaim
 }
 
-//used for persistence of fsm
+// used for persistence of fsm
 type ActiveMQArtemisFSMData struct {
 	MCurrentStateID                        int   `json:"mcurrentstateid,omitempty"`
 	MNextStateID                           int   `json:"mnextstateid,omitempty"`
@@ -193,16 +206,25 @@ func (amqbfsm *ActiveMQArtemisFSM) GetFSMData() *ActiveMQArtemisFSMData {
 
 func (amqbfsm *ActiveMQArtemisFSM) MakeNamers() *Namers {
 	newNamers := Namers{
-		SsGlobalName:                  "",
-		SsNameBuilder:                 namer.NamerData{},
-		SvcHeadlessNameBuilder:        namer.NamerData{},
-		SvcPingNameBuilder:            namer.NamerData{},
-		PodsNameBuilder:               namer.NamerData{},
-		SecretsCredentialsNameBuilder: namer.NamerData{},
-		SecretsConsoleNameBuilder:     namer.NamerData{},
-		SecretsNettyNameBuilder:       namer.NamerData{},
-		LabelBuilder:                  selectors.LabelerData{},
-		GLOBAL_DATA_PATH:              "/opt/" + amqbfsm.customResource.Name + "/data",
+		SsGlobalName:                   "",
+		SsNameBuilder:                  namer.NamerData{},
+		SvcHeadlessNameBuilder:         namer.NamerData{},
+		SvcPingNameBuilder:             namer.NamerData{},
+		PodsNameBuilder:                namer.NamerData{},
+		SecretsCredentialsNameBuilder:  namer.NamerData{},
+		SecretsConsoleNameBuilder:      namer.NamerData{},
+		SecretsNettyNameBuilder:        namer.NamerData{},
+		PdbNameBuilder:                 namer.NamerData{},
+		HpaNameBuilder:                 namer.NamerData{},
+		SvcMetricsNameBuilder:          namer.NamerData{},
+		SmNameBuilder:                  namer.NamerData{},
+		AlertsNameBuilder:              namer.NamerData{},
+		SecretsClusterTLSNameBuilder:   namer.NamerData{},
+		SecretsClusterCANameBuilder:    namer.NamerData{},
+		SecretsConsoleOAuthNameBuilder: namer.NamerData{},
+		SaConsoleOAuthNameBuilder:      namer.NamerData{},
+		LabelBuilder:                   selectors.LabelerData{},
+		GLOBAL_DATA_PATH:               "/opt/" + amqbfsm.customResource.Name + "/data",
 	}
 	newNamers.SsNameBuilder.Base(amqbfsm.customResource.Name).Suffix("ss").Generate()
 	newNamers.SsGlobalName = amqbfsm.customResource.Name
@@ -212,6 +234,15 @@ func (amqbfsm *ActiveMQArtemisFSM) MakeNamers() *Namers {
 	newNamers.SecretsCredentialsNameBuilder.Prefix(amqbfsm.customResource.Name).Base("credentials").Suffix("secret").Generate()
 	newNamers.SecretsConsoleNameBuilder.Prefix(amqbfsm.customResource.Name).Base("console").Suffix("secret").Generate()
 	newNamers.SecretsNettyNameBuilder.Prefix(amqbfsm.customResource.Name).Base("netty").Suffix("secret").Generate()
+	newNamers.PdbNameBuilder.Base(amqbfsm.customResource.Name).Suffix("pdb").Generate()
+	newNamers.HpaNameBuilder.Base(amqbfsm.customResource.Name).Suffix("hpa").Generate()
+	newNamers.SvcMetricsNameBuilder.Prefix(amqbfsm.customResource.Name).Base("metrics").Suffix("svc").Generate()
+	newNamers.SmNameBuilder.Base(amqbfsm.customResource.Name).Suffix("sm").Generate()
+	newNamers.AlertsNameBuilder.Base(amqbfsm.customResource.Name).Suffix("alerts").Generate()
+	newNamers.SecretsClusterTLSNameBuilder.Prefix(amqbfsm.customResource.Name).Base("cluster-tls").Suffix("secret").Generate()
+	newNamers.SecretsClusterCANameBuilder.Prefix(amqbfsm.customResource.Name).Base("cluster-ca").Suffix("secret").Generate()
+	newNamers.SecretsConsoleOAuthNameBuilder.Prefix(amqbfsm.customResource.Name).Base("console-oauth").Suffix("secret").Generate()
+	newNamers.SaConsoleOAuthNameBuilder.Prefix(amqbfsm.customResource.Name).Base("console-oauth").Suffix("sa").Generate()
 	newNamers.LabelBuilder.Base(amqbfsm.customResource.Name).Suffix("app").Generate()
 
 	return &newNamers
@@ -287,9 +318,11 @@ func (amqbfsm *ActiveMQArtemisFSM) Enter(startStateID int) error {
 	// For the moment sequentially set stuff up
 	// k8s resource creation and broker environment configuration can probably be done concurrently later
 	amqbfsm.r.result = reconcile.Result{}
+	amqbfsm.updateValidCondition()
 	if err = amqbfsm.m.Enter(CreatingK8sResourcesID); nil != err {
 		err, _ = amqbfsm.m.Update()
 	}
+	UpdateStatus(amqbfsm)
 
 	return err
 }
@@ -305,9 +338,9 @@ func (amqbfsm *ActiveMQArtemisFSM) Update() (error, int) {
 
 	// Was the current state complete?
 	amqbfsm.r.result = reconcile.Result{}
+	amqbfsm.updateValidCondition()
 	err, nextStateID := amqbfsm.m.Update()
-	ssNamespacedName := types.NamespacedName{Name: amqbfsm.namers.SsNameBuilder.Name(), Namespace: amqbfsm.customResource.Namespace}
-	UpdatePodStatus(amqbfsm.customResource, amqbfsm.r.client, ssNamespacedName)
+	UpdateStatus(amqbfsm)
 
 	return err, nextStateID
 }
@@ -348,6 +381,42 @@ func (amqbfsm *ActiveMQArtemisFSM) GetConsoleSecretName() string {
 	return amqbfsm.namers.SecretsConsoleNameBuilder.Name()
 }
 
+func (amqbfsm *ActiveMQArtemisFSM) GetHorizontalPodAutoscalerName() string {
+	return amqbfsm.namers.HpaNameBuilder.Name()
+}
+
+func (amqbfsm *ActiveMQArtemisFSM) GetMetricsServiceName() string {
+	return amqbfsm.namers.SvcMetricsNameBuilder.Name()
+}
+
+func (amqbfsm *ActiveMQArtemisFSM) GetPodDisruptionBudgetName() string {
+	return amqbfsm.namers.PdbNameBuilder.Name()
+}
+
+func (amqbfsm *ActiveMQArtemisFSM) GetServiceMonitorName() string {
+	return amqbfsm.namers.SmNameBuilder.Name()
+}
+
+func (amqbfsm *ActiveMQArtemisFSM) GetPrometheusRuleName() string {
+	return amqbfsm.namers.AlertsNameBuilder.Name()
+}
+
+func (amqbfsm *ActiveMQArtemisFSM) GetClusterTLSSecretName() string {
+	return amqbfsm.namers.SecretsClusterTLSNameBuilder.Name()
+}
+
+func (amqbfsm *ActiveMQArtemisFSM) GetClusterCASecretName() string {
+	return amqbfsm.namers.SecretsClusterCANameBuilder.Name()
+}
+
+func (amqbfsm *ActiveMQArtemisFSM) GetConsoleOAuthSecretName() string {
+	return amqbfsm.namers.SecretsConsoleOAuthNameBuilder.Name()
+}
+
+func (amqbfsm *ActiveMQArtemisFSM) GetConsoleOAuthServiceAccountName() string {
+	return amqbfsm.namers.SaConsoleOAuthNameBuilder.Name()
+}
+
 func (amqbfsm *ActiveMQArtemisFSM) GetStatefulSetName() string {
 	return amqbfsm.namers.SsNameBuilder.Name()
 }
@@ -0,0 +1,114 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/horizontalpodautoscalers"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The cpu utilization the api server defaults an autoscaler without metrics to
+const defaultTargetCPUUtilizationPercentage int32 = 80
+
+// ProcessHorizontalPodAutoscaler adds the horizontal pod autoscaler of the deployment plan
+// to the requested resources. The autoscaler scales the custom resource rather than the
+// statefulset, so that scaling down goes through the message migration of the operator.
+func (reconciler *ActiveMQArtemisReconciler) ProcessHorizontalPodAutoscaler(fsm *ActiveMQArtemisFSM, client client.Client) {
+
+	requested := NewHorizontalPodAutoscalerForCR(fsm)
+
+	namespacedName := types.NamespacedName{
+		Name:      fsm.GetHorizontalPodAutoscalerName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	deployed := &autoscalingv2beta1.HorizontalPodAutoscaler{}
+	err := client.Get(context.TODO(), namespacedName, deployed)
+	if err == nil {
+		if requested == nil {
+			log.Info("Horizontal pod autoscaler is no longer requested, deleting it", "name", namespacedName.Name)
+			if err = resources.Delete(namespacedName, client, deployed); err != nil {
+				log.Error(err, "Failed to delete horizontal pod autoscaler", "name", namespacedName.Name)
+			}
+			return
+		}
+		if !equality.Semantic.DeepEqual(deployed.Spec, requested.Spec) {
+			log.Info("Horizontal pod autoscaler changed, updating it", "name", namespacedName.Name)
+			deployed.Spec = requested.Spec
+			if err = resources.Update(namespacedName, client, deployed); err != nil {
+				log.Error(err, "Failed to update horizontal pod autoscaler", "name", namespacedName.Name)
+			}
+			return
+		}
+	} else if !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get horizontal pod autoscaler", "name", namespacedName.Name)
+	}
+
+	if requested != nil {
+		requestedResources = append(requestedResources, requested)
+	}
+}
+
+// NewHorizontalPodAutoscalerForCR returns the horizontal pod autoscaler of the deployment
+// plan, nil when the brokers are not autoscaled. Defaults are set as the api server would
+// so that the deployed autoscaler compares equal.
+func NewHorizontalPodAutoscalerForCR(fsm *ActiveMQArtemisFSM) *autoscalingv2beta1.HorizontalPodAutoscaler {
+
+	autoscaling := fsm.customResource.Spec.DeploymentPlan.Autoscaling
+	if autoscaling == nil {
+		return nil
+	}
+
+	minReplicas := int32(1)
+	if autoscaling.MinReplicas != nil {
+		minReplicas = *autoscaling.MinReplicas
+	}
+
+	var metrics []autoscalingv2beta1.MetricSpec
+	for _, metric := range autoscaling.Metrics {
+		targetAverageValue, err := resource.ParseQuantity(metric.TargetAverageValue)
+		if err != nil {
+			log.Error(err, "Broker metric not scaled on, invalid target average value", "metric", metric.Name)
+			continue
+		}
+		metrics = append(metrics, autoscalingv2beta1.MetricSpec{
+			Type: autoscalingv2beta1.PodsMetricSourceType,
+			Pods: &autoscalingv2beta1.PodsMetricSource{
+				MetricName:         metric.Name,
+				TargetAverageValue: targetAverageValue,
+			},
+		})
+	}
+	targetCPUUtilizationPercentage := autoscaling.TargetCPUUtilizationPercentage
+	if targetCPUUtilizationPercentage == nil && len(metrics) == 0 {
+		defaultPercentage := defaultTargetCPUUtilizationPercentage
+		targetCPUUtilizationPercentage = &defaultPercentage
+	}
+	if targetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, autoscalingv2beta1.MetricSpec{
+			Type: autoscalingv2beta1.ResourceMetricSourceType,
+			Resource: &autoscalingv2beta1.ResourceMetricSource{
+				Name:                     corev1.ResourceCPU,
+				TargetAverageUtilization: targetCPUUtilizationPercentage,
+			},
+		})
+	}
+
+	namespacedName := types.NamespacedName{
+		Name:      fsm.GetHorizontalPodAutoscalerName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	target := autoscalingv2beta1.CrossVersionObjectReference{
+		APIVersion: brokerv2alpha5.SchemeGroupVersion.String(),
+		Kind:       "ActiveMQArtemis",
+		Name:       fsm.customResource.Name,
+	}
+	return horizontalpodautoscalers.NewHorizontalPodAutoscalerForCR(namespacedName, fsm.namers.LabelBuilder.Labels(), target, &minReplicas, autoscaling.MaxReplicas, metrics)
+}
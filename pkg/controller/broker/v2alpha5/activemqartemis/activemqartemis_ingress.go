@@ -0,0 +1,68 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/ingresses"
+	extv1b1 "k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// requestIngress adds the ingress to the requested resources, updating the deployed one
+// when its host, class, annotations or tls changed, which the requested resources are not
+// compared on
+func requestIngress(fsm *ActiveMQArtemisFSM, client client.Client, requested *extv1b1.Ingress) {
+
+	namespacedName := types.NamespacedName{
+		Name:      requested.Name,
+		Namespace: fsm.customResource.Namespace,
+	}
+	deployed := &extv1b1.Ingress{}
+	err := client.Get(context.TODO(), namespacedName, deployed)
+	if err == nil {
+		if !equality.Semantic.DeepEqual(deployed.Spec, requested.Spec) || !equality.Semantic.DeepEqual(deployed.Annotations, requested.Annotations) {
+			log.Info("Ingress changed, updating it", "name", namespacedName.Name)
+			deployed.Spec = requested.Spec
+			deployed.Annotations = requested.Annotations
+			if err = resources.Update(namespacedName, client, deployed); err != nil {
+				log.Error(err, "Failed to update ingress", "name", namespacedName.Name)
+			}
+			return
+		}
+	} else if !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get ingress", "name", namespacedName.Name)
+	}
+
+	requestedResources = append(requestedResources, requested)
+}
+
+// ingressOptions returns the ingress options of an acceptor or the console of a broker,
+// with the placeholders of the host replaced
+func ingressOptions(fsm *ActiveMQArtemisFSM, itemName string, ordinal string, host string, className string, annotations map[string]string, tlsSecret string) ingresses.IngressOptions {
+	return ingresses.IngressOptions{
+		Host:        ingressHost(fsm, itemName, ordinal, host),
+		ClassName:   className,
+		Annotations: annotations,
+		TLSSecret:   tlsSecret,
+	}
+}
+
+func ingressHost(fsm *ActiveMQArtemisFSM, itemName string, ordinal string, host string) string {
+	return strings.NewReplacer(
+		"$(CR_NAME)", fsm.customResource.Name,
+		"$(CR_NAMESPACE)", fsm.customResource.Namespace,
+		"$(BROKER_ORDINAL)", ordinal,
+		"$(ITEM_NAME)", itemName,
+	).Replace(host)
+}
+
+// isIngressConfigured tells whether any of the ingress fields are set, the ingress of an
+// exposed console having a default tls otherwise
+func isIngressConfigured(host string, className string, annotations map[string]string, tlsSecret string) bool {
+	return host != "" || className != "" || len(annotations) > 0 || tlsSecret != ""
+}
@@ -0,0 +1,58 @@
+package v2alpha5activemqartemis
+
+import (
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/ingresses"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Ingress", func() {
+
+	namespacedName := types.NamespacedName{Name: "ex-aao", Namespace: "test"}
+
+	It("leaves the host of the default console ingress unset", func() {
+		ingress := ingresses.NewIngressForCRWithSSL(namespacedName, nil, "ex-aao-wconsj-0-svc", "wconsj-0", true)
+
+		Expect(ingress.Spec.Rules).To(HaveLen(1))
+		Expect(ingress.Spec.Rules[0].Host).To(BeEmpty())
+		Expect(ingress.Spec.TLS).To(HaveLen(1))
+		Expect(ingress.Spec.TLS[0].Hosts).To(BeEmpty())
+	})
+
+	It("replaces the placeholders of the host", func() {
+		fsm := newTestFSM(newTestCR(), nil)
+		annotations := map[string]string{"nginx.ingress.kubernetes.io/ssl-passthrough": "true"}
+
+		options := ingressOptions(fsm, "console", "1", "$(ITEM_NAME)-$(BROKER_ORDINAL).$(CR_NAME).$(CR_NAMESPACE).example.com", "", annotations, "console-tls")
+		ingress := ingresses.NewIngressForCRWithOptions(namespacedName, nil, "ex-aao-wconsj-1-svc", "wconsj-1", options)
+
+		Expect(ingress.Spec.Rules[0].Host).To(Equal("console-1.ex-aao.test.example.com"))
+		Expect(ingress.Annotations).To(Equal(annotations))
+		Expect(ingress.Spec.TLS).To(HaveLen(1))
+		Expect(ingress.Spec.TLS[0].SecretName).To(Equal("console-tls"))
+		Expect(ingress.Spec.TLS[0].Hosts).To(ConsistOf("console-1.ex-aao.test.example.com"))
+	})
+
+	It("sets the class name as the ingress class annotation", func() {
+		fsm := newTestFSM(newTestCR(), nil)
+		annotations := map[string]string{"nginx.ingress.kubernetes.io/ssl-passthrough": "true"}
+
+		options := ingressOptions(fsm, "amqp", "0", "", "nginx", annotations, "")
+		ingress := ingresses.NewIngressForCRWithOptions(namespacedName, nil, "ex-aao-amqp-0-svc", "amqp-0", options)
+
+		Expect(ingress.Annotations).To(Equal(map[string]string{
+			"nginx.ingress.kubernetes.io/ssl-passthrough": "true",
+			ingresses.IngressClassAnnotation:              "nginx",
+		}))
+		Expect(annotations).To(HaveLen(1))
+	})
+
+	It("tells whether the ingress of the console is configured", func() {
+		Expect(isIngressConfigured("", "", nil, "")).To(BeFalse())
+		Expect(isIngressConfigured("console.example.com", "", nil, "")).To(BeTrue())
+		Expect(isIngressConfigured("", "nginx", nil, "")).To(BeTrue())
+		Expect(isIngressConfigured("", "", map[string]string{"nginx.ingress.kubernetes.io/ssl-passthrough": "true"}, "")).To(BeTrue())
+		Expect(isIngressConfigured("", "", nil, "console-tls")).To(BeTrue())
+	})
+})
@@ -0,0 +1,268 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/prometheusrules"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/servicemonitors"
+	svc "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/services"
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// The metrics plugin is served by the web server of the broker, along with the console
+	metricsPort int32 = 8161
+	metricsPath       = "/metrics/"
+
+	defaultAddressMemoryUsagePercentage int32 = 90
+)
+
+// ProcessMonitoring adds the metrics service of the brokers to the requested resources and
+// syncs their service monitor and prometheus rule. The lists of the prometheus operator
+// resources hold pointers, which the deployed resources cannot be read from, so these are
+// created, updated and deleted here rather than through the requested resources.
+func (reconciler *ActiveMQArtemisReconciler) ProcessMonitoring(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme) {
+
+	monitoring := fsm.customResource.Spec.Monitoring
+	enabled := monitoring != nil && monitoring.Enabled
+	if enabled && !isMetricsPluginEnabled(fsm) {
+		log.Info("Monitoring not enabled, it needs the metrics plugin enabled in the deployment plan")
+		enabled = false
+	}
+	alertsEnabled := enabled && monitoring.Alerts != nil && monitoring.Alerts.Enabled
+
+	serviceNamespacedName := types.NamespacedName{
+		Name:      fsm.GetMetricsServiceName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	if enabled {
+		requestedResources = append(requestedResources, NewMetricsServiceForCR(fsm))
+	} else {
+		deployed := &corev1.Service{}
+		if err := client.Get(context.TODO(), serviceNamespacedName, deployed); err == nil {
+			log.Info("Metrics service is no longer requested, deleting it", "name", serviceNamespacedName.Name)
+			resources.Delete(serviceNamespacedName, client, deployed)
+		}
+	}
+
+	var serviceMonitor *monitoringv1.ServiceMonitor
+	if enabled {
+		serviceMonitor = NewServiceMonitorForCR(fsm)
+	}
+	syncServiceMonitor(fsm, client, scheme, serviceMonitor)
+
+	var prometheusRule *monitoringv1.PrometheusRule
+	if alertsEnabled {
+		prometheusRule = NewPrometheusRuleForCR(fsm)
+	}
+	syncPrometheusRule(fsm, client, scheme, prometheusRule)
+}
+
+func isMetricsPluginEnabled(fsm *ActiveMQArtemisFSM) bool {
+	enableMetricsPlugin := fsm.customResource.Spec.DeploymentPlan.EnableMetricsPlugin
+	return enableMetricsPlugin != nil && *enableMetricsPlugin
+}
+
+func syncServiceMonitor(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, requested *monitoringv1.ServiceMonitor) {
+
+	namespacedName := types.NamespacedName{
+		Name:      fsm.GetServiceMonitorName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	deployed := &monitoringv1.ServiceMonitor{}
+	err := client.Get(context.TODO(), namespacedName, deployed)
+	switch {
+	case meta.IsNoMatchError(err):
+		if requested != nil {
+			log.Info("Service monitor not created, the prometheus operator is not installed", "name", namespacedName.Name)
+		}
+	case errors.IsNotFound(err):
+		if requested != nil {
+			resources.Create(fsm.customResource, namespacedName, client, scheme, requested)
+		}
+	case err != nil:
+		log.Error(err, "Failed to get service monitor", "name", namespacedName.Name)
+	case requested == nil:
+		log.Info("Service monitor is no longer requested, deleting it", "name", namespacedName.Name)
+		resources.Delete(namespacedName, client, deployed)
+	case !equality.Semantic.DeepEqual(deployed.Spec, requested.Spec) || !equality.Semantic.DeepEqual(deployed.Labels, requested.Labels):
+		log.Info("Service monitor changed, updating it", "name", namespacedName.Name)
+		deployed.Spec = requested.Spec
+		deployed.Labels = requested.Labels
+		resources.Update(namespacedName, client, deployed)
+	}
+}
+
+func syncPrometheusRule(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, requested *monitoringv1.PrometheusRule) {
+
+	namespacedName := types.NamespacedName{
+		Name:      fsm.GetPrometheusRuleName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	deployed := &monitoringv1.PrometheusRule{}
+	err := client.Get(context.TODO(), namespacedName, deployed)
+	switch {
+	case meta.IsNoMatchError(err):
+		if requested != nil {
+			log.Info("Prometheus rule not created, the prometheus operator is not installed", "name", namespacedName.Name)
+		}
+	case errors.IsNotFound(err):
+		if requested != nil {
+			resources.Create(fsm.customResource, namespacedName, client, scheme, requested)
+		}
+	case err != nil:
+		log.Error(err, "Failed to get prometheus rule", "name", namespacedName.Name)
+	case requested == nil:
+		log.Info("Prometheus rule is no longer requested, deleting it", "name", namespacedName.Name)
+		resources.Delete(namespacedName, client, deployed)
+	case !equality.Semantic.DeepEqual(deployed.Spec, requested.Spec) || !equality.Semantic.DeepEqual(deployed.Labels, requested.Labels):
+		log.Info("Prometheus rule changed, updating it", "name", namespacedName.Name)
+		deployed.Spec = requested.Spec
+		deployed.Labels = requested.Labels
+		resources.Update(namespacedName, client, deployed)
+	}
+}
+
+// NewMetricsServiceForCR returns the service of the metrics endpoint of the brokers
+func NewMetricsServiceForCR(fsm *ActiveMQArtemisFSM) *corev1.Service {
+	namespacedName := types.NamespacedName{
+		Name:      fsm.customResource.Name,
+		Namespace: fsm.customResource.Namespace,
+	}
+	labels := fsm.namers.LabelBuilder.Labels()
+	return svc.NewServiceDefinitionForCR(namespacedName, "metrics", metricsPort, labels, labels)
+}
+
+// NewServiceMonitorForCR returns the service monitor scraping the metrics service of the
+// brokers with the admin credentials, over https when the console is secured
+func NewServiceMonitorForCR(fsm *ActiveMQArtemisFSM) *monitoringv1.ServiceMonitor {
+	monitoring := fsm.customResource.Spec.Monitoring
+	credentials := corev1.LocalObjectReference{Name: fsm.GetCredentialsSecretName()}
+	targetPort := intstr.FromInt(int(metricsPort))
+	endpoint := monitoringv1.Endpoint{
+		Port:       "metrics",
+		TargetPort: &targetPort,
+		Path:       metricsPath,
+		Scheme:     "http",
+		Interval:   monitoring.Interval,
+		BasicAuth: &monitoringv1.BasicAuth{
+			Username: corev1.SecretKeySelector{LocalObjectReference: credentials, Key: "AMQ_USER"},
+			Password: corev1.SecretKeySelector{LocalObjectReference: credentials, Key: "AMQ_PASSWORD"},
+		},
+	}
+	if fsm.customResource.Spec.Console.SSLEnabled {
+		endpoint.Scheme = "https"
+		// The brokers are scraped by pod ip, which their certificates are not issued for
+		endpoint.TLSConfig = &monitoringv1.TLSConfig{InsecureSkipVerify: true}
+	}
+
+	namespacedName := types.NamespacedName{
+		Name:      fsm.GetServiceMonitorName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	return servicemonitors.NewServiceMonitorForCR(namespacedName, monitoringLabels(fsm), fsm.namers.LabelBuilder.Labels(), endpoint)
+}
+
+// NewPrometheusRuleForCR returns the prometheus rule with the default alerts of the
+// brokers: fewer brokers up than the deployment has, the address memory near full, a dead
+// letter queue growing and messages on a queue without consumers
+func NewPrometheusRuleForCR(fsm *ActiveMQArtemisFSM) *monitoringv1.PrometheusRule {
+	alerts := fsm.customResource.Spec.Monitoring.Alerts
+	addressMemoryUsagePercentage := defaultAddressMemoryUsagePercentage
+	if alerts.AddressMemoryUsagePercentage != nil {
+		addressMemoryUsagePercentage = *alerts.AddressMemoryUsagePercentage
+	}
+
+	size := fsm.customResource.Spec.DeploymentPlan.Size
+
+	// The targets of the metrics service of this broker deployment
+	selector := fmt.Sprintf(`namespace="%s",service="%s"`, fsm.customResource.Namespace, fsm.GetMetricsServiceName())
+	deadLetterQueues := `queue=~"DLQ.*"`
+	internalQueues := `queue!~"DLQ.*|ExpiryQueue.*"`
+
+	group := monitoringv1.RuleGroup{
+		Name: fsm.customResource.Name + ".rules",
+		Rules: []monitoringv1.Rule{
+			{
+				// a broker pod that is not ready is no longer a target, it has no up series
+				// at all, so the targets up are counted against the size of the deployment
+				Alert: "ArtemisBrokerDown",
+				Expr:  intstr.FromString("(count(up{" + selector + "} == 1) or vector(0)) < " + strconv.Itoa(int(size))),
+				For:   "5m",
+				Labels: map[string]string{
+					"severity": "critical",
+				},
+				Annotations: map[string]string{
+					"summary":     "Brokers of " + fsm.customResource.Name + " are down",
+					"description": "Only {{ $value }} of the " + strconv.Itoa(int(size)) + " brokers of " + fsm.customResource.Name + " could be scraped for 5 minutes.",
+				},
+			},
+			{
+				Alert: "ArtemisAddressMemoryNearFull",
+				Expr:  intstr.FromString("artemis_address_memory_usage_percentage{" + selector + "} > " + strconv.Itoa(int(addressMemoryUsagePercentage))),
+				For:   "5m",
+				Labels: map[string]string{
+					"severity": "warning",
+				},
+				Annotations: map[string]string{
+					"summary":     "Address memory of broker {{ $labels.pod }} is near full",
+					"description": "The addresses of broker {{ $labels.pod }} use {{ $value }}% of the global max size, they page, block or drop messages once it is full.",
+				},
+			},
+			{
+				Alert: "ArtemisDeadLetterQueueGrowing",
+				Expr:  intstr.FromString("delta(artemis_message_count{" + selector + "," + deadLetterQueues + "}[15m]) > 0"),
+				Labels: map[string]string{
+					"severity": "warning",
+				},
+				Annotations: map[string]string{
+					"summary":     "Dead letter queue {{ $labels.queue }} of broker {{ $labels.pod }} is growing",
+					"description": "Messages that could not be delivered were sent to dead letter queue {{ $labels.queue }} in the last 15 minutes.",
+				},
+			},
+			{
+				Alert: "ArtemisQueueWithoutConsumers",
+				Expr: intstr.FromString("artemis_message_count{" + selector + "," + internalQueues + "} > 0" +
+					" and artemis_consumer_count{" + selector + "," + internalQueues + "} == 0"),
+				For: "10m",
+				Labels: map[string]string{
+					"severity": "warning",
+				},
+				Annotations: map[string]string{
+					"summary":     "Queue {{ $labels.queue }} of broker {{ $labels.pod }} has messages and no consumers",
+					"description": "Queue {{ $labels.queue }} has held {{ $value }} messages without consumers for 10 minutes.",
+				},
+			},
+		},
+	}
+
+	namespacedName := types.NamespacedName{
+		Name:      fsm.GetPrometheusRuleName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	return prometheusrules.NewPrometheusRuleForCR(namespacedName, monitoringLabels(fsm), []monitoringv1.RuleGroup{group})
+}
+
+// monitoringLabels returns the labels of the brokers along with those a prometheus selects
+// the service monitor and prometheus rule by
+func monitoringLabels(fsm *ActiveMQArtemisFSM) map[string]string {
+	labels := make(map[string]string)
+	for key, value := range fsm.customResource.Spec.Monitoring.Labels {
+		labels[key] = value
+	}
+	for key, value := range fsm.namers.LabelBuilder.Labels() {
+		labels[key] = value
+	}
+	return labels
+}
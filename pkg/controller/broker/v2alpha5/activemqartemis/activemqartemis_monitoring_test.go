@@ -0,0 +1,60 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// alertExpr returns the expression of the alert of the prometheus rule
+func alertExpr(rule *monitoringv1.PrometheusRule, alert string) string {
+	for _, group := range rule.Spec.Groups {
+		for _, r := range group.Rules {
+			if r.Alert == alert {
+				return r.Expr.String()
+			}
+		}
+	}
+	return ""
+}
+
+var _ = Describe("Prometheus rule", func() {
+
+	newMonitoredCR := func(size int32) *brokerv2alpha5.ActiveMQArtemis {
+		cr := newTestCR()
+		cr.Spec.DeploymentPlan.Size = size
+		cr.Spec.Monitoring = &brokerv2alpha5.MonitoringType{Alerts: &brokerv2alpha5.AlertsType{}}
+		return cr
+	}
+
+	It("alerts when fewer brokers are up than the deployment has", func() {
+		fsm := newTestFSM(newMonitoredCR(3), nil)
+
+		expr := alertExpr(NewPrometheusRuleForCR(fsm), "ArtemisBrokerDown")
+
+		selector := `namespace="test",service="` + fsm.GetMetricsServiceName() + `"`
+		Expect(expr).To(Equal("(count(up{" + selector + "} == 1) or vector(0)) < 3"))
+	})
+
+	It("updates the deployed rule when the deployment is scaled", func() {
+		scheme := runtime.NewScheme()
+		Expect(monitoringv1.AddToScheme(scheme)).To(Succeed())
+		Expect(brokerv2alpha5.SchemeBuilder.AddToScheme(scheme)).To(Succeed())
+		fsm := newTestFSM(newMonitoredCR(2), nil)
+		c := fake.NewFakeClientWithScheme(scheme, NewPrometheusRuleForCR(fsm))
+
+		fsm.customResource.Spec.DeploymentPlan.Size = 4
+		syncPrometheusRule(fsm, c, scheme, NewPrometheusRuleForCR(fsm))
+
+		deployed := &monitoringv1.PrometheusRule{}
+		name := types.NamespacedName{Namespace: "test", Name: fsm.GetPrometheusRuleName()}
+		Expect(c.Get(context.TODO(), name, deployed)).To(Succeed())
+		Expect(alertExpr(deployed, "ArtemisBrokerDown")).To(HaveSuffix("< 4"))
+	})
+})
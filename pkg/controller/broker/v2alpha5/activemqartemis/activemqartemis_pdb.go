@@ -0,0 +1,79 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/poddisruptionbudgets"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ProcessPodDisruptionBudget adds the pod disruption budget of the broker pods to the
+// requested resources. The spec of a deployed budget cannot be updated, so a budget that
+// is no longer the requested one is deleted, to be created again once it is gone.
+func (reconciler *ActiveMQArtemisReconciler) ProcessPodDisruptionBudget(fsm *ActiveMQArtemisFSM, client client.Client) {
+
+	requested := NewPodDisruptionBudgetForCR(fsm)
+
+	namespacedName := types.NamespacedName{
+		Name:      fsm.GetPodDisruptionBudgetName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	deployed := &policyv1beta1.PodDisruptionBudget{}
+	err := client.Get(context.TODO(), namespacedName, deployed)
+	if err == nil {
+		if requested == nil || !isPodDisruptionBudgetSpecEqual(&deployed.Spec, &requested.Spec) {
+			log.Info("Pod disruption budget is no longer requested as deployed, deleting it", "name", namespacedName.Name)
+			if err = resources.Delete(namespacedName, client, deployed); err != nil {
+				log.Error(err, "Failed to delete pod disruption budget", "name", namespacedName.Name)
+			}
+			return
+		}
+	} else if !errors.IsNotFound(err) {
+		log.Error(err, "Failed to get pod disruption budget", "name", namespacedName.Name)
+	}
+
+	if requested != nil {
+		requestedResources = append(requestedResources, requested)
+	}
+}
+
+// NewPodDisruptionBudgetForCR returns the pod disruption budget of the broker pods of the
+// deployment plan, nil when there should be none
+func NewPodDisruptionBudgetForCR(fsm *ActiveMQArtemisFSM) *policyv1beta1.PodDisruptionBudget {
+
+	plan := &fsm.customResource.Spec.DeploymentPlan
+	budget := plan.PodDisruptionBudget
+	if budget != nil && budget.Enabled != nil && !*budget.Enabled {
+		return nil
+	}
+
+	var minAvailable, maxUnavailable *intstr.IntOrString
+	if budget != nil && (budget.MinAvailable != nil || budget.MaxUnavailable != nil) {
+		minAvailable = budget.MinAvailable
+		maxUnavailable = budget.MaxUnavailable
+	} else if (budget != nil && budget.Enabled != nil) || (isClustered(fsm.customResource) && plan.Size > 1) {
+		one := intstr.FromInt(1)
+		maxUnavailable = &one
+	} else {
+		return nil
+	}
+
+	namespacedName := types.NamespacedName{
+		Name:      fsm.GetPodDisruptionBudgetName(),
+		Namespace: fsm.customResource.Namespace,
+	}
+	labels := fsm.namers.LabelBuilder.Labels()
+	return poddisruptionbudgets.NewPodDisruptionBudgetForCR(namespacedName, labels, labels, minAvailable, maxUnavailable)
+}
+
+func isPodDisruptionBudgetSpecEqual(deployed *policyv1beta1.PodDisruptionBudgetSpec, requested *policyv1beta1.PodDisruptionBudgetSpec) bool {
+	return equality.Semantic.DeepEqual(deployed.MinAvailable, requested.MinAvailable) &&
+		equality.Semantic.DeepEqual(deployed.MaxUnavailable, requested.MaxUnavailable) &&
+		equality.Semantic.DeepEqual(deployed.Selector, requested.Selector)
+}
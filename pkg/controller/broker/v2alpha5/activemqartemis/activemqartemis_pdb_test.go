@@ -0,0 +1,125 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func intOrStringPtr(value intstr.IntOrString) *intstr.IntOrString {
+	return &value
+}
+
+var _ = Describe("Pod disruption budget", func() {
+
+	newClusterCR := func(size int32) *brokerv2alpha5.ActiveMQArtemis {
+		cr := newTestCR()
+		cr.Spec.DeploymentPlan.Size = size
+		return cr
+	}
+
+	BeforeEach(func() {
+		requestedResources = nil
+	})
+
+	It("is only requested by default for a cluster of several brokers", func() {
+		Expect(NewPodDisruptionBudgetForCR(newTestFSM(newClusterCR(1), nil))).To(BeNil())
+
+		single := newClusterCR(3)
+		single.Spec.DeploymentPlan.Clustered = boolPtr(false)
+		Expect(NewPodDisruptionBudgetForCR(newTestFSM(single, nil))).To(BeNil())
+
+		pdb := NewPodDisruptionBudgetForCR(newTestFSM(newClusterCR(3), nil))
+		Expect(pdb).NotTo(BeNil())
+		Expect(*pdb.Spec.MaxUnavailable).To(Equal(intstr.FromInt(1)))
+		Expect(pdb.Spec.MinAvailable).To(BeNil())
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(pdb.Labels))
+	})
+
+	It("follows the budget of the deployment plan", func() {
+		cr := newClusterCR(1)
+		cr.Spec.DeploymentPlan.PodDisruptionBudget = &brokerv2alpha5.PodDisruptionBudgetType{Enabled: boolPtr(true)}
+		pdb := NewPodDisruptionBudgetForCR(newTestFSM(cr, nil))
+		Expect(*pdb.Spec.MaxUnavailable).To(Equal(intstr.FromInt(1)))
+
+		cr = newClusterCR(3)
+		cr.Spec.DeploymentPlan.PodDisruptionBudget = &brokerv2alpha5.PodDisruptionBudgetType{MinAvailable: intOrStringPtr(intstr.FromString("50%"))}
+		pdb = NewPodDisruptionBudgetForCR(newTestFSM(cr, nil))
+		Expect(*pdb.Spec.MinAvailable).To(Equal(intstr.FromString("50%")))
+		Expect(pdb.Spec.MaxUnavailable).To(BeNil())
+
+		cr.Spec.DeploymentPlan.PodDisruptionBudget.Enabled = boolPtr(false)
+		Expect(NewPodDisruptionBudgetForCR(newTestFSM(cr, nil))).To(BeNil())
+	})
+
+	Context("when reconciled", func() {
+
+		var reconciler *ActiveMQArtemisReconciler
+
+		BeforeEach(func() {
+			reconciler = &ActiveMQArtemisReconciler{}
+		})
+
+		deployedBudget := func(c client.Client, fsm *ActiveMQArtemisFSM) error {
+			name := types.NamespacedName{Namespace: fsm.customResource.Namespace, Name: fsm.GetPodDisruptionBudgetName()}
+			return c.Get(context.TODO(), name, &policyv1beta1.PodDisruptionBudget{})
+		}
+
+		It("requests a new budget", func() {
+			fsm := newTestFSM(newClusterCR(3), nil)
+
+			reconciler.ProcessPodDisruptionBudget(fsm, fake.NewFakeClient())
+
+			Expect(requestedResources).To(ConsistOf(NewPodDisruptionBudgetForCR(fsm)))
+		})
+
+		It("keeps requesting an unchanged budget", func() {
+			fsm := newTestFSM(newClusterCR(3), nil)
+			c := fake.NewFakeClient(NewPodDisruptionBudgetForCR(fsm))
+
+			reconciler.ProcessPodDisruptionBudget(fsm, c)
+
+			Expect(requestedResources).To(HaveLen(1))
+			Expect(deployedBudget(c, fsm)).To(Succeed())
+		})
+
+		It("deletes a changed budget to create it again once it is gone", func() {
+			fsm := newTestFSM(newClusterCR(3), nil)
+			c := fake.NewFakeClient(NewPodDisruptionBudgetForCR(fsm))
+			fsm.customResource.Spec.DeploymentPlan.PodDisruptionBudget = &brokerv2alpha5.PodDisruptionBudgetType{MinAvailable: intOrStringPtr(intstr.FromInt(2))}
+
+			reconciler.ProcessPodDisruptionBudget(fsm, c)
+
+			Expect(requestedResources).To(BeEmpty())
+			Expect(errors.IsNotFound(deployedBudget(c, fsm))).To(BeTrue())
+
+			reconciler.ProcessPodDisruptionBudget(fsm, c)
+
+			Expect(requestedResources).To(HaveLen(1))
+			Expect(*requestedResources[0].(*policyv1beta1.PodDisruptionBudget).Spec.MinAvailable).To(Equal(intstr.FromInt(2)))
+		})
+
+		It("deletes a budget no longer requested", func() {
+			fsm := newTestFSM(newClusterCR(3), nil)
+			c := fake.NewFakeClient(NewPodDisruptionBudgetForCR(fsm))
+			fsm.customResource.Spec.DeploymentPlan.Size = 1
+
+			reconciler.ProcessPodDisruptionBudget(fsm, c)
+
+			Expect(requestedResources).To(BeEmpty())
+			Expect(errors.IsNotFound(deployedBudget(c, fsm))).To(BeTrue())
+		})
+	})
+})
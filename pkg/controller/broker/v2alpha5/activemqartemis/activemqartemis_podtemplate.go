@@ -0,0 +1,143 @@
+package v2alpha5activemqartemis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/pods"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// podTemplateOverrideErrors returns what the pod template override of the CR would change
+// of the operator managed parts of the pod template, whatever the rest of the CR is
+func podTemplateOverrideErrors(cr *brokerv2alpha5.ActiveMQArtemis) field.ErrorList {
+	override := cr.Spec.DeploymentPlan.PodTemplate
+	if override == nil {
+		return nil
+	}
+	return pods.ValidatePodTemplateOverride(field.NewPath("spec", "deploymentPlan", "podTemplate"), override, cr.Name+"-container")
+}
+
+// podTemplateOverrideConflicts returns the env vars, volumes and volume mounts of the
+// override that the generated pod template already has
+func podTemplateOverrideConflicts(generated *corev1.PodTemplateSpec, override *corev1.PodTemplateSpec) []string {
+	var conflicts []string
+	for _, container := range override.Spec.Containers {
+		for _, current := range generated.Spec.Containers {
+			if container.Name != current.Name {
+				continue
+			}
+			for _, env := range container.Env {
+				for _, currentEnv := range current.Env {
+					if env.Name == currentEnv.Name {
+						conflicts = append(conflicts, fmt.Sprintf("env var %s of container %s", env.Name, container.Name))
+					}
+				}
+			}
+			for _, mount := range container.VolumeMounts {
+				for _, currentMount := range current.VolumeMounts {
+					if mount.MountPath == currentMount.MountPath {
+						conflicts = append(conflicts, fmt.Sprintf("mount path %s of container %s", mount.MountPath, container.Name))
+					}
+				}
+			}
+		}
+	}
+	for _, volume := range override.Spec.Volumes {
+		for _, current := range generated.Spec.Volumes {
+			if volume.Name == current.Name {
+				conflicts = append(conflicts, fmt.Sprintf("volume %s", volume.Name))
+			}
+		}
+	}
+	return conflicts
+}
+
+// applyPodTemplateOverride merges the pod template override of the CR onto the generated
+// pod template. The template is left as generated when the override is invalid.
+func applyPodTemplateOverride(fsm *ActiveMQArtemisFSM, pts *corev1.PodTemplateSpec) {
+	override := fsm.customResource.Spec.DeploymentPlan.PodTemplate
+	if override == nil {
+		return
+	}
+	if errs := podTemplateOverrideErrors(fsm.customResource); len(errs) > 0 {
+		// the Valid condition of the CR tells why
+		log.Error(errs.ToAggregate(), "Pod template override not applied")
+		return
+	}
+	if conflicts := podTemplateOverrideConflicts(pts, override); len(conflicts) > 0 {
+		rejectPodTemplateOverride(fsm, "it sets "+strings.Join(conflicts, ", ")+" already set by the operator")
+		return
+	}
+	merged, err := mergePodTemplate(pts, override)
+	if err != nil {
+		rejectPodTemplateOverride(fsm, err.Error())
+		return
+	}
+	*pts = *merged
+}
+
+// rejectPodTemplateOverride tells with a warning event why the override that passed
+// validation can't be merged, which only shows once the pod template is generated
+func rejectPodTemplateOverride(fsm *ActiveMQArtemisFSM, reason string) {
+	log.Error(nil, "Pod template override not applied, "+reason)
+	recordEvent(fsm, fsm.customResource, corev1.EventTypeWarning, EventReasonPodTemplateRejected,
+		"deploymentPlan.podTemplate is not applied, %s", reason)
+}
+
+func mergePodTemplate(original *corev1.PodTemplateSpec, override *corev1.PodTemplateSpec) (*corev1.PodTemplateSpec, error) {
+	originalJSON, err := json.Marshal(original)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := podTemplatePatch(override)
+	if err != nil {
+		return nil, err
+	}
+	mergedJSON, err := strategicpatch.StrategicMergePatch(originalJSON, patch, corev1.PodTemplateSpec{})
+	if err != nil {
+		return nil, err
+	}
+	merged := &corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(mergedJSON, merged); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// podTemplatePatch returns the override as a strategic merge patch. The fields the typed
+// override leaves unset are marshalled as nulls, which would delete them from the
+// generated template, so these are pruned.
+func podTemplatePatch(override *corev1.PodTemplateSpec) ([]byte, error) {
+	overrideJSON, err := json.Marshal(override)
+	if err != nil {
+		return nil, err
+	}
+	patch := make(map[string]interface{})
+	if err := json.Unmarshal(overrideJSON, &patch); err != nil {
+		return nil, err
+	}
+	return json.Marshal(pruneNulls(patch))
+}
+
+func pruneNulls(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if field == nil {
+				delete(v, key)
+			} else {
+				v[key] = pruneNulls(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = pruneNulls(item)
+		}
+	}
+	return value
+}
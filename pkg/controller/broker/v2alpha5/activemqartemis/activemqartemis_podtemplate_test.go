@@ -0,0 +1,110 @@
+package v2alpha5activemqartemis
+
+import (
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func envNames(container *corev1.Container) []string {
+	var names []string
+	for _, env := range container.Env {
+		names = append(names, env.Name)
+	}
+	return names
+}
+
+func volumeNames(pts *corev1.PodTemplateSpec) []string {
+	var names []string
+	for _, volume := range pts.Spec.Volumes {
+		names = append(names, volume.Name)
+	}
+	return names
+}
+
+var _ = Describe("Pod template override", func() {
+
+	var cr *brokerv2alpha5.ActiveMQArtemis
+	var recorder *record.FakeRecorder
+	var fsm *ActiveMQArtemisFSM
+
+	BeforeEach(func() {
+		cr = newTestCR()
+		recorder = record.NewFakeRecorder(10)
+		fsm = newTestFSM(cr, nil)
+		fsm.r = &ReconcileActiveMQArtemis{recorder: recorder}
+	})
+
+	brokerContainer := func(pts *corev1.PodTemplateSpec) *corev1.Container {
+		for i := range pts.Spec.Containers {
+			if pts.Spec.Containers[i].Name == cr.Name+"-container" {
+				return &pts.Spec.Containers[i]
+			}
+		}
+		return nil
+	}
+
+	It("adds the env vars, mounts and volumes of the override", func() {
+		cr.Spec.DeploymentPlan.PodTemplate = &corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:         cr.Name + "-container",
+					Env:          []corev1.EnvVar{{Name: "EXTRA", Value: "1"}},
+					VolumeMounts: []corev1.VolumeMount{{Name: "extra", MountPath: "/opt/extra"}},
+				}},
+				Volumes: []corev1.Volume{{Name: "extra", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}},
+			},
+		}
+
+		pts := NewPodTemplateSpecForCR(fsm)
+
+		container := brokerContainer(&pts)
+		Expect(container).NotTo(BeNil())
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "EXTRA", Value: "1"}))
+		Expect(envNames(container)).To(ContainElement("CONFIG_BROKER"))
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "extra", MountPath: "/opt/extra"}))
+		Expect(volumeNames(&pts)).To(ContainElement("extra"))
+		Expect(pts.Spec.InitContainers).To(HaveLen(1))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("rejects an override of the operator managed parts with the Valid condition", func() {
+		cr.Spec.DeploymentPlan.PodTemplate = &corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: cr.Name + "-container", Image: "custom:latest"}},
+				Volumes:    []corev1.Volume{{Name: "tool-dir"}},
+			},
+		}
+
+		pts := NewPodTemplateSpecForCR(fsm)
+		fsm.updateValidCondition()
+
+		Expect(brokerContainer(&pts).Image).NotTo(Equal("custom:latest"))
+		valid := brokerv2alpha5.FindCondition(cr.Status.Conditions, brokerv2alpha5.ConditionTypeValid)
+		Expect(valid).NotTo(BeNil())
+		Expect(valid.Status).To(Equal(corev1.ConditionFalse))
+		Expect(valid.Reason).To(Equal(ReasonInvalidPodTemplate))
+		Expect(valid.Message).To(ContainSubstring("spec.deploymentPlan.podTemplate.spec.containers[0]"))
+		Expect(valid.Message).To(ContainSubstring("volume tool-dir is managed by the operator"))
+	})
+
+	It("rejects an override setting what the operator sets with a warning event", func() {
+		cr.Spec.DeploymentPlan.PodTemplate = &corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: cr.Name + "-container",
+					Env:  []corev1.EnvVar{{Name: "EXTRA", Value: "1"}, {Name: "CONFIG_BROKER", Value: "true"}},
+				}},
+			},
+		}
+
+		pts := NewPodTemplateSpecForCR(fsm)
+
+		Expect(envNames(brokerContainer(&pts))).NotTo(ContainElement("EXTRA"))
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix(corev1.EventTypeWarning+" "+EventReasonPodTemplateRejected),
+			ContainSubstring("env var CONFIG_BROKER of container "+cr.Name+"-container"))))
+	})
+})
@@ -0,0 +1,105 @@
+package v2alpha5activemqartemis
+
+import (
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/containers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func int32Ptr(value int32) *int32 {
+	return &value
+}
+
+var _ = Describe("Probes", func() {
+
+	newContainer := func() *corev1.Container {
+		container := containers.MakeContainer("ex-aao", "broker:latest", nil)
+		return &container
+	}
+
+	It("keeps the default probes when none is overridden", func() {
+		container := newContainer()
+
+		configProbes(container, &brokerv2alpha5.DeploymentPlanType{})
+
+		Expect(container).To(Equal(newContainer()))
+	})
+
+	It("overrides the handlers and timings of the probes", func() {
+		container := newContainer()
+		plan := &brokerv2alpha5.DeploymentPlanType{
+			LivenessProbe: brokerv2alpha5.LivenessProbeType{
+				Handler:          corev1.Handler{HTTPGet: &corev1.HTTPGetAction{Path: "/console", Port: intstr.FromInt(8161)}},
+				PeriodSeconds:    int32Ptr(30),
+				FailureThreshold: int32Ptr(5),
+			},
+			ReadinessProbe: brokerv2alpha5.ReadinessProbeType{
+				InitialDelaySeconds: int32Ptr(20),
+				SuccessThreshold:    int32Ptr(2),
+				TimeoutSeconds:      int32Ptr(7),
+			},
+		}
+
+		configProbes(container, plan)
+
+		liveness := container.LivenessProbe
+		Expect(liveness.TCPSocket).To(BeNil())
+		Expect(liveness.HTTPGet.Path).To(Equal("/console"))
+		Expect(liveness.PeriodSeconds).To(BeEquivalentTo(30))
+		Expect(liveness.FailureThreshold).To(BeEquivalentTo(5))
+		Expect(liveness.InitialDelaySeconds).To(Equal(newContainer().LivenessProbe.InitialDelaySeconds))
+
+		readiness := container.ReadinessProbe
+		Expect(readiness.Exec).NotTo(BeNil())
+		Expect(readiness.InitialDelaySeconds).To(BeEquivalentTo(20))
+		Expect(readiness.SuccessThreshold).To(BeEquivalentTo(2))
+		Expect(readiness.TimeoutSeconds).To(BeEquivalentTo(7))
+	})
+
+	It("adds the extra liveness delay to the initial delay of the liveness probe only", func() {
+		container := newContainer()
+		plan := &brokerv2alpha5.DeploymentPlanType{
+			LivenessProbe:             brokerv2alpha5.LivenessProbeType{InitialDelaySeconds: int32Ptr(30)},
+			ExtraLivenessDelaySeconds: int32Ptr(600),
+		}
+
+		configProbes(container, plan)
+
+		Expect(container.LivenessProbe.InitialDelaySeconds).To(BeEquivalentTo(630))
+		Expect(container.ReadinessProbe.InitialDelaySeconds).To(Equal(newContainer().ReadinessProbe.InitialDelaySeconds))
+	})
+
+	It("updates the statefulset when a probe changes", func() {
+		prev := newTestCR()
+		statefulSet := &appsv1.StatefulSet{}
+		changes := []func(*brokerv2alpha5.DeploymentPlanType){
+			func(p *brokerv2alpha5.DeploymentPlanType) { p.LivenessProbe.TimeoutSeconds = int32Ptr(10) },
+			func(p *brokerv2alpha5.DeploymentPlanType) {
+				p.ReadinessProbe.Handler = corev1.Handler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(61616)}}
+			},
+			func(p *brokerv2alpha5.DeploymentPlanType) { p.ExtraLivenessDelaySeconds = int32Ptr(120) },
+		}
+		for _, change := range changes {
+			cr := newTestCR()
+			change(&cr.Spec.DeploymentPlan)
+
+			Expect(isProbesChanged(&prev.Spec.DeploymentPlan, &cr.Spec.DeploymentPlan)).To(BeTrue())
+			Expect(checkGeneralStatefulSetUpdate(newTestFSM(cr, prev), statefulSet)).To(BeTrue())
+		}
+		Expect(isProbesChanged(&prev.Spec.DeploymentPlan, &newTestCR().Spec.DeploymentPlan)).To(BeFalse())
+	})
+
+	It("sets the probes of the broker container of a new CR", func() {
+		cr := newTestCR()
+		cr.Spec.DeploymentPlan.ExtraLivenessDelaySeconds = int32Ptr(300)
+
+		template := NewPodTemplateSpecForCR(newTestFSM(cr, nil))
+
+		Expect(template.Spec.Containers[0].LivenessProbe.InitialDelaySeconds).
+			To(Equal(newContainer().LivenessProbe.InitialDelaySeconds + 300))
+	})
+})
@@ -13,6 +13,7 @@ import (
 	"github.com/RHsyseng/operator-utils/pkg/resource/compare"
 	"github.com/RHsyseng/operator-utils/pkg/resource/read"
 	activemqartemisscaledown "github.com/artemiscloud/activemq-artemis-operator/pkg/controller/broker/v2alpha1/activemqartemisscaledown"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/metrics"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/containers"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/ingresses"
@@ -28,9 +29,12 @@ import (
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/cr2jinja2"
 	"github.com/artemiscloud/activemq-artemis-operator/version"
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	brokerv2alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha1"
@@ -42,7 +46,9 @@ import (
 	"reflect"
 
 	routev1 "github.com/openshift/api/route/v1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
 	extv1b1 "k8s.io/api/extensions/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 
 	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
 
@@ -100,8 +106,13 @@ type ActiveMQArtemisIReconciler interface {
 	ProcessStatefulSet(fsm *ActiveMQArtemisFSM, client client.Client, log logr.Logger, firstTime bool) (*appsv1.StatefulSet, bool)
 	ProcessCredentials(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) uint32
 	ProcessDeploymentPlan(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet, firstTime bool) uint32
+	ProcessCertificates(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme)
+	ProcessClusterTLS(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) uint32
 	ProcessAcceptorsAndConnectors(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) uint32
 	ProcessConsole(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet)
+	ProcessPodDisruptionBudget(fsm *ActiveMQArtemisFSM, client client.Client)
+	ProcessHorizontalPodAutoscaler(fsm *ActiveMQArtemisFSM, client client.Client)
+	ProcessMonitoring(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme)
 	ProcessResources(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme, currentStatefulSet *appsv1.StatefulSet) uint8
 	ProcessAddressSettings(customResource *brokerv2alpha5.ActiveMQArtemis, client client.Client) bool
 }
@@ -117,12 +128,22 @@ func (reconciler *ActiveMQArtemisReconciler) Process(fsm *ActiveMQArtemisFSM, cl
 
 	statefulSetUpdates |= reconciler.ProcessCredentials(fsm, client, scheme, currentStatefulSet)
 
+	reconciler.ProcessCertificates(fsm, client, scheme)
+
+	statefulSetUpdates |= reconciler.ProcessClusterTLS(fsm, client, scheme, currentStatefulSet)
+
 	statefulSetUpdates |= reconciler.ProcessAcceptorsAndConnectors(fsm, client, scheme, currentStatefulSet)
 
 	statefulSetUpdates |= reconciler.ProcessConsole(fsm, client, scheme, currentStatefulSet)
 
 	requestedResources = append(requestedResources, currentStatefulSet)
 
+	reconciler.ProcessPodDisruptionBudget(fsm, client)
+
+	reconciler.ProcessHorizontalPodAutoscaler(fsm, client)
+
+	reconciler.ProcessMonitoring(fsm, client, scheme)
+
 	stepsComplete := reconciler.ProcessResources(fsm, client, scheme, currentStatefulSet)
 
 	if statefulSetUpdates > 0 {
@@ -130,6 +151,8 @@ func (reconciler *ActiveMQArtemisReconciler) Process(fsm *ActiveMQArtemisFSM, cl
 		currentStatefulSet.ResourceVersion = ""
 		if err := resources.Update(ssNamespacedName, client, currentStatefulSet); err != nil {
 			log.Error(err, "Failed to update StatefulSet.", "Deployment.Namespace", currentStatefulSet.Namespace, "Deployment.Name", currentStatefulSet.Name)
+		} else {
+			recordEvent(fsm, fsm.customResource, corev1.EventTypeNormal, EventReasonStatefulSetRolled, "Updated statefulset %s, its pods are restarted one at a time", ssNamespacedName.Name)
 		}
 	}
 
@@ -150,6 +173,7 @@ func (reconciler *ActiveMQArtemisReconciler) ProcessStatefulSet(fsm *ActiveMQArt
 	} else if nil == err {
 		// Found it
 		log.Info("StatefulSet: " + ssNamespacedName.Name + " found")
+		fsm.deployedClaimTemplates = currentStatefulSet.Spec.VolumeClaimTemplates
 		log.Info("Checking for statefulset and current operator compatibility")
 		log.V(1).Info("Checking owner apiVersion")
 		objectMetadata := currentStatefulSet.GetObjectMeta()
@@ -194,16 +218,27 @@ func (reconciler *ActiveMQArtemisReconciler) ProcessStatefulSet(fsm *ActiveMQArt
 		}
 		if statefulsetRecreationRequired {
 			log.Info("Recreating existing statefulset")
+			deployedClaimTemplates := currentStatefulSet.Spec.VolumeClaimTemplates
 			deleteErr := resources.Delete(ssNamespacedName, client, currentStatefulSet)
 			if nil == deleteErr {
 				log.Info(fmt.Sprintf("sucessfully deleted ownerReference[0].APIVersion: %s, recreating v2alpha5 version for use", ownerReferenceArray[0].APIVersion))
+				recordEvent(fsm, fsm.customResource, corev1.EventTypeNormal, EventReasonStatefulSetRolled, "Deleted statefulset %s to recreate it, its pods are restarted", ssNamespacedName.Name)
 				currentStatefulSet = NewStatefulSetForCR(fsm)
+				keepClaimTemplates(currentStatefulSet, deployedClaimTemplates)
 				firstTime = true
 			} else {
 				log.Info("statefulset recreation failed!")
 			}
 		}
 
+		if !statefulsetRecreationRequired {
+			expandPersistentVolumeClaims(fsm, client, currentStatefulSet)
+			var recreated bool
+			if currentStatefulSet, recreated = recreateForClaimTemplates(fsm, client, currentStatefulSet); recreated {
+				firstTime = true
+			}
+		}
+
 		newPodTemplateCreated := false
 		//update statefulset with customer resource
 		log.Info("Calling ProcessAddressSettings")
@@ -238,10 +273,10 @@ func checkGeneralStatefulSetUpdate(fsm *ActiveMQArtemisFSM, currentStatefulSet *
 	if fsm.prevCustomResource == nil || currentStatefulSet == nil {
 		return false
 	}
-	prevConsole := fsm.prevCustomResource.Spec.Console
-	currConsole := fsm.customResource.Spec.Console
+	prevConsole := consoleForPods(fsm.prevCustomResource.Spec.Console)
+	currConsole := consoleForPods(fsm.customResource.Spec.Console)
 
-	if prevConsole != currConsole {
+	if !equality.Semantic.DeepEqual(prevConsole, currConsole) {
 		log.Info("Console config has changed, statefulset need update", "old", prevConsole, "new", currConsole)
 		return true
 	}
@@ -253,9 +288,53 @@ func checkGeneralStatefulSetUpdate(fsm *ActiveMQArtemisFSM, currentStatefulSet *
 		return true
 	}
 
+	if isPodSchedulingChanged(&fsm.prevCustomResource.Spec.DeploymentPlan, &fsm.customResource.Spec.DeploymentPlan) {
+		log.Info("Pod scheduling has changed, statefulset need update")
+		return true
+	}
+
+	if isProbesChanged(&fsm.prevCustomResource.Spec.DeploymentPlan, &fsm.customResource.Spec.DeploymentPlan) {
+		log.Info("Probes have changed, statefulset need update")
+		return true
+	}
+
+	if !equality.Semantic.DeepEqual(fsm.prevCustomResource.Spec.DeploymentPlan.PodTemplate, fsm.customResource.Spec.DeploymentPlan.PodTemplate) {
+		log.Info("Pod template override has changed, statefulset need update")
+		return true
+	}
+
+	if isClusterTLSEnabled(fsm.prevCustomResource) != isClusterTLSEnabled(fsm.customResource) {
+		log.Info("Cluster tls has changed, statefulset need update")
+		return true
+	}
+
 	return false
 }
 
+// consoleForPods returns the console config the brokers are started with, the ingress
+// exposing it and the certificate issued for it being updated in place
+func consoleForPods(console brokerv2alpha5.ConsoleType) brokerv2alpha5.ConsoleType {
+	console.IngressHost = ""
+	console.IngressClassName = ""
+	console.IngressAnnotations = nil
+	console.IngressTLSSecret = ""
+	console.CertManager = nil
+	return console
+}
+
+func isPodSchedulingChanged(prev *brokerv2alpha5.DeploymentPlanType, curr *brokerv2alpha5.DeploymentPlanType) bool {
+	return !equality.Semantic.DeepEqual(prev.Affinity, curr.Affinity) ||
+		!equality.Semantic.DeepEqual(prev.Tolerations, curr.Tolerations) ||
+		!equality.Semantic.DeepEqual(prev.NodeSelector, curr.NodeSelector) ||
+		prev.PriorityClassName != curr.PriorityClassName
+}
+
+func isProbesChanged(prev *brokerv2alpha5.DeploymentPlanType, curr *brokerv2alpha5.DeploymentPlanType) bool {
+	return !equality.Semantic.DeepEqual(prev.LivenessProbe, curr.LivenessProbe) ||
+		!equality.Semantic.DeepEqual(prev.ReadinessProbe, curr.ReadinessProbe) ||
+		!equality.Semantic.DeepEqual(prev.ExtraLivenessDelaySeconds, curr.ExtraLivenessDelaySeconds)
+}
+
 func isClustered(customResource *brokerv2alpha5.ActiveMQArtemis) bool {
 	if customResource.Spec.DeploymentPlan.Clustered != nil {
 		return *customResource.Spec.DeploymentPlan.Clustered
@@ -439,6 +518,7 @@ func (reconciler *ActiveMQArtemisReconciler) ProcessConsole(fsm *ActiveMQArtemis
 	var retVal uint32 = statefulSetNotUpdated
 
 	configureConsoleExposure(fsm, client, scheme)
+	syncConsoleOAuth(fsm, client, scheme)
 	if !fsm.customResource.Spec.Console.SSLEnabled {
 		return retVal
 	}
@@ -587,6 +667,7 @@ func sourceEnvVarFromSecret(fsm *ActiveMQArtemisFSM, currentStatefulSet *appsv1.
 
 			// Force the rolling update to occur
 			environments.IncrementTriggeredRollCount(currentStatefulSet.Spec.Template.Spec.Containers)
+			metrics.StatefulSetRollTriggered(fsm.customResource.Namespace, fsm.customResource.Name)
 
 			//so far it doesn't matter what the value is as long as it's greater than zero
 			retVal = statefulSetAcceptorsUpdated
@@ -680,6 +761,7 @@ func sourceEnvVarFromSecret2(fsm *ActiveMQArtemisFSM, currentStatefulSet *appsv1
 
 			// Force the rolling update to occur
 			environments.IncrementTriggeredRollCount(currentStatefulSet.Spec.Template.Spec.Containers)
+			metrics.StatefulSetRollTriggered(fsm.customResource.Namespace, fsm.customResource.Name)
 
 			//so far it doesn't matter what the value is as long as it's greater than zero
 			retVal = statefulSetAcceptorsUpdated
@@ -728,19 +810,20 @@ func generateAcceptorsString(fsm *ActiveMQArtemisFSM, client client.Client) stri
 	acceptorEntry := ""
 	defaultArgs := "tcpSendBufferSize=1048576;tcpReceiveBufferSize=1048576;useEpoll=true;amqpCredits=1000;amqpMinCredits=300"
 
-	var portIncrement int32 = 10
+	// Defaults are normally in the spec already, see brokerv2alpha5.SetDefaults_ActiveMQArtemis
+	var portIncrement int32 = brokerv2alpha5.DefaultAcceptorPortIncrement
 	var currentPortIncrement int32 = 0
 	var port61616InUse bool = false
 	var i uint32 = 0
 	for _, acceptor := range fsm.customResource.Spec.Acceptors {
 		if 0 == acceptor.Port {
-			acceptor.Port = 61626 + currentPortIncrement
+			acceptor.Port = brokerv2alpha5.DefaultAcceptorPortBase + currentPortIncrement
 			currentPortIncrement += portIncrement
 			fsm.customResource.Spec.Acceptors[i].Port = acceptor.Port
 		}
 		if "" == acceptor.Protocols ||
 			"all" == strings.ToLower(acceptor.Protocols) {
-			acceptor.Protocols = "AMQP,CORE,HORNETQ,MQTT,OPENWIRE,STOMP"
+			acceptor.Protocols = brokerv2alpha5.DefaultAcceptorProtocols
 		}
 		acceptorEntry = acceptorEntry + "<acceptor name=\"" + acceptor.Name + "\">"
 		acceptorEntry = acceptorEntry + "tcp:" + "\\/\\/" + "ACCEPTOR_IP:"
@@ -760,7 +843,7 @@ func generateAcceptorsString(fsm *ActiveMQArtemisFSM, client client.Client) stri
 			if "" != acceptor.SSLSecret {
 				secretName = acceptor.SSLSecret
 			}
-			acceptorEntry = acceptorEntry + ";" + generateAcceptorConnectorSSLArguments(fsm, client, secretName)
+			acceptorEntry = acceptorEntry + ";" + generateAcceptorConnectorSSLArguments(fsm, client, acceptor.Name, secretName)
 			sslOptionalArguments := generateAcceptorSSLOptionalArguments(acceptor)
 			if "" != sslOptionalArguments {
 				acceptorEntry = acceptorEntry + ";" + sslOptionalArguments
@@ -824,7 +907,7 @@ func generateConnectorsString(fsm *ActiveMQArtemisFSM, client client.Client) str
 			if "" != connector.SSLSecret {
 				secretName = connector.SSLSecret
 			}
-			connectorEntry = connectorEntry + ";" + generateAcceptorConnectorSSLArguments(fsm, client, secretName)
+			connectorEntry = connectorEntry + ";" + generateAcceptorConnectorSSLArguments(fsm, client, connector.Name, secretName)
 			sslOptionalArguments := generateConnectorSSLOptionalArguments(connector)
 			if "" != sslOptionalArguments {
 				connectorEntry = connectorEntry + ";" + sslOptionalArguments
@@ -843,6 +926,11 @@ func configureAcceptorsExposure(fsm *ActiveMQArtemisFSM, client client.Client, s
 	ordinalString := ""
 	causedUpdate := false
 
+	isOpenshift, err := environments.DetectOpenshift()
+	if err != nil {
+		log.Error(err, "Failed to get env, will try kubernetes")
+	}
+
 	originalLabels := fsm.namers.LabelBuilder.Labels()
 	namespacedName := types.NamespacedName{
 		Name:      fsm.customResource.Name,
@@ -857,25 +945,43 @@ func configureAcceptorsExposure(fsm *ActiveMQArtemisFSM, client client.Client, s
 		serviceRoutelabels["statefulset.kubernetes.io/pod-name"] = fsm.GetStatefulSetName() + "-" + ordinalString
 
 		for _, acceptor := range fsm.customResource.Spec.Acceptors {
+			exposeMode := acceptorExposeMode(acceptor, isOpenshift)
 			serviceDefinition := svc.NewServiceDefinitionForCR(namespacedName, acceptor.Name+"-"+ordinalString, acceptor.Port, serviceRoutelabels, fsm.namers.LabelBuilder.Labels())
+			serviceDefinition.Spec.Type = exposeServiceType(exposeMode)
 			serviceNamespacedName := types.NamespacedName{
 				Name:      serviceDefinition.Name,
 				Namespace: fsm.customResource.Namespace,
 			}
 			if acceptor.Expose {
-				requestedResources = append(requestedResources, serviceDefinition)
+				requestService(fsm, client, serviceDefinition)
 				//causedUpdate, err = resources.Enable(customResource, client, scheme, serviceNamespacedName, serviceDefinition)
 			} else {
 				causedUpdate, err = resources.Disable(fsm.customResource, client, scheme, serviceNamespacedName, serviceDefinition)
 			}
 			targetPortName := acceptor.Name + "-" + ordinalString
 			targetServiceName := fsm.customResource.Name + "-" + targetPortName + "-svc"
+
+			options := ingressOptions(fsm, acceptor.Name, ordinalString, acceptor.IngressHost, acceptor.IngressClassName, acceptor.IngressAnnotations, acceptor.IngressTLSSecret)
+			ingressDefinition := ingresses.NewIngressForCRWithOptions(namespacedName, serviceRoutelabels, targetServiceName, targetPortName, options)
+			ingressNamespacedName := types.NamespacedName{
+				Name:      ingressDefinition.Name,
+				Namespace: fsm.customResource.Namespace,
+			}
+			if acceptor.Expose && exposeMode == brokerv2alpha5.ExposeModeIngress {
+				requestIngress(fsm, client, ingressDefinition)
+			} else {
+				causedUpdate, err = resources.Disable(fsm.customResource, client, scheme, ingressNamespacedName, ingressDefinition)
+			}
+
+			if !isOpenshift {
+				continue
+			}
 			routeDefinition := routes.NewRouteDefinitionForCR(namespacedName, serviceRoutelabels, targetServiceName, targetPortName, acceptor.SSLEnabled)
 			routeNamespacedName := types.NamespacedName{
 				Name:      routeDefinition.Name,
 				Namespace: fsm.customResource.Namespace,
 			}
-			if acceptor.Expose {
+			if acceptor.Expose && exposeMode == brokerv2alpha5.ExposeModeRoute {
 				requestedResources = append(requestedResources, routeDefinition)
 				//causedUpdate, err = resources.Enable(customResource, client, scheme, routeNamespacedName, routeDefinition)
 			} else {
@@ -940,6 +1046,16 @@ func configureConnectorsExposure(fsm *ActiveMQArtemisFSM, client client.Client,
 	return causedUpdate, err
 }
 
+// consolePortName returns the name of the console port of a broker, its service and route
+// are named after it
+func consolePortName(ordinal string) string {
+	return "wconsj" + "-" + ordinal
+}
+
+func consoleServiceName(fsm *ActiveMQArtemisFSM, ordinal string) string {
+	return fsm.customResource.Name + "-" + consolePortName(ordinal) + "-svc"
+}
+
 func configureConsoleExposure(fsm *ActiveMQArtemisFSM, client client.Client, scheme *runtime.Scheme) (bool, error) {
 
 	var i int32 = 0
@@ -947,6 +1063,7 @@ func configureConsoleExposure(fsm *ActiveMQArtemisFSM, client client.Client, sch
 	ordinalString := ""
 	causedUpdate := false
 	console := fsm.customResource.Spec.Console
+	oauthProxyEnabled := isConsoleOAuthProxyEnabled(fsm.customResource)
 
 	originalLabels := fsm.namers.LabelBuilder.Labels()
 	namespacedName := types.NamespacedName{
@@ -962,10 +1079,14 @@ func configureConsoleExposure(fsm *ActiveMQArtemisFSM, client client.Client, sch
 		serviceRoutelabels["statefulset.kubernetes.io/pod-name"] = fsm.GetStatefulSetName() + "-" + ordinalString
 
 		portNumber := int32(8161)
-		targetPortName := "wconsj" + "-" + ordinalString
-		targetServiceName := fsm.customResource.Name + "-" + targetPortName + "-svc"
+		targetPortName := consolePortName(ordinalString)
+		targetServiceName := consoleServiceName(fsm, ordinalString)
 
 		serviceDefinition := svc.NewServiceDefinitionForCR(namespacedName, targetPortName, portNumber, serviceRoutelabels, fsm.namers.LabelBuilder.Labels())
+		if oauthProxyEnabled {
+			// the users reach the console through its proxy only
+			serviceDefinition.Spec.Ports[0].TargetPort = intstr.FromInt(int(brokerv2alpha5.ConsoleOAuthProxyPort))
+		}
 
 		serviceNamespacedName := types.NamespacedName{
 			Name:      serviceDefinition.Name,
@@ -986,7 +1107,13 @@ func configureConsoleExposure(fsm *ActiveMQArtemisFSM, client client.Client, sch
 		if isOpenshift {
 			log.Info("Environment is OpenShift")
 			log.Info("Checking routeDefinition for " + targetPortName)
-			routeDefinition := routes.NewRouteDefinitionForCR(namespacedName, serviceRoutelabels, targetServiceName, targetPortName, console.SSLEnabled)
+			routeDefinition := routes.NewRouteDefinitionForCR(namespacedName, serviceRoutelabels, targetServiceName, targetPortName, console.SSLEnabled && !oauthProxyEnabled)
+			if oauthProxyEnabled {
+				routeDefinition.Spec.TLS = &routev1.TLSConfig{
+					Termination:                   routev1.TLSTerminationEdge,
+					InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+				}
+			}
 			routeNamespacedName := types.NamespacedName{
 				Name:      routeDefinition.Name,
 				Namespace: fsm.customResource.Namespace,
@@ -999,13 +1126,19 @@ func configureConsoleExposure(fsm *ActiveMQArtemisFSM, client client.Client, sch
 			}
 		} else {
 			log.Info("Environment is not OpenShift, creating ingress")
-			ingressDefinition := ingresses.NewIngressForCRWithSSL(namespacedName, serviceRoutelabels, targetServiceName, targetPortName, console.SSLEnabled)
+			var ingressDefinition *extv1b1.Ingress
+			if isIngressConfigured(console.IngressHost, console.IngressClassName, console.IngressAnnotations, console.IngressTLSSecret) {
+				options := ingressOptions(fsm, "console", ordinalString, console.IngressHost, console.IngressClassName, console.IngressAnnotations, console.IngressTLSSecret)
+				ingressDefinition = ingresses.NewIngressForCRWithOptions(namespacedName, serviceRoutelabels, targetServiceName, targetPortName, options)
+			} else {
+				ingressDefinition = ingresses.NewIngressForCRWithSSL(namespacedName, serviceRoutelabels, targetServiceName, targetPortName, console.SSLEnabled && !oauthProxyEnabled)
+			}
 			ingressNamespacedName := types.NamespacedName{
 				Name:      ingressDefinition.Name,
 				Namespace: fsm.customResource.Namespace,
 			}
 			if console.Expose {
-				requestedResources = append(requestedResources, ingressDefinition)
+				requestIngress(fsm, client, ingressDefinition)
 				//causedUpdate, err = resources.Enable(customResource, client, scheme, ingressNamespacedName, ingressDefinition)
 			} else {
 				causedUpdate, err = resources.Disable(fsm.customResource, client, scheme, ingressNamespacedName, ingressDefinition)
@@ -1034,6 +1167,7 @@ func generateConsoleSSLFlags(fsm *ActiveMQArtemisFSM, client client.Client, secr
 	keyStorePath := "/etc/" + secretName + "-volume/broker.ks"
 	trustStorePassword := "password"
 	trustStorePath := "/etc/" + secretName + "-volume/client.ts"
+	keyStoreType := ""
 	if err := resources.Retrieve(secretNamespacedName, client, userPasswordSecret); err == nil {
 		if "" != string(userPasswordSecret.Data["keyStorePassword"]) {
 			keyStorePassword = string(userPasswordSecret.Data["keyStorePassword"])
@@ -1047,12 +1181,26 @@ func generateConsoleSSLFlags(fsm *ActiveMQArtemisFSM, client client.Client, secr
 		if "" != string(userPasswordSecret.Data["trustStorePath"]) {
 			trustStorePath = string(userPasswordSecret.Data["trustStorePath"])
 		}
+		if password, converted := convertedStores(fsm, client, "console"); converted && isPEMSecret(userPasswordSecret) {
+			storesPath := "/etc/" + storesSecretName(fsm.customResource, "console") + "-volume/"
+			keyStorePassword = password
+			keyStorePath = storesPath + keyStoreFile
+			trustStorePassword = password
+			trustStorePath = storesPath + trustStoreFile
+			keyStoreType = storeType
+		}
+	} else {
+		warnMissingSSLSecret(fsm, secretName, err)
 	}
 
 	sslFlags = sslFlags + " " + "--ssl-key" + " " + keyStorePath
 	sslFlags = sslFlags + " " + "--ssl-key-password" + " " + keyStorePassword
 	sslFlags = sslFlags + " " + "--ssl-trust" + " " + trustStorePath
 	sslFlags = sslFlags + " " + "--ssl-trust-password" + " " + trustStorePassword
+	if "" != keyStoreType {
+		sslFlags = sslFlags + " " + "--ssl-key-type" + " " + keyStoreType
+		sslFlags = sslFlags + " " + "--ssl-trust-type" + " " + keyStoreType
+	}
 	if fsm.customResource.Spec.Console.UseClientAuth {
 		sslFlags = sslFlags + " " + "--use-client-auth"
 	}
@@ -1060,7 +1208,7 @@ func generateConsoleSSLFlags(fsm *ActiveMQArtemisFSM, client client.Client, secr
 	return sslFlags
 }
 
-func generateAcceptorConnectorSSLArguments(fsm *ActiveMQArtemisFSM, client client.Client, secretName string) string {
+func generateAcceptorConnectorSSLArguments(fsm *ActiveMQArtemisFSM, client client.Client, itemName string, secretName string) string {
 
 	sslArguments := "sslEnabled=true"
 	secretNamespacedName := types.NamespacedName{
@@ -1078,6 +1226,7 @@ func generateAcceptorConnectorSSLArguments(fsm *ActiveMQArtemisFSM, client clien
 	keyStorePath := "\\/etc\\/" + secretName + "-volume\\/broker.ks"
 	trustStorePassword := "password"
 	trustStorePath := "\\/etc\\/" + secretName + "-volume\\/client.ts"
+	keyStoreType := ""
 	if err := resources.Retrieve(secretNamespacedName, client, userPasswordSecret); err == nil {
 		if "" != string(userPasswordSecret.Data["keyStorePassword"]) {
 			//noinspection GoUnresolvedReference
@@ -1095,15 +1244,39 @@ func generateAcceptorConnectorSSLArguments(fsm *ActiveMQArtemisFSM, client clien
 			//noinspection GoUnresolvedReference
 			trustStorePath = strings.ReplaceAll(string(userPasswordSecret.Data["trustStorePath"]), "/", "\\/")
 		}
+		if password, converted := convertedStores(fsm, client, itemName); converted && isPEMSecret(userPasswordSecret) {
+			storesPath := "\\/etc\\/" + storesSecretName(fsm.customResource, itemName) + "-volume\\/"
+			keyStorePassword = password
+			keyStorePath = storesPath + keyStoreFile
+			trustStorePassword = password
+			trustStorePath = storesPath + trustStoreFile
+			keyStoreType = storeType
+		}
+	} else {
+		warnMissingSSLSecret(fsm, secretName, err)
 	}
 	sslArguments = sslArguments + ";" + "keyStorePath=" + keyStorePath
 	sslArguments = sslArguments + ";" + "keyStorePassword=" + keyStorePassword
 	sslArguments = sslArguments + ";" + "trustStorePath=" + trustStorePath
 	sslArguments = sslArguments + ";" + "trustStorePassword=" + trustStorePassword
+	if "" != keyStoreType {
+		sslArguments = sslArguments + ";" + "keyStoreType=" + keyStoreType
+		sslArguments = sslArguments + ";" + "trustStoreType=" + keyStoreType
+	}
 
 	return sslArguments
 }
 
+// warnMissingSSLSecret records a warning while the ssl secret of an acceptor, connector or
+// the console is missing, the admission webhook lets such a CR through as the secret may
+// be applied after it
+func warnMissingSSLSecret(fsm *ActiveMQArtemisFSM, secretName string, err error) {
+	if errors.IsNotFound(err) {
+		recordEvent(fsm, fsm.customResource, corev1.EventTypeWarning, EventReasonSSLSecretMissing,
+			"SSL is enabled but secret %s does not exist, the broker pods can't start until it is created", secretName)
+	}
+}
+
 func generateAcceptorSSLOptionalArguments(acceptor brokerv2alpha5.AcceptorType) string {
 
 	sslOptionalArguments := ""
@@ -1320,10 +1493,8 @@ func imageSyncCausedUpdateOn(customResource *brokerv2alpha5.ActiveMQArtemis, cur
 		imageName = customResource.Spec.DeploymentPlan.Image
 	}
 	if strings.Compare(currentStatefulSet.Spec.Template.Spec.Containers[0].Image, imageName) != 0 {
-		containerArrayLen := len(currentStatefulSet.Spec.Template.Spec.Containers)
-		for i := 0; i < containerArrayLen; i++ {
-			currentStatefulSet.Spec.Template.Spec.Containers[i].Image = imageName
-		}
+		// the sidecars keep their own images
+		currentStatefulSet.Spec.Template.Spec.Containers[0].Image = imageName
 		return true
 	}
 
@@ -1596,6 +1767,8 @@ func getDeployedResources(instance *brokerv2alpha5.ActiveMQArtemis, client clien
 			&appsv1.StatefulSetList{},
 			&routev1.RouteList{},
 			&corev1.SecretList{},
+			&policyv1beta1.PodDisruptionBudgetList{},
+			&autoscalingv2beta1.HorizontalPodAutoscalerList{},
 		)
 	} else {
 		resourceMap, err = reader.ListAll(
@@ -1604,6 +1777,8 @@ func getDeployedResources(instance *brokerv2alpha5.ActiveMQArtemis, client clien
 			&appsv1.StatefulSetList{},
 			&extv1b1.IngressList{},
 			&corev1.SecretList{},
+			&policyv1beta1.PodDisruptionBudgetList{},
+			&autoscalingv2beta1.HorizontalPodAutoscalerList{},
 		)
 	}
 	if err != nil {
@@ -1620,6 +1795,8 @@ func MakeVolumes(fsm *ActiveMQArtemisFSM) []corev1.Volume {
 	if fsm.customResource.Spec.DeploymentPlan.PersistenceEnabled {
 		basicCRVolume := volumes.MakePersistentVolume(fsm.customResource.Name)
 		volumeDefinitions = append(volumeDefinitions, basicCRVolume...)
+		dataVolumes, _ := makeDataVolumes(fsm)
+		volumeDefinitions = append(volumeDefinitions, dataVolumes...)
 	}
 
 	// Scan acceptors for any with sslEnabled
@@ -1633,6 +1810,7 @@ func MakeVolumes(fsm *ActiveMQArtemisFSM) []corev1.Volume {
 		}
 		volume := volumes.MakeVolume(secretName)
 		volumeDefinitions = append(volumeDefinitions, volume)
+		volumeDefinitions = append(volumeDefinitions, volumes.MakeOptionalVolume(storesSecretName(fsm.customResource, acceptor.Name)))
 	}
 
 	// Scan connectors for any with sslEnabled
@@ -1646,6 +1824,7 @@ func MakeVolumes(fsm *ActiveMQArtemisFSM) []corev1.Volume {
 		}
 		volume := volumes.MakeVolume(secretName)
 		volumeDefinitions = append(volumeDefinitions, volume)
+		volumeDefinitions = append(volumeDefinitions, volumes.MakeOptionalVolume(storesSecretName(fsm.customResource, connector.Name)))
 	}
 
 	if fsm.customResource.Spec.Console.SSLEnabled {
@@ -1655,6 +1834,7 @@ func MakeVolumes(fsm *ActiveMQArtemisFSM) []corev1.Volume {
 		}
 		volume := volumes.MakeVolume(secretName)
 		volumeDefinitions = append(volumeDefinitions, volume)
+		volumeDefinitions = append(volumeDefinitions, volumes.MakeOptionalVolume(storesSecretName(fsm.customResource, "console")))
 	}
 
 	return volumeDefinitions
@@ -1666,6 +1846,8 @@ func MakeVolumeMounts(fsm *ActiveMQArtemisFSM) []corev1.VolumeMount {
 	if fsm.customResource.Spec.DeploymentPlan.PersistenceEnabled {
 		persistentCRVlMnt := volumes.MakePersistentVolumeMount(fsm.customResource.Name, fsm.namers.GLOBAL_DATA_PATH)
 		volumeMounts = append(volumeMounts, persistentCRVlMnt...)
+		_, dataVolumeMounts := makeDataVolumes(fsm)
+		volumeMounts = append(volumeMounts, dataVolumeMounts...)
 	}
 
 	// Scan acceptors for any with sslEnabled
@@ -1679,6 +1861,7 @@ func MakeVolumeMounts(fsm *ActiveMQArtemisFSM) []corev1.VolumeMount {
 		}
 		volumeMount := volumes.MakeVolumeMount(volumeMountName)
 		volumeMounts = append(volumeMounts, volumeMount)
+		volumeMounts = append(volumeMounts, volumes.MakeVolumeMount(storesSecretName(fsm.customResource, acceptor.Name)+"-volume"))
 	}
 
 	// Scan connectors for any with sslEnabled
@@ -1692,6 +1875,7 @@ func MakeVolumeMounts(fsm *ActiveMQArtemisFSM) []corev1.VolumeMount {
 		}
 		volumeMount := volumes.MakeVolumeMount(volumeMountName)
 		volumeMounts = append(volumeMounts, volumeMount)
+		volumeMounts = append(volumeMounts, volumes.MakeVolumeMount(storesSecretName(fsm.customResource, connector.Name)+"-volume"))
 	}
 
 	if fsm.customResource.Spec.Console.SSLEnabled {
@@ -1701,6 +1885,7 @@ func MakeVolumeMounts(fsm *ActiveMQArtemisFSM) []corev1.VolumeMount {
 		}
 		volumeMount := volumes.MakeVolumeMount(volumeMountName)
 		volumeMounts = append(volumeMounts, volumeMount)
+		volumeMounts = append(volumeMounts, volumes.MakeVolumeMount(storesSecretName(fsm.customResource, "console")+"-volume"))
 	}
 
 	return volumeMounts
@@ -1775,15 +1960,8 @@ func NewPodTemplateSpecForCR(fsm *ActiveMQArtemisFSM) corev1.PodTemplateSpec {
 	}
 	reqLogger.V(1).Info("now mounts added to container", "new len", len(container.VolumeMounts))
 
-	if fsm.customResource.Spec.DeploymentPlan.LivenessProbe.TimeoutSeconds != nil {
-		container.LivenessProbe.TimeoutSeconds = *fsm.customResource.Spec.DeploymentPlan.LivenessProbe.TimeoutSeconds
-		reqLogger.V(1).Info("Setting liveness timeoutSeconds", "value", container.LivenessProbe.TimeoutSeconds)
-	}
-	reqLogger.V(1).Info("Checking out readiness", "crvalue", fsm.customResource.Spec.DeploymentPlan.ReadinessProbe.TimeoutSeconds)
-	if fsm.customResource.Spec.DeploymentPlan.ReadinessProbe.TimeoutSeconds != nil {
-		container.ReadinessProbe.TimeoutSeconds = *fsm.customResource.Spec.DeploymentPlan.ReadinessProbe.TimeoutSeconds
-		reqLogger.V(1).Info("Setting readiness timeoutSeconds", "value", container.ReadinessProbe.TimeoutSeconds)
-	}
+	configProbes(&container, &fsm.customResource.Spec.DeploymentPlan)
+	reqLogger.V(1).Info("Probes configured", "liveness", container.LivenessProbe, "readiness", container.ReadinessProbe)
 
 	Spec.Containers = append(Containers, container)
 	brokerVolumes := MakeVolumes(fsm)
@@ -1795,7 +1973,7 @@ func NewPodTemplateSpecForCR(fsm *ActiveMQArtemisFSM) corev1.PodTemplateSpec {
 	}
 	Spec.TerminationGracePeriodSeconds = &terminationGracePeriodSeconds
 
-	var cfgVolumeName string = "amq-cfg-dir"
+	var cfgVolumeName string = pods.ConfigVolumeName
 
 	//tell container don't config
 	envConfigBroker := corev1.EnvVar{
@@ -1930,11 +2108,11 @@ func NewPodTemplateSpecForCR(fsm *ActiveMQArtemisFSM) corev1.PodTemplateSpec {
 	volumeMountForCfgRoot := volumes.MakeVolumeMountForCfg(cfgVolumeName, brokerConfigRoot)
 	Spec.InitContainers[0].VolumeMounts = append(Spec.InitContainers[0].VolumeMounts, volumeMountForCfgRoot)
 
-	volumeMountForCfg = volumes.MakeVolumeMountForCfg("tool-dir", initCfgRootDir)
+	volumeMountForCfg = volumes.MakeVolumeMountForCfg(pods.ToolVolumeName, initCfgRootDir)
 	Spec.InitContainers[0].VolumeMounts = append(Spec.InitContainers[0].VolumeMounts, volumeMountForCfg)
 
 	//add empty-dir volume
-	volumeForCfg = volumes.MakeVolumeForCfg("tool-dir")
+	volumeForCfg = volumes.MakeVolumeForCfg(pods.ToolVolumeName)
 	Spec.Volumes = append(Spec.Volumes, volumeForCfg)
 
 	log.Info("Total volumes ", "volumes", Spec.Volumes)
@@ -1959,7 +2137,10 @@ func NewPodTemplateSpecForCR(fsm *ActiveMQArtemisFSM) corev1.PodTemplateSpec {
 
 	isFirst := true
 	initCmds = append(initCmds, configCmd)
+	initCmds = append(initCmds, dataVolumeConfigCmds(fsm)...)
+	initCmds = append(initCmds, clusterTLSConfigCmds(fsm)...)
 	initCmds = append(initCmds, brokerHandlerCmds...)
+	initCmds = append(initCmds, consoleOAuthConfigCmds(fsm, consoleHawtioRoles(fsm), initCfgRootDir+"/security", "$CONFIG_INSTANCE_DIR")...)
 	initCmds = append(initCmds, initHelperScript)
 
 	for _, icmd := range initCmds {
@@ -1993,11 +2174,15 @@ func NewPodTemplateSpecForCR(fsm *ActiveMQArtemisFSM) corev1.PodTemplateSpec {
 	}
 	environments.Create(Spec.InitContainers, &envBrokerCustomInstanceDir)
 
+	configClusterTLS(fsm, &Spec)
+	configConsoleOAuthProxy(fsm, &Spec)
 	configPodSecurity(&Spec, &fsm.customResource.Spec.DeploymentPlan.PodSecurity)
+	configPodScheduling(&Spec, &fsm.customResource.Spec.DeploymentPlan)
 
 	log.Info("Final Init spec", "Detail", Spec.InitContainers)
 
 	pts.Spec = Spec
+	applyPodTemplateOverride(fsm, &pts)
 
 	return pts
 }
@@ -2020,6 +2205,48 @@ func configPodSecurity(podSpec *corev1.PodSpec, podSecurity *brokerv2alpha5.PodS
 	}
 }
 
+func configPodScheduling(podSpec *corev1.PodSpec, deploymentPlan *brokerv2alpha5.DeploymentPlanType) {
+	podSpec.Affinity = deploymentPlan.Affinity
+	podSpec.Tolerations = deploymentPlan.Tolerations
+	podSpec.NodeSelector = deploymentPlan.NodeSelector
+	podSpec.PriorityClassName = deploymentPlan.PriorityClassName
+}
+
+func configProbes(container *corev1.Container, deploymentPlan *brokerv2alpha5.DeploymentPlanType) {
+	liveness := &deploymentPlan.LivenessProbe
+	configProbe(container.LivenessProbe, &liveness.Handler, liveness.InitialDelaySeconds, liveness.TimeoutSeconds,
+		liveness.PeriodSeconds, liveness.SuccessThreshold, liveness.FailureThreshold)
+	readiness := &deploymentPlan.ReadinessProbe
+	configProbe(container.ReadinessProbe, &readiness.Handler, readiness.InitialDelaySeconds, readiness.TimeoutSeconds,
+		readiness.PeriodSeconds, readiness.SuccessThreshold, readiness.FailureThreshold)
+
+	if delay := deploymentPlan.ExtraLivenessDelaySeconds; delay != nil {
+		container.LivenessProbe.InitialDelaySeconds += *delay
+	}
+}
+
+func configProbe(probe *corev1.Probe, handler *corev1.Handler, initialDelaySeconds *int32, timeoutSeconds *int32,
+	periodSeconds *int32, successThreshold *int32, failureThreshold *int32) {
+	if handler.Exec != nil || handler.HTTPGet != nil || handler.TCPSocket != nil {
+		probe.Handler = *handler.DeepCopy()
+	}
+	if initialDelaySeconds != nil {
+		probe.InitialDelaySeconds = *initialDelaySeconds
+	}
+	if timeoutSeconds != nil {
+		probe.TimeoutSeconds = *timeoutSeconds
+	}
+	if periodSeconds != nil {
+		probe.PeriodSeconds = *periodSeconds
+	}
+	if successThreshold != nil {
+		probe.SuccessThreshold = *successThreshold
+	}
+	if failureThreshold != nil {
+		probe.FailureThreshold = *failureThreshold
+	}
+}
+
 func determineImageToUse(customResource *brokerv2alpha5.ActiveMQArtemis, imageTypeName string) string {
 
 	imageName := ""
@@ -2140,7 +2367,7 @@ func NewStatefulSetForCR(fsm *ActiveMQArtemisFSM) *appsv1.StatefulSet {
 	ss, Spec := statefulsets.MakeStatefulSet2(fsm.GetStatefulSetName(), fsm.GetHeadlessServiceName(), namespacedName, fsm.customResource.Annotations, fsm.namers.LabelBuilder.Labels(), fsm.customResource.Spec.DeploymentPlan.Size, NewPodTemplateSpecForCR(fsm))
 
 	if fsm.customResource.Spec.DeploymentPlan.PersistenceEnabled {
-		Spec.VolumeClaimTemplates = *NewPersistentVolumeClaimArrayForCR(fsm, 1)
+		Spec.VolumeClaimTemplates = newClaimTemplatesForCR(fsm)
 	}
 	ss.Spec = Spec
 
@@ -2164,6 +2391,7 @@ func NewPersistentVolumeClaimArrayForCR(fsm *ActiveMQArtemisFSM, arrayLength int
 
 	for i := 0; i < arrayLength; i++ {
 		pvc = persistentvolumeclaims.NewPersistentVolumeClaimWithCapacity(namespacedName, capacity, fsm.namers.LabelBuilder.Labels())
+		configPersistentVolumeClaim(pvc, &fsm.customResource.Spec.DeploymentPlan.Storage)
 		pvcArray = append(pvcArray, *pvc)
 	}
 
@@ -2171,10 +2399,14 @@ func NewPersistentVolumeClaimArrayForCR(fsm *ActiveMQArtemisFSM, arrayLength int
 }
 
 // TODO: Test namespacedName to ensure it's the right namespacedName
-func UpdatePodStatus(cr *brokerv2alpha5.ActiveMQArtemis, client client.Client, ssNamespacedName types.NamespacedName) error {
+func UpdateStatus(fsm *ActiveMQArtemisFSM) error {
+
+	cr := fsm.customResource
+	client := fsm.r.client
+	ssNamespacedName := fsm.GetStatefulSetNamespacedName()
 
 	reqLogger := log.WithValues("ActiveMQArtemis Name", cr.Name)
-	reqLogger.V(1).Info("Updating status for pods")
+	reqLogger.V(1).Info("Updating status")
 
 	podStatus := GetPodStatus(cr, client, ssNamespacedName)
 
@@ -2183,16 +2415,30 @@ func UpdatePodStatus(cr *brokerv2alpha5.ActiveMQArtemis, client client.Client, s
 	reqLogger.V(1).Info("Stopped Count........................", "info:", len(podStatus.Stopped))
 	reqLogger.V(1).Info("Starting Count........................", "info:", len(podStatus.Starting))
 
-	if !reflect.DeepEqual(podStatus, cr.Status.PodStatus) {
-		cr.Status.PodStatus = podStatus
+	cr.Status.PodStatus = podStatus
+	// The scale subresource reports these to a horizontal pod autoscaler
+	cr.Status.Replicas = int32(len(podStatus.Ready) + len(podStatus.Starting) + len(podStatus.Stopped))
+	cr.Status.Selector = labels.SelectorFromSet(fsm.namers.LabelBuilder.Labels()).String()
+	cr.Status.ExternalAddresses = externalAddresses(fsm, client)
+	cr.Status.ObservedGeneration = cr.Generation
+	cr.Status.FSMState = fsm.GetStateName()
+	metrics.SetFSMState(cr.Namespace, cr.Name, cr.Status.FSMState)
 
-		err := resources.Update(ssNamespacedName, client, cr)
-		if err != nil {
-			reqLogger.Error(err, "Failed to update pods status")
+	// Compare against the live object, the fsm copy may have been restored from an older version
+	current := &brokerv2alpha5.ActiveMQArtemis{}
+	if err := client.Get(context.TODO(), fsm.namespacedName, current); err != nil {
+		reqLogger.Error(err, "Failed to get custom resource for status update")
+		return err
+	}
+
+	if !reflect.DeepEqual(cr.Status, current.Status) {
+		current.Status = *cr.Status.DeepCopy()
+
+		if err := client.Status().Update(context.TODO(), current); err != nil {
+			reqLogger.Error(err, "Failed to update status")
 			return err
 		}
-		reqLogger.Info("Pods status updated")
-		return nil
+		reqLogger.Info("Status updated", "state", cr.Status.FSMState)
 	}
 
 	return nil
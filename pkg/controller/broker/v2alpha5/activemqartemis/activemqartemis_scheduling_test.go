@@ -0,0 +1,121 @@
+package v2alpha5activemqartemis
+
+import (
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// newTestFSM returns the fsm of a broker CR made outside the controller, with the given
+// spec as the one last reconciled
+func newTestFSM(cr *brokerv2alpha5.ActiveMQArtemis, prev *brokerv2alpha5.ActiveMQArtemis) *ActiveMQArtemisFSM {
+	fsm := MakeActiveMQArtemisFSM(cr, types.NamespacedName{Name: cr.Name, Namespace: cr.Namespace}, nil)
+	if prev != nil {
+		fsm.prevCustomResource = prev
+	}
+	return fsm
+}
+
+func newTestCR() *brokerv2alpha5.ActiveMQArtemis {
+	return &brokerv2alpha5.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv2alpha5.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv2alpha5.DeploymentPlanType{Size: 1},
+		},
+	}
+}
+
+var _ = Describe("Pod scheduling", func() {
+
+	schedulingPlan := func() brokerv2alpha5.DeploymentPlanType {
+		return brokerv2alpha5.DeploymentPlanType{
+			Affinity: &corev1.Affinity{
+				PodAntiAffinity: &corev1.PodAntiAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+						LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"application": "ex-aao-app"}},
+						TopologyKey:   "kubernetes.io/hostname",
+					}},
+				},
+			},
+			Tolerations:       []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "brokers", Effect: corev1.TaintEffectNoSchedule}},
+			NodeSelector:      map[string]string{"disktype": "ssd"},
+			PriorityClassName: "broker-critical",
+		}
+	}
+
+	It("sets the scheduling of the deployment plan on the pods", func() {
+		plan := schedulingPlan()
+		podSpec := &corev1.PodSpec{}
+
+		configPodScheduling(podSpec, &plan)
+
+		Expect(podSpec.Affinity).To(Equal(plan.Affinity))
+		Expect(podSpec.Tolerations).To(Equal(plan.Tolerations))
+		Expect(podSpec.NodeSelector).To(Equal(plan.NodeSelector))
+		Expect(podSpec.PriorityClassName).To(Equal("broker-critical"))
+	})
+
+	It("clears the scheduling removed from the deployment plan", func() {
+		plan := schedulingPlan()
+		podSpec := &corev1.PodSpec{}
+		configPodScheduling(podSpec, &plan)
+
+		configPodScheduling(podSpec, &brokerv2alpha5.DeploymentPlanType{})
+
+		Expect(podSpec.Affinity).To(BeNil())
+		Expect(podSpec.Tolerations).To(BeEmpty())
+		Expect(podSpec.NodeSelector).To(BeEmpty())
+		Expect(podSpec.PriorityClassName).To(BeEmpty())
+	})
+
+	It("tells each scheduling change", func() {
+		plan := schedulingPlan()
+		Expect(isPodSchedulingChanged(&plan, &plan)).To(BeFalse())
+
+		// an empty list or map schedules as a missing one
+		empty := brokerv2alpha5.DeploymentPlanType{Tolerations: []corev1.Toleration{}, NodeSelector: map[string]string{}}
+		Expect(isPodSchedulingChanged(&brokerv2alpha5.DeploymentPlanType{}, &empty)).To(BeFalse())
+
+		changes := []func(*brokerv2alpha5.DeploymentPlanType){
+			func(p *brokerv2alpha5.DeploymentPlanType) { p.Affinity = nil },
+			func(p *brokerv2alpha5.DeploymentPlanType) {
+				p.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution[0].TopologyKey = "topology.kubernetes.io/zone"
+			},
+			func(p *brokerv2alpha5.DeploymentPlanType) { p.Tolerations[0].Value = "other" },
+			func(p *brokerv2alpha5.DeploymentPlanType) { p.NodeSelector["disktype"] = "hdd" },
+			func(p *brokerv2alpha5.DeploymentPlanType) { p.PriorityClassName = "" },
+		}
+		for _, change := range changes {
+			curr := schedulingPlan()
+			change(&curr)
+			Expect(isPodSchedulingChanged(&plan, &curr)).To(BeTrue())
+		}
+	})
+
+	It("updates the statefulset when the scheduling changes", func() {
+		prev := newTestCR()
+		cr := newTestCR()
+		cr.Spec.DeploymentPlan.NodeSelector = map[string]string{"disktype": "ssd"}
+		statefulSet := &appsv1.StatefulSet{}
+
+		Expect(checkGeneralStatefulSetUpdate(newTestFSM(prev, prev.DeepCopy()), statefulSet)).To(BeFalse())
+		Expect(checkGeneralStatefulSetUpdate(newTestFSM(cr, prev), statefulSet)).To(BeTrue())
+	})
+
+	It("schedules the broker pods of a new CR", func() {
+		cr := newTestCR()
+		cr.Spec.DeploymentPlan = schedulingPlan()
+		cr.Spec.DeploymentPlan.Size = 1
+
+		template := NewPodTemplateSpecForCR(newTestFSM(cr, nil))
+
+		Expect(template.Spec.Affinity).To(Equal(cr.Spec.DeploymentPlan.Affinity))
+		Expect(template.Spec.Tolerations).To(Equal(cr.Spec.DeploymentPlan.Tolerations))
+		Expect(template.Spec.NodeSelector).To(Equal(cr.Spec.DeploymentPlan.NodeSelector))
+		Expect(template.Spec.PriorityClassName).To(Equal("broker-critical"))
+	})
+})
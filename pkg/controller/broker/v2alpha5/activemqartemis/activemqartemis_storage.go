@@ -0,0 +1,358 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/persistentvolumeclaims"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/volumes"
+)
+
+// dataDirectory is a data directory of a broker that may be put on a volume of its own
+type dataDirectory struct {
+	// name of the directory, also used in the names of its claims
+	name string
+	// element of broker.xml holding the path of the directory
+	element string
+}
+
+var dataDirectories = map[string]dataDirectory{
+	"journal":       {"journal", "journal-directory"},
+	"bindings":      {"bindings", "bindings-directory"},
+	"paging":        {"paging", "paging-directory"},
+	"largeMessages": {"large-messages", "large-messages-directory"},
+}
+
+// newClaimTemplatesForCR returns the claim templates of the broker statefulset, the one of
+// the data volume followed by those of the data directories that have a volume of their own
+func newClaimTemplatesForCR(fsm *ActiveMQArtemisFSM) []corev1.PersistentVolumeClaim {
+	claims := *NewPersistentVolumeClaimArrayForCR(fsm, 1)
+	storage := &fsm.customResource.Spec.DeploymentPlan.Storage
+	for _, volume := range storage.Volumes {
+		directory, ok := dataDirectories[volume.Directory]
+		if !ok {
+			continue
+		}
+		capacity := "2Gi"
+		if volume.Size != "" {
+			capacity = volume.Size
+		}
+		namespacedName := types.NamespacedName{
+			Name:      fsm.customResource.Name + "-" + directory.name,
+			Namespace: fsm.customResource.Namespace,
+		}
+		pvc := persistentvolumeclaims.NewPersistentVolumeClaimWithCapacity(namespacedName, capacity, fsm.namers.LabelBuilder.Labels())
+		configPersistentVolumeClaim(pvc, &brokerv2alpha5.StorageType{
+			StorageClassName: volume.StorageClassName,
+			AccessModes:      volume.AccessModes,
+			Labels:           storage.Labels,
+			Annotations:      storage.Annotations,
+		})
+		claims = append(claims, *pvc)
+	}
+	return claims
+}
+
+// dataVolumeDirectories returns the data directories that have a volume of their own: those
+// the deployment plan declares, or once the statefulset is deployed those it has claim
+// templates for, as its claims can't be added to or removed
+func dataVolumeDirectories(fsm *ActiveMQArtemisFSM) []dataDirectory {
+	var directories []dataDirectory
+	if !fsm.customResource.Spec.DeploymentPlan.PersistenceEnabled {
+		return directories
+	}
+	if len(fsm.deployedClaimTemplates) > 0 {
+		for _, template := range fsm.deployedClaimTemplates {
+			for _, directory := range dataDirectories {
+				if template.Name == fsm.customResource.Name+"-"+directory.name {
+					directories = append(directories, directory)
+				}
+			}
+		}
+		return directories
+	}
+	for _, volume := range fsm.customResource.Spec.DeploymentPlan.Storage.Volumes {
+		if directory, ok := dataDirectories[volume.Directory]; ok {
+			directories = append(directories, directory)
+		}
+	}
+	return directories
+}
+
+// makeDataVolumes returns the volumes and mounts of the data directories that have a
+// volume of their own, next to the data directory of the broker
+func makeDataVolumes(fsm *ActiveMQArtemisFSM) ([]corev1.Volume, []corev1.VolumeMount) {
+	var dataVolumes []corev1.Volume
+	var dataVolumeMounts []corev1.VolumeMount
+	for _, directory := range dataVolumeDirectories(fsm) {
+		claimName := fsm.customResource.Name + "-" + directory.name
+		dataVolumes = append(dataVolumes, volumes.MakePersistentVolume(claimName)...)
+		dataVolumeMounts = append(dataVolumeMounts, volumes.MakePersistentVolumeMount(claimName, dataDirectoryPath(fsm, directory))...)
+	}
+	return dataVolumes, dataVolumeMounts
+}
+
+func dataDirectoryPath(fsm *ActiveMQArtemisFSM, directory dataDirectory) string {
+	return path.Join(path.Dir(fsm.namers.GLOBAL_DATA_PATH), directory.name)
+}
+
+// dataVolumeConfigCmds returns the commands pointing the broker config generated by the
+// init container to the data directories that have a volume of their own
+func dataVolumeConfigCmds(fsm *ActiveMQArtemisFSM) []string {
+	var expressions []string
+	for _, directory := range dataVolumeDirectories(fsm) {
+		element := directory.element
+		expressions = append(expressions, "-e 's#<"+element+">[^<]*</"+element+">#<"+element+">"+dataDirectoryPath(fsm, directory)+"</"+element+">#'")
+	}
+	if len(expressions) == 0 {
+		return nil
+	}
+	return []string{"for f in $(find $CONFIG_INSTANCE_DIR -name broker.xml 2>/dev/null); do sed -i " + strings.Join(expressions, " ") + " \"$f\"; done"}
+}
+
+// configPersistentVolumeClaim sets the storage options of the deployment plan on a claim
+// template of the broker statefulset
+func configPersistentVolumeClaim(pvc *corev1.PersistentVolumeClaim, storage *brokerv2alpha5.StorageType) {
+	if len(storage.Labels) > 0 {
+		labels := make(map[string]string)
+		for key, value := range storage.Labels {
+			labels[key] = value
+		}
+		for key, value := range pvc.Labels {
+			labels[key] = value
+		}
+		pvc.Labels = labels
+	}
+	if len(storage.Annotations) > 0 {
+		pvc.Annotations = make(map[string]string)
+		for key, value := range storage.Annotations {
+			pvc.Annotations[key] = value
+		}
+	}
+	if storage.StorageClassName != nil {
+		pvc.Spec.StorageClassName = storage.StorageClassName
+	}
+	if len(storage.AccessModes) > 0 {
+		pvc.Spec.AccessModes = storage.AccessModes
+	}
+	if storage.Selector != nil {
+		pvc.Spec.Selector = storage.Selector.DeepCopy()
+	}
+}
+
+// claimTemplateUpdate returns the claim templates of a statefulset replacing the deployed
+// one: the deployed templates with the larger storage sizes that are requested, which
+// expandPersistentVolumeClaims applies to the claims the statefulset created. Kubernetes
+// can't change the other options of these claims, the requested changes to them are
+// returned as not applied and the deployed templates are kept, so that the claims of the
+// brokers added later are like those of the others.
+func claimTemplateUpdate(deployed []corev1.PersistentVolumeClaim, requested []corev1.PersistentVolumeClaim) ([]corev1.PersistentVolumeClaim, bool, []string) {
+	templates := make([]corev1.PersistentVolumeClaim, 0, len(deployed))
+	grown := false
+	var unapplied []string
+	requestedByName := make(map[string]*corev1.PersistentVolumeClaim)
+	for i := range requested {
+		requestedByName[requested[i].Name] = &requested[i]
+	}
+	for i := range deployed {
+		template := deployed[i].DeepCopy()
+		templates = append(templates, *template)
+		req, ok := requestedByName[template.Name]
+		if !ok {
+			unapplied = append(unapplied, fmt.Sprintf("removal of claim template %s", template.Name))
+			continue
+		}
+		delete(requestedByName, template.Name)
+		for option, changed := range map[string]bool{
+			"labels":           !equality.Semantic.DeepEqual(template.Labels, req.Labels),
+			"annotations":      !equality.Semantic.DeepEqual(template.Annotations, req.Annotations),
+			"storageClassName": !equality.Semantic.DeepEqual(template.Spec.StorageClassName, req.Spec.StorageClassName),
+			"accessModes":      !equality.Semantic.DeepEqual(template.Spec.AccessModes, req.Spec.AccessModes),
+			"selector":         !equality.Semantic.DeepEqual(template.Spec.Selector, req.Spec.Selector),
+		} {
+			if changed {
+				unapplied = append(unapplied, fmt.Sprintf("%s of claim template %s", option, template.Name))
+			}
+		}
+		deployedSize := template.Spec.Resources.Requests[corev1.ResourceStorage]
+		requestedSize := req.Spec.Resources.Requests[corev1.ResourceStorage]
+		if deployedSize.Cmp(requestedSize) < 0 {
+			if template.Spec.Resources.Requests == nil {
+				templates[i].Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			templates[i].Spec.Resources.Requests[corev1.ResourceStorage] = requestedSize
+			grown = true
+		} else if deployedSize.Cmp(requestedSize) > 0 {
+			unapplied = append(unapplied, fmt.Sprintf("shrinking of claim template %s", template.Name))
+		}
+	}
+	for i := range requested {
+		if _, added := requestedByName[requested[i].Name]; added {
+			unapplied = append(unapplied, fmt.Sprintf("addition of claim template %s", requested[i].Name))
+		}
+	}
+	sort.Strings(unapplied)
+	return templates, grown, unapplied
+}
+
+// recreateForClaimTemplates replaces the deployed statefulset when the storage sizes of
+// the deployment plan grew, as its claim templates cannot be updated. The statefulset is
+// deleted leaving its pods running for the new one to adopt. The other changes to the claim
+// templates are reported with an event as not applied. It returns the statefulset to
+// request, the deployed one when it was not replaced.
+func recreateForClaimTemplates(fsm *ActiveMQArtemisFSM, c client.Client, currentStatefulSet *appsv1.StatefulSet) (*appsv1.StatefulSet, bool) {
+	if !fsm.customResource.Spec.DeploymentPlan.PersistenceEnabled || len(currentStatefulSet.Spec.VolumeClaimTemplates) == 0 {
+		return currentStatefulSet, false
+	}
+	templates, grown, unapplied := claimTemplateUpdate(currentStatefulSet.Spec.VolumeClaimTemplates, newClaimTemplatesForCR(fsm))
+	if len(unapplied) > 0 {
+		log.Info("Claim template changes not applied", "statefulset", currentStatefulSet.Name, "changes", unapplied)
+		recordEvent(fsm, fsm.customResource, corev1.EventTypeWarning, EventReasonStorageNotApplied,
+			"The claims of statefulset %s can't be changed once created, not applying the %s", currentStatefulSet.Name, strings.Join(unapplied, ", "))
+	}
+	if !grown {
+		return currentStatefulSet, false
+	}
+	if !claimsResized(brokerClaims(fsm, c, currentStatefulSet)) {
+		// the claims of the running brokers are expanded first, so that a broker created by
+		// the new statefulset before they are never gets a smaller claim
+		return currentStatefulSet, false
+	}
+	log.Info("Storage sizes have grown, recreating statefulset leaving its pods running", "statefulset", currentStatefulSet.Name)
+	if err := c.Delete(context.TODO(), currentStatefulSet, client.PropagationPolicy(metav1.DeletePropagationOrphan)); err != nil && !errors.IsNotFound(err) {
+		log.Error(err, "Failed to delete statefulset to update its claim templates", "statefulset", currentStatefulSet.Name)
+		return currentStatefulSet, false
+	}
+	statefulSet := NewStatefulSetForCR(fsm)
+	statefulSet.Spec.VolumeClaimTemplates = templates
+	return statefulSet, true
+}
+
+// keepClaimTemplates sets the claim templates of the deleted statefulset on the one
+// recreating it, for which recreateForClaimTemplates then applies what it can
+func keepClaimTemplates(statefulSet *appsv1.StatefulSet, deployed []corev1.PersistentVolumeClaim) {
+	if len(deployed) > 0 && len(statefulSet.Spec.VolumeClaimTemplates) > 0 {
+		statefulSet.Spec.VolumeClaimTemplates = deployed
+	}
+}
+
+// brokerClaim is a claim of a broker with the storage size the deployment plan requests
+type brokerClaim struct {
+	pvc  *corev1.PersistentVolumeClaim
+	size resource.Quantity
+}
+
+// expandPersistentVolumeClaims requests the storage sizes of the deployment plan on the
+// claims of the brokers that have a smaller one, and reports how far their expansion got
+// in the StorageResized condition
+func expandPersistentVolumeClaims(fsm *ActiveMQArtemisFSM, c client.Client, currentStatefulSet *appsv1.StatefulSet) {
+	if !fsm.customResource.Spec.DeploymentPlan.PersistenceEnabled || currentStatefulSet == nil {
+		return
+	}
+	claims := brokerClaims(fsm, c, currentStatefulSet)
+	if len(claims) == 0 {
+		return
+	}
+
+	resized, pending := 0, 0
+	var failed []string
+	for _, claim := range claims {
+		pvc := claim.pvc
+		requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if requested.Cmp(claim.size) < 0 {
+			log.Info("Expanding persistent volume claim", "claim", pvc.Name, "from", requested.String(), "to", claim.size.String())
+			pvc.Spec.Resources.Requests[corev1.ResourceStorage] = claim.size
+			if err := c.Update(context.TODO(), pvc); err != nil {
+				log.Error(err, "Failed to expand persistent volume claim", "claim", pvc.Name)
+				failed = append(failed, fmt.Sprintf("%s: %v", pvc.Name, err))
+				continue
+			}
+		} else if requested.Cmp(claim.size) > 0 {
+			log.V(1).Info("Persistent volume claims cannot shrink, keeping their size", "claim", pvc.Name, "size", requested.String())
+		}
+		capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+		if capacity.Cmp(claim.size) >= 0 {
+			resized++
+		}
+		for _, condition := range pvc.Status.Conditions {
+			if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+				pending++
+			}
+		}
+	}
+
+	message := fmt.Sprintf("%d/%d claims resized", resized, len(claims))
+	if len(failed) > 0 {
+		fsm.SetCondition(brokerv2alpha5.ConditionTypeStorageResized, corev1.ConditionFalse, ReasonStorageResizeFailed,
+			fmt.Sprintf("%s, failed to expand %v", message, failed))
+	} else if resized == len(claims) {
+		fsm.SetCondition(brokerv2alpha5.ConditionTypeStorageResized, corev1.ConditionTrue, ReasonStorageResized, message)
+	} else if pending > 0 {
+		fsm.SetCondition(brokerv2alpha5.ConditionTypeStorageResized, corev1.ConditionFalse, ReasonFileSystemResizePending,
+			fmt.Sprintf("%s, %d waiting for their broker to restart", message, pending))
+	} else {
+		fsm.SetCondition(brokerv2alpha5.ConditionTypeStorageResized, corev1.ConditionFalse, ReasonStorageResizing, message)
+	}
+}
+
+// claimsResized tells whether every claim requests at least the storage size of the
+// deployment plan
+func claimsResized(claims []brokerClaim) bool {
+	for _, claim := range claims {
+		requested := claim.pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		if requested.Cmp(claim.size) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// brokerClaims returns the claims the statefulset created for its brokers from the claim
+// templates that are still requested
+func brokerClaims(fsm *ActiveMQArtemisFSM, c client.Client, currentStatefulSet *appsv1.StatefulSet) []brokerClaim {
+	sizes := make(map[string]resource.Quantity)
+	for _, template := range newClaimTemplatesForCR(fsm) {
+		sizes[template.Name] = template.Spec.Resources.Requests[corev1.ResourceStorage]
+	}
+	var claims []brokerClaim
+	replicas := 0
+	if currentStatefulSet.Spec.Replicas != nil {
+		replicas = int(*currentStatefulSet.Spec.Replicas)
+	}
+	for _, template := range currentStatefulSet.Spec.VolumeClaimTemplates {
+		size, requested := sizes[template.Name]
+		if !requested {
+			continue
+		}
+		for i := 0; i < replicas; i++ {
+			pvc := &corev1.PersistentVolumeClaim{}
+			name := types.NamespacedName{
+				Namespace: currentStatefulSet.Namespace,
+				Name:      template.Name + "-" + currentStatefulSet.Name + "-" + strconv.Itoa(i),
+			}
+			if err := c.Get(context.TODO(), name, pvc); err != nil {
+				log.V(1).Info("Persistent volume claim not found, skipping it", "claim", name, "error", err)
+				continue
+			}
+			if pvc.Spec.Resources.Requests == nil {
+				pvc.Spec.Resources.Requests = corev1.ResourceList{}
+			}
+			claims = append(claims, brokerClaim{pvc: pvc, size: size})
+		}
+	}
+	return claims
+}
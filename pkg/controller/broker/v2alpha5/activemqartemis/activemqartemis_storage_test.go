@@ -0,0 +1,170 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"strconv"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newPersistentCR(size string) *brokerv2alpha5.ActiveMQArtemis {
+	cr := newTestCR()
+	cr.Spec.DeploymentPlan.Size = 2
+	cr.Spec.DeploymentPlan.PersistenceEnabled = true
+	cr.Spec.DeploymentPlan.Storage.Size = size
+	return cr
+}
+
+// brokerClaimsOf returns the claims the statefulset would have created for its brokers,
+// each requesting the given size
+func brokerClaimsOf(statefulSet *appsv1.StatefulSet, size string) []corev1.PersistentVolumeClaim {
+	var claims []corev1.PersistentVolumeClaim
+	for _, template := range statefulSet.Spec.VolumeClaimTemplates {
+		for i := 0; i < int(*statefulSet.Spec.Replicas); i++ {
+			claim := template.DeepCopy()
+			claim.Name = template.Name + "-" + statefulSet.Name + "-" + strconv.Itoa(i)
+			claim.Namespace = statefulSet.Namespace
+			claim.Spec.Resources.Requests = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)}
+			claims = append(claims, *claim)
+		}
+	}
+	return claims
+}
+
+func storageSize(pvc *corev1.PersistentVolumeClaim) string {
+	size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	return size.String()
+}
+
+var _ = Describe("Claim templates", func() {
+
+	var recorder *record.FakeRecorder
+	var deployed *appsv1.StatefulSet
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		deployed = NewStatefulSetForCR(newTestFSM(newPersistentCR("2Gi"), nil))
+	})
+
+	newFSM := func(cr *brokerv2alpha5.ActiveMQArtemis) *ActiveMQArtemisFSM {
+		fsm := newTestFSM(cr, nil)
+		fsm.r = &ReconcileActiveMQArtemis{recorder: recorder}
+		return fsm
+	}
+
+	newClient := func(claimSize string) client.Client {
+		objects := []runtime.Object{deployed.DeepCopy()}
+		for _, claim := range brokerClaimsOf(deployed, claimSize) {
+			objects = append(objects, claim.DeepCopy())
+		}
+		return fake.NewFakeClient(objects...)
+	}
+
+	It("keeps the deployed templates with the grown sizes", func() {
+		storageClass := "fast"
+		requested := newClaimTemplatesForCR(newTestFSM(newPersistentCR("5Gi"), nil))
+		requested[0].Spec.StorageClassName = &storageClass
+		requested[0].Labels = map[string]string{"tier": "gold"}
+
+		templates, grown, unapplied := claimTemplateUpdate(deployed.Spec.VolumeClaimTemplates, requested)
+
+		Expect(grown).To(BeTrue())
+		Expect(templates).To(HaveLen(1))
+		Expect(storageSize(&templates[0])).To(Equal("5Gi"))
+		Expect(templates[0].Spec.StorageClassName).To(BeNil())
+		Expect(templates[0].Labels).To(Equal(deployed.Spec.VolumeClaimTemplates[0].Labels))
+		Expect(unapplied).To(ConsistOf("labels of claim template ex-aao", "storageClassName of claim template ex-aao"))
+		Expect(storageSize(&deployed.Spec.VolumeClaimTemplates[0])).To(Equal("2Gi"))
+	})
+
+	It("does not shrink, add or remove templates", func() {
+		cr := newPersistentCR("1Gi")
+		cr.Spec.DeploymentPlan.Storage.Volumes = []brokerv2alpha5.DataVolumeType{{Directory: "journal"}}
+		requested := newClaimTemplatesForCR(newTestFSM(cr, nil))
+
+		templates, grown, unapplied := claimTemplateUpdate(deployed.Spec.VolumeClaimTemplates, requested)
+
+		Expect(grown).To(BeFalse())
+		Expect(templates).To(Equal(deployed.Spec.VolumeClaimTemplates))
+		Expect(unapplied).To(ConsistOf("shrinking of claim template ex-aao", "addition of claim template ex-aao-journal"))
+
+		_, _, unapplied = claimTemplateUpdate(requested, deployed.Spec.VolumeClaimTemplates)
+		Expect(unapplied).To(ContainElement("removal of claim template ex-aao-journal"))
+	})
+
+	It("leaves the statefulset of an unchanged storage plan", func() {
+		c := newClient("2Gi")
+
+		statefulSet, recreated := recreateForClaimTemplates(newFSM(newPersistentCR("2Gi")), c, deployed)
+
+		Expect(recreated).To(BeFalse())
+		Expect(statefulSet).To(BeIdenticalTo(deployed))
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("recreates the statefulset once the claims of its brokers are expanded", func() {
+		cr := newPersistentCR("5Gi")
+		c := newClient("2Gi")
+		fsm := newFSM(cr)
+
+		statefulSet, recreated := recreateForClaimTemplates(fsm, c, deployed)
+		Expect(recreated).To(BeFalse())
+		Expect(statefulSet).To(BeIdenticalTo(deployed))
+
+		expandPersistentVolumeClaims(fsm, c, deployed)
+		for _, claim := range brokerClaimsOf(deployed, "5Gi") {
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: claim.Namespace, Name: claim.Name}, pvc)).To(Succeed())
+			Expect(storageSize(pvc)).To(Equal("5Gi"))
+		}
+		resized := brokerv2alpha5.FindCondition(cr.Status.Conditions, brokerv2alpha5.ConditionTypeStorageResized)
+		Expect(resized.Status).To(Equal(corev1.ConditionFalse))
+		Expect(resized.Reason).To(Equal(ReasonStorageResizing))
+
+		statefulSet, recreated = recreateForClaimTemplates(fsm, c, deployed)
+		Expect(recreated).To(BeTrue())
+		Expect(storageSize(&statefulSet.Spec.VolumeClaimTemplates[0])).To(Equal("5Gi"))
+		err := c.Get(context.TODO(), types.NamespacedName{Namespace: deployed.Namespace, Name: deployed.Name}, &appsv1.StatefulSet{})
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("does not recreate the statefulset for the options Kubernetes can't change", func() {
+		cr := newPersistentCR("2Gi")
+		storageClass := "fast"
+		cr.Spec.DeploymentPlan.Storage.StorageClassName = &storageClass
+		cr.Spec.DeploymentPlan.Storage.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+		cr.Spec.DeploymentPlan.Storage.Annotations = map[string]string{"backup": "daily"}
+		c := newClient("2Gi")
+
+		statefulSet, recreated := recreateForClaimTemplates(newFSM(cr), c, deployed)
+
+		Expect(recreated).To(BeFalse())
+		Expect(statefulSet).To(BeIdenticalTo(deployed))
+		Expect(c.Get(context.TODO(), types.NamespacedName{Namespace: deployed.Namespace, Name: deployed.Name}, &appsv1.StatefulSet{})).To(Succeed())
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix(corev1.EventTypeWarning+" "+EventReasonStorageNotApplied),
+			ContainSubstring("accessModes of claim template ex-aao, annotations of claim template ex-aao, storageClassName of claim template ex-aao"))))
+	})
+
+	It("keeps the deployed templates on a statefulset recreated for another change", func() {
+		cr := newPersistentCR("5Gi")
+		cr.Spec.DeploymentPlan.Storage.Labels = map[string]string{"tier": "gold"}
+		statefulSet := NewStatefulSetForCR(newTestFSM(cr, nil))
+
+		keepClaimTemplates(statefulSet, deployed.Spec.VolumeClaimTemplates)
+
+		Expect(statefulSet.Spec.VolumeClaimTemplates).To(Equal(deployed.Spec.VolumeClaimTemplates))
+	})
+})
@@ -0,0 +1,13 @@
+package v2alpha5activemqartemis
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestV2alpha5ActiveMQArtemis(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "V2alpha5 ActiveMQArtemis Suite")
+}
@@ -0,0 +1,93 @@
+package v2alpha5activemqartemis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/brokerclient"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BrokerUser is a user of the properties login module of the broker security domain
+type BrokerUser struct {
+	Name     string
+	Password string
+	Roles    []string
+}
+
+// ResetUsersForSecurity sets the password and roles of users on the running brokers the
+// config handler applies to, through the management api rather than a new pod template.
+// Brokers that are not running yet read the users from their config when they start.
+func ResetUsersForSecurity(handler ActiveMQArtemisConfigHandler, users []BrokerUser, client client.Client) error {
+	failed := 0
+	for nsn, fsm := range namespacedNameToFSM {
+		if !handler.IsApplicableFor(nsn) {
+			continue
+		}
+		brokers, err := getRunningBrokers(fsm, client)
+		if err != nil {
+			log.Error(err, "Failed to find the brokers to reset users on", "cr", nsn)
+			failed++
+			continue
+		}
+		for podName, broker := range brokers {
+			for _, user := range users {
+				if err := broker.ResetUser(user.Name, user.Password, user.Roles); err != nil {
+					log.Error(err, "Failed to reset user", "pod", podName, "user", user.Name)
+					failed++
+				} else {
+					log.Info("Reset user", "pod", podName, "user", user.Name)
+				}
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d users could not be reset, please see log for details", failed)
+	}
+	return nil
+}
+
+// getRunningBrokers returns a management client for each running pod of the statefulset of
+// the fsm, keyed by pod name, using the admin credentials of the deployment
+func getRunningBrokers(fsm *ActiveMQArtemisFSM, c client.Client) (map[string]*brokerclient.Client, error) {
+
+	ssNamespacedName := fsm.GetStatefulSetNamespacedName()
+	statefulset := &appsv1.StatefulSet{}
+	if err := c.Get(context.TODO(), ssNamespacedName, statefulset); err != nil {
+		return nil, err
+	}
+
+	credentials := &corev1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Namespace: ssNamespacedName.Namespace, Name: fsm.GetCredentialsSecretName()}, credentials); err != nil {
+		return nil, err
+	}
+	user := string(credentials.Data["AMQ_USER"])
+	password := string(credentials.Data["AMQ_PASSWORD"])
+	protocol := "http"
+	if fsm.customResource.Spec.Console.SSLEnabled {
+		protocol = "https"
+	}
+
+	brokers := make(map[string]*brokerclient.Client)
+	replicas := 0
+	if statefulset.Spec.Replicas != nil {
+		replicas = int(*statefulset.Spec.Replicas)
+	}
+	for i := 0; i < replicas; i++ {
+		pod := &corev1.Pod{}
+		podNamespacedName := types.NamespacedName{Namespace: ssNamespacedName.Namespace, Name: statefulset.Name + "-" + strconv.Itoa(i)}
+		if err := c.Get(context.TODO(), podNamespacedName, pod); err != nil {
+			log.V(1).Info("Pod not found, skipping it", "pod", podNamespacedName, "error", err)
+			continue
+		}
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" {
+			continue
+		}
+		brokers[pod.Name] = brokerclient.GetClient(pod.Status.PodIP, "8161", "amq-broker", user, password, protocol)
+	}
+	return brokers, nil
+}
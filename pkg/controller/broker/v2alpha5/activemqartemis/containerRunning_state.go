@@ -4,9 +4,11 @@ import (
 	"context"
 	"strconv"
 
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/fsm"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -89,17 +91,25 @@ func (rs *ContainerRunningState) Update() (error, int) {
 			break
 		}
 
+		rs.parentFSM.updateReadyCondition(currentStatefulSet)
 		statefulSetUpdates, _, newss = reconciler.Process(rs.parentFSM, rs.parentFSM.r.client, rs.parentFSM.r.scheme, firstTime)
 		break
 	}
 
 	if statefulSetUpdates > 0 {
+		rs.parentFSM.SetCondition(brokerv2alpha5.ConditionTypeConfigApplied, corev1.ConditionFalse, ReasonRollingUpdate, "StatefulSet updated, rolling out broker pods")
+		if currentImage, newImage := brokerImageOf(currentStatefulSet), brokerImageOf(newss); currentImage != newImage {
+			rs.parentFSM.SetCondition(brokerv2alpha5.ConditionTypeUpgrading, corev1.ConditionTrue, ReasonImageChanged, "Changing broker image from "+currentImage+" to "+newImage)
+		}
 		//https://stackoverflow.com/questions/65987577/kubectl-apply-reports-error-operation-cannot-be-fulfilled-on-serviceaccounts
 		currentStatefulSet.ResourceVersion = ""
 		if err := resources.Update(rs.parentFSM.namespacedName, rs.parentFSM.r.client, newss); err != nil {
 			reqLogger.Error(err, "Failed to update StatefulSet.", "Deployment.Namespace", newss.Namespace, "Deployment.Name", newss.Name)
 		}
 		nextStateID = ScalingID
+	} else if nextStateID == ContainerRunningID {
+		rs.parentFSM.SetCondition(brokerv2alpha5.ConditionTypeConfigApplied, corev1.ConditionTrue, ReasonConfigApplied, "")
+		rs.parentFSM.SetCondition(brokerv2alpha5.ConditionTypeUpgrading, corev1.ConditionFalse, ReasonNoUpgrade, "")
 	}
 
 	return err, nextStateID
@@ -3,6 +3,7 @@ package v2alpha5activemqartemis
 import (
 	"context"
 
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/pods"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
 	svc "github.com/artemiscloud/activemq-artemis-operator/pkg/resources/services"
@@ -132,6 +133,10 @@ func (rs *CreatingK8sResourcesState) Enter(previousStateID int) error {
 	_, stepsComplete, _ = reconciler.Process(rs.parentFSM, rs.parentFSM.r.client, rs.parentFSM.r.scheme, firstTime)
 	rs.stepsComplete = stepsComplete
 
+	if rs.stepsComplete&CreatedStatefulSet > 0 {
+		rs.parentFSM.SetCondition(brokerv2alpha5.ConditionTypeDeployed, corev1.ConditionTrue, ReasonStatefulSetCreated, "")
+	}
+
 	return nil
 }
 
@@ -150,10 +155,12 @@ func (rs *CreatingK8sResourcesState) Update() (error, int) {
 	for {
 		if err != nil && errors.IsNotFound(err) {
 			reqLogger.Error(err, "Failed to get StatefulSet.", "Deployment.Namespace", currentStatefulSet.Namespace, "Deployment.Name", currentStatefulSet.Name)
+			rs.parentFSM.SetCondition(brokerv2alpha5.ConditionTypeDeployed, corev1.ConditionFalse, ReasonStatefulSetNotFound, "StatefulSet "+ssNamespacedName.Name+" not found")
 			err = nil
 			break
 		} else {
 			rs.stepsComplete |= CreatedStatefulSet
+			rs.parentFSM.SetCondition(brokerv2alpha5.ConditionTypeDeployed, corev1.ConditionTrue, ReasonStatefulSetCreated, "")
 		}
 
 		// Do we need to check for and bounce an observed generation change here?
@@ -161,6 +168,7 @@ func (rs *CreatingK8sResourcesState) Update() (error, int) {
 			firstTime := false
 
 			_, _, _ = reconciler.Process(rs.parentFSM, rs.parentFSM.r.client, rs.parentFSM.r.scheme, firstTime)
+			rs.parentFSM.updateReadyCondition(currentStatefulSet)
 			if rs.parentFSM.customResource.Spec.DeploymentPlan.Size != currentStatefulSet.Status.ReadyReplicas {
 				if rs.parentFSM.customResource.Spec.DeploymentPlan.Size > 0 {
 					nextStateID = ScalingID
@@ -2,12 +2,15 @@ package v2alpha5activemqartemis
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/fsm"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -83,11 +86,20 @@ func (ss *ScalingState) Update() (error, int) {
 			break
 		}
 
-		if (*currentStatefulSet.Spec.Replicas == currentStatefulSet.Status.ReadyReplicas) &&
-			(0 == strings.Compare(currentStatefulSet.Status.CurrentRevision, currentStatefulSet.Status.UpdateRevision)) {
+		ss.parentFSM.updateReadyCondition(currentStatefulSet)
+		revisionsMatch := 0 == strings.Compare(currentStatefulSet.Status.CurrentRevision, currentStatefulSet.Status.UpdateRevision)
+		if !revisionsMatch {
+			ss.parentFSM.SetCondition(brokerv2alpha5.ConditionTypeConfigApplied, corev1.ConditionFalse, ReasonRollingUpdate,
+				fmt.Sprintf("%d/%d broker pods updated to revision %s", currentStatefulSet.Status.UpdatedReplicas, *currentStatefulSet.Spec.Replicas, currentStatefulSet.Status.UpdateRevision))
+		}
+
+		if (*currentStatefulSet.Spec.Replicas == currentStatefulSet.Status.ReadyReplicas) && revisionsMatch {
 			ss.parentFSM.r.result = reconcile.Result{Requeue: true}
 			reqLogger.Info("ScalingState requesting reconcile requeue for immediate reissue due to scaling completion")
 
+			ss.parentFSM.SetCondition(brokerv2alpha5.ConditionTypeConfigApplied, corev1.ConditionTrue, ReasonConfigApplied, "")
+			ss.parentFSM.SetCondition(brokerv2alpha5.ConditionTypeUpgrading, corev1.ConditionFalse, ReasonNoUpgrade, "")
+
 			if 0 == *currentStatefulSet.Spec.Replicas {
 				nextStateID = CreatingK8sResourcesID
 				break
@@ -19,6 +19,7 @@ package draincontroller
 import (
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -44,6 +45,7 @@ import (
 	"strings"
 
 	brokerv2alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha1"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/metrics"
 	rbacutil "github.com/artemiscloud/activemq-artemis-operator/pkg/rbac"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources"
 	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/secrets"
@@ -66,12 +68,16 @@ const DrainRoleName = "drain-pod-role"
 
 const (
 	SuccessCreate    = "SuccessfulCreate"
+	DrainStarted     = "DrainStarted"
 	DrainSuccess     = "DrainSuccess"
+	DrainFailed      = "DrainFailed"
 	PVCDeleteSuccess = "SuccessfulPVCDelete"
 	PodDeleteSuccess = "SuccessfulDelete"
 
 	MessageDrainPodCreated  = "create Drain Pod %s in StatefulSet %s successful"
+	MessageDrainPodStarted  = "drain Pod %s in StatefulSet %s started migrating the messages of the scaled down broker"
 	MessageDrainPodFinished = "drain Pod %s in StatefulSet %s completed successfully"
+	MessageDrainPodFailed   = "drain Pod %s in StatefulSet %s failed to migrate the messages of the scaled down broker"
 	MessageDrainPodDeleted  = "delete Drain Pod %s in StatefulSet %s successful"
 	MessagePVCDeleted       = "delete Claim %s in StatefulSet %s successful"
 )
@@ -111,6 +117,9 @@ type Controller struct {
 	stopCh chan struct{}
 
 	client client.Client
+
+	// the failed drain pods already counted, they stay around until the next drain
+	failedDrainPods sync.Map
 }
 
 // NewController returns a new sample controller
@@ -482,8 +491,11 @@ func (c *Controller) processStatefulSet(sts *appsv1.StatefulSet) error {
 					return err
 				}
 
+				metrics.DrainPodStarted(sts.Namespace, sts.Name)
+
 				if !c.localOnly {
 					c.recorder.Event(sts, corev1.EventTypeNormal, SuccessCreate, fmt.Sprintf(MessageDrainPodCreated, podName, sts.Name))
+					c.recordOwnerEvent(sts, corev1.EventTypeNormal, DrainStarted, fmt.Sprintf(MessageDrainPodStarted, podName, sts.Name))
 				}
 
 				continue
@@ -606,12 +618,21 @@ func (c *Controller) cleanUpDrainPodIfNeeded(sts *appsv1.StatefulSet, pod *corev
 		if err != nil {
 			return err
 		}
+		metrics.DrainPodSucceeded(sts.Namespace, sts.Name)
 		if !c.localOnly {
 			c.recorder.Event(sts, corev1.EventTypeNormal, PodDeleteSuccess, fmt.Sprintf(MessageDrainPodDeleted, podName, sts.Name))
+			c.recordOwnerEvent(sts, corev1.EventTypeNormal, DrainSuccess, fmt.Sprintf(MessageDrainPodFinished, podName, sts.Name))
 		}
 		break
 	case (corev1.PodFailed):
 		log.Info("Drain pod " + podName + " failed.")
+		if _, counted := c.failedDrainPods.LoadOrStore(pod.UID, true); !counted {
+			metrics.DrainPodFailed(sts.Namespace, sts.Name)
+			if !c.localOnly {
+				c.recorder.Event(sts, corev1.EventTypeWarning, DrainFailed, fmt.Sprintf(MessageDrainPodFailed, podName, sts.Name))
+				c.recordOwnerEvent(sts, corev1.EventTypeWarning, DrainFailed, fmt.Sprintf(MessageDrainPodFailed, podName, sts.Name))
+			}
+		}
 		break
 	default:
 		str := fmt.Sprintf("Drain pod Phase was %s", pod.Status.Phase)
@@ -622,6 +643,24 @@ func (c *Controller) cleanUpDrainPodIfNeeded(sts *appsv1.StatefulSet, pod *corev
 	return nil
 }
 
+// recordOwnerEvent records an event on the ActiveMQArtemis owning the statefulset, where
+// the drains of its brokers are looked for
+func (c *Controller) recordOwnerEvent(sts *appsv1.StatefulSet, eventType string, reason string, message string) {
+	for _, owner := range sts.OwnerReferences {
+		if owner.Kind != "ActiveMQArtemis" {
+			continue
+		}
+		ref := &corev1.ObjectReference{
+			APIVersion: owner.APIVersion,
+			Kind:       owner.Kind,
+			Name:       owner.Name,
+			Namespace:  sts.Namespace,
+			UID:        owner.UID,
+		}
+		c.recorder.Event(ref, eventType, reason, message)
+	}
+}
+
 func isDrainPod(pod *corev1.Pod) bool {
 	return pod != nil && pod.ObjectMeta.Annotations[AnnotationStatefulSet] != ""
 }
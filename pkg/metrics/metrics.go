@@ -0,0 +1,159 @@
+// Package metrics holds the operator metrics, served along with the controller-runtime ones
+// on the metrics port of the manager.
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const namespace = "activemq_artemis_operator"
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Time taken to reconcile an ActiveMQArtemis",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"namespace", "name"})
+
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconcile_errors_total",
+		Help:      "Number of reconciles of an ActiveMQArtemis that returned an error",
+	}, []string{"namespace", "name"})
+
+	fsmState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "fsm_state",
+		Help:      "The state the ActiveMQArtemis is in, 1 for its current state",
+	}, []string{"namespace", "name", "state"})
+
+	jolokiaRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jolokia_requests_total",
+		Help:      "Number of jolokia requests made to the brokers",
+	}, []string{"operation"})
+
+	jolokiaRequestFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jolokia_request_failures_total",
+		Help:      "Number of jolokia requests made to the brokers that failed",
+	}, []string{"operation"})
+
+	drainPodsStarted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "drain_pods_started_total",
+		Help:      "Number of drain pods created to migrate the messages of scaled down brokers",
+	}, []string{"namespace", "statefulset"})
+
+	drainPodsSucceeded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "drain_pods_succeeded_total",
+		Help:      "Number of drain pods that migrated the messages of a scaled down broker",
+	}, []string{"namespace", "statefulset"})
+
+	drainPodsFailed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "drain_pods_failed_total",
+		Help:      "Number of drain pods that failed to migrate the messages of a scaled down broker",
+	}, []string{"namespace", "statefulset"})
+
+	statefulSetRolls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "statefulset_rolls_total",
+		Help:      "Number of rolling restarts of the brokers triggered by the operator",
+	}, []string{"namespace", "name"})
+)
+
+// The current state of each ActiveMQArtemis, so that the gauge of its previous state is
+// removed when it changes
+var (
+	statesMutex sync.Mutex
+	states      = make(map[[2]string]string)
+)
+
+func init() {
+	crmetrics.Registry.MustRegister(
+		reconcileDuration,
+		reconcileErrors,
+		fsmState,
+		jolokiaRequests,
+		jolokiaRequestFailures,
+		drainPodsStarted,
+		drainPodsSucceeded,
+		drainPodsFailed,
+		statefulSetRolls,
+	)
+}
+
+// ObserveReconcile records the duration of a reconcile of an ActiveMQArtemis and whether it
+// failed
+func ObserveReconcile(namespace string, name string, duration time.Duration, err error) {
+	reconcileDuration.WithLabelValues(namespace, name).Observe(duration.Seconds())
+	if err != nil {
+		reconcileErrors.WithLabelValues(namespace, name).Inc()
+	}
+}
+
+// SetFSMState records the state an ActiveMQArtemis is in
+func SetFSMState(namespace string, name string, state string) {
+	statesMutex.Lock()
+	defer statesMutex.Unlock()
+
+	key := [2]string{namespace, name}
+	if previous, found := states[key]; found {
+		if previous == state {
+			return
+		}
+		fsmState.DeleteLabelValues(namespace, name, previous)
+	}
+	states[key] = state
+	fsmState.WithLabelValues(namespace, name, state).Set(1)
+}
+
+// DeleteBroker removes the metrics of an ActiveMQArtemis that was deleted
+func DeleteBroker(namespace string, name string) {
+	statesMutex.Lock()
+	defer statesMutex.Unlock()
+
+	key := [2]string{namespace, name}
+	if state, found := states[key]; found {
+		fsmState.DeleteLabelValues(namespace, name, state)
+		delete(states, key)
+	}
+	reconcileDuration.DeleteLabelValues(namespace, name)
+	reconcileErrors.DeleteLabelValues(namespace, name)
+	statefulSetRolls.DeleteLabelValues(namespace, name)
+}
+
+// ObserveJolokiaRequest records a jolokia request made to a broker and whether it failed
+func ObserveJolokiaRequest(operation string, err error) {
+	jolokiaRequests.WithLabelValues(operation).Inc()
+	if err != nil {
+		jolokiaRequestFailures.WithLabelValues(operation).Inc()
+	}
+}
+
+// DrainPodStarted records the creation of a drain pod for a statefulset
+func DrainPodStarted(namespace string, statefulSet string) {
+	drainPodsStarted.WithLabelValues(namespace, statefulSet).Inc()
+}
+
+// DrainPodSucceeded records a drain pod of a statefulset that completed
+func DrainPodSucceeded(namespace string, statefulSet string) {
+	drainPodsSucceeded.WithLabelValues(namespace, statefulSet).Inc()
+}
+
+// DrainPodFailed records a drain pod of a statefulset that failed
+func DrainPodFailed(namespace string, statefulSet string) {
+	drainPodsFailed.WithLabelValues(namespace, statefulSet).Inc()
+}
+
+// StatefulSetRollTriggered records a rolling restart of the brokers of an ActiveMQArtemis
+func StatefulSetRollTriggered(namespace string, name string) {
+	statefulSetRolls.WithLabelValues(namespace, name).Inc()
+}
@@ -0,0 +1,129 @@
+// Package migration rewrites the stored broker custom resources at the storage version of
+// their CRD, so that the versions they were first written at can be retired.
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("migration")
+
+var crdGVK = schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
+
+// The CRDs with more than one version. The versions of ActiveMQArtemis convert through the
+// webhook, those of ActiveMQArtemisAddress only add fields from one version to the next and
+// their CRD converts without it.
+var crdNames = []string{
+	"activemqartemises.broker.amq.io",
+	"activemqartemisaddresses.broker.amq.io",
+}
+
+type storageVersionMigrator struct {
+	client    client.Client
+	namespace string
+	ready     <-chan struct{}
+}
+
+// AddToManager migrates the custom resources in namespace, "" for all namespaces, once ready
+// is closed. Reading the custom resources stored at an older version at the storage version
+// goes through the conversion webhook, ready tells it is in place. The stored versions of a
+// CRD are only trimmed down to its storage version once every custom resource in the cluster
+// has been rewritten, so that needs all namespaces.
+func AddToManager(mgr manager.Manager, namespace string, ready <-chan struct{}) error {
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return err
+	}
+	m := &storageVersionMigrator{client: c, namespace: namespace, ready: ready}
+	return mgr.Add(manager.RunnableFunc(m.Start))
+}
+
+// Start migrates each CRD in turn, failures are logged rather than stopping the operator
+func (m *storageVersionMigrator) Start(stop <-chan struct{}) error {
+	select {
+	case <-m.ready:
+	case <-stop:
+		return nil
+	}
+	for _, name := range crdNames {
+		if err := m.migrate(name); err != nil {
+			log.Error(err, "Storage version migration failed", "crd", name)
+		}
+	}
+	return nil
+}
+
+func (m *storageVersionMigrator) migrate(crdName string) error {
+
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := m.client.Get(context.TODO(), types.NamespacedName{Name: crdName}, crd); err != nil {
+		return err
+	}
+
+	storageVersion, err := getStorageVersion(crd)
+	if err != nil {
+		return err
+	}
+	storedVersions, _, _ := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	if len(storedVersions) == 1 && storedVersions[0] == storageVersion {
+		log.V(1).Info("Nothing to migrate", "crd", crdName, "storageVersion", storageVersion)
+		return nil
+	}
+	log.Info("Migrating custom resources to the storage version", "crd", crdName, "storedVersions", storedVersions, "storageVersion", storageVersion)
+
+	group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+	listKind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "listKind")
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{Group: group, Version: storageVersion, Kind: listKind})
+	if err := m.client.List(context.TODO(), &client.ListOptions{Namespace: m.namespace}, list); err != nil {
+		return err
+	}
+
+	// an update without changes is enough for the api server to write the object
+	// again at the storage version
+	failed := 0
+	for i := range list.Items {
+		item := &list.Items[i]
+		if err := m.client.Update(context.TODO(), item); err != nil {
+			log.Error(err, "Failed to migrate custom resource", "crd", crdName, "namespace", item.GetNamespace(), "name", item.GetName())
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d custom resources were not migrated", failed, len(list.Items))
+	}
+	log.Info("Migrated custom resources", "crd", crdName, "count", len(list.Items))
+
+	if m.namespace != "" {
+		log.Info("Not watching all namespaces, leaving the stored versions as they are", "crd", crdName)
+		return nil
+	}
+	if err := unstructured.SetNestedStringSlice(crd.Object, []string{storageVersion}, "status", "storedVersions"); err != nil {
+		return err
+	}
+	return m.client.Status().Update(context.TODO(), crd)
+}
+
+func getStorageVersion(crd *unstructured.Unstructured) (string, error) {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if storage, _, _ := unstructured.NestedBool(version, "storage"); storage {
+			name, _, _ := unstructured.NestedString(version, "name")
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("crd %s has no storage version", crd.GetName())
+}
@@ -0,0 +1,32 @@
+package horizontalpodautoscalers
+
+import (
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NewHorizontalPodAutoscalerForCR returns a horizontal pod autoscaler scaling the target
+// through its scale subresource between minReplicas and maxReplicas
+func NewHorizontalPodAutoscalerForCR(namespacedName types.NamespacedName, labels map[string]string, target autoscalingv2beta1.CrossVersionObjectReference, minReplicas *int32, maxReplicas int32, metrics []autoscalingv2beta1.MetricSpec) *autoscalingv2beta1.HorizontalPodAutoscaler {
+
+	hpa := &autoscalingv2beta1.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "autoscaling/v2beta1",
+			Kind:       "HorizontalPodAutoscaler",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    labels,
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+		},
+		Spec: autoscalingv2beta1.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: target,
+			MinReplicas:    minReplicas,
+			MaxReplicas:    maxReplicas,
+			Metrics:        metrics,
+		},
+	}
+
+	return hpa
+}
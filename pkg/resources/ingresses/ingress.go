@@ -12,6 +12,9 @@ import (
 
 var log = logf.Log.WithName("package ingresses")
 
+// IngressClassAnnotation selects the ingress controller serving an ingress
+const IngressClassAnnotation = "kubernetes.io/ingress.class"
+
 //leave this for backward compatibility
 func NewIngressForCR(namespacedName types.NamespacedName, labels map[string]string, targetServiceName string, targetPortName string) *extv1b1.Ingress {
 	return NewIngressForCRWithSSL(namespacedName, labels, targetServiceName, targetPortName, false)
@@ -21,19 +24,56 @@ func NewIngressForCR(namespacedName types.NamespacedName, labels map[string]stri
 //func NewIngressForCR(cr *v2alpha1.ActiveMQArtemis, target string) *extv1b1.Ingress {
 func NewIngressForCRWithSSL(namespacedName types.NamespacedName, labels map[string]string, targetServiceName string, targetPortName string, sslEnabled bool) *extv1b1.Ingress {
 
+	options := IngressOptions{}
+	if sslEnabled {
+		//ingress assumes TLS termination at the ingress point and uses default https port 443
+		//the host is left unset for the ingress to serve any host the certificate is valid for
+		options.TLSSecret = "console-secret"
+	}
+	return NewIngressForCRWithOptions(namespacedName, labels, targetServiceName, targetPortName, options)
+}
+
+// IngressOptions are the host, class, annotations and tls of an ingress
+type IngressOptions struct {
+	Host string
+	// ClassName of the ingress controller, set as the ingress class annotation that the
+	// extensions/v1beta1 ingress has in place of a field until the client-go upgrade
+	ClassName   string
+	Annotations map[string]string
+	// TLSSecret holds the certificate the ingress terminates tls with for the host
+	TLSSecret string
+}
+
+// NewIngressForCRWithOptions returns the ingress routing the host to the port of the target
+// service
+func NewIngressForCRWithOptions(namespacedName types.NamespacedName, labels map[string]string, targetServiceName string, targetPortName string, options IngressOptions) *extv1b1.Ingress {
+
+	var annotations map[string]string
+	if len(options.Annotations) > 0 || options.ClassName != "" {
+		annotations = make(map[string]string)
+		for key, value := range options.Annotations {
+			annotations[key] = value
+		}
+		if options.ClassName != "" {
+			annotations[IngressClassAnnotation] = options.ClassName
+		}
+	}
+
 	ingress := &extv1b1.Ingress{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "extensions/v1beta1",
 			Kind:       "Ingress",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Labels:    labels,
-			Name:      targetServiceName + "-ing",
-			Namespace: namespacedName.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+			Name:        targetServiceName + "-ing",
+			Namespace:   namespacedName.Namespace,
 		},
 		Spec: extv1b1.IngressSpec{
 			Rules: []extv1b1.IngressRule{
 				{
+					Host: options.Host,
 					IngressRuleValue: extv1b1.IngressRuleValue{
 						HTTP: &extv1b1.HTTPIngressRuleValue{
 							Paths: []extv1b1.HTTPIngressPath{
@@ -51,19 +91,14 @@ func NewIngressForCRWithSSL(namespacedName types.NamespacedName, labels map[stri
 			},
 		},
 	}
-	if sslEnabled {
-		//ingress assumes TLS termination at the ingress point and uses default https port 443
-		//it requires a host name that matches the certificate's CN value
-		ingress.Spec.Rules[0].Host = "www.mgmtconsole.com"
-		tls := []extv1b1.IngressTLS{
-			{
-				Hosts: []string{
-					"www.mgmtconsole.com",
-				},
-				SecretName: "console-secret",
-			},
+	if options.TLSSecret != "" {
+		tls := extv1b1.IngressTLS{
+			SecretName: options.TLSSecret,
+		}
+		if options.Host != "" {
+			tls.Hosts = []string{options.Host}
 		}
-		ingress.Spec.TLS = tls
+		ingress.Spec.TLS = []extv1b1.IngressTLS{tls}
 	}
 	return ingress
 }
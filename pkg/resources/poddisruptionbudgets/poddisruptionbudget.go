@@ -0,0 +1,34 @@
+package poddisruptionbudgets
+
+import (
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// NewPodDisruptionBudgetForCR returns a pod disruption budget of the pods matching the
+// selector labels, only one of minAvailable and maxUnavailable is expected to be set
+func NewPodDisruptionBudgetForCR(namespacedName types.NamespacedName, labels map[string]string, selectorLabels map[string]string, minAvailable *intstr.IntOrString, maxUnavailable *intstr.IntOrString) *policyv1beta1.PodDisruptionBudget {
+
+	pdb := &policyv1beta1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "policy/v1beta1",
+			Kind:       "PodDisruptionBudget",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    labels,
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable:   minAvailable,
+			MaxUnavailable: maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: selectorLabels,
+			},
+		},
+	}
+
+	return pdb
+}
@@ -0,0 +1,46 @@
+package pods
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// The volumes the operator adds to every broker pod, the broker config is generated into
+// the first by the init container from the tools it copies into the second
+const (
+	ConfigVolumeName = "amq-cfg-dir"
+	ToolVolumeName   = "tool-dir"
+)
+
+// ValidatePodTemplateOverride checks the pod template override does not set the operator
+// managed parts of the pod template: the init container, the broker container other than
+// its env vars and volume mounts, and the config volumes. Both the admission webhook and
+// the controller, which gets the CRs the webhook didn't see, check overrides with it.
+func ValidatePodTemplateOverride(path *field.Path, podTemplate *corev1.PodTemplateSpec, brokerContainerName string) field.ErrorList {
+	var errs field.ErrorList
+	specPath := path.Child("spec")
+	if len(podTemplate.Spec.InitContainers) > 0 {
+		errs = append(errs, field.Forbidden(specPath.Child("initContainers"), "the init container is managed by the operator"))
+	}
+	for i, container := range podTemplate.Spec.Containers {
+		if container.Name != brokerContainerName {
+			continue
+		}
+		rest := container.DeepCopy()
+		rest.Env = nil
+		rest.EnvFrom = nil
+		rest.VolumeMounts = nil
+		if !equality.Semantic.DeepEqual(rest, &corev1.Container{Name: brokerContainerName}) {
+			errs = append(errs, field.Forbidden(specPath.Child("containers").Index(i), "only env, envFrom and volumeMounts may be set on the broker container"))
+		}
+	}
+	for i, volume := range podTemplate.Spec.Volumes {
+		if volume.Name == ConfigVolumeName || volume.Name == ToolVolumeName {
+			errs = append(errs, field.Forbidden(specPath.Child("volumes").Index(i).Child("name"), fmt.Sprintf("volume %s is managed by the operator", volume.Name)))
+		}
+	}
+	return errs
+}
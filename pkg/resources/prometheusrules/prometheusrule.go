@@ -0,0 +1,28 @@
+package prometheusrules
+
+import (
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NewPrometheusRuleForCR returns a prometheus rule of the rule groups
+func NewPrometheusRuleForCR(namespacedName types.NamespacedName, labels map[string]string, groups []monitoringv1.RuleGroup) *monitoringv1.PrometheusRule {
+
+	rule := &monitoringv1.PrometheusRule{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: monitoringv1.SchemeGroupVersion.String(),
+			Kind:       monitoringv1.PrometheusRuleKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    labels,
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+		},
+		Spec: monitoringv1.PrometheusRuleSpec{
+			Groups: groups,
+		},
+	}
+
+	return rule
+}
@@ -21,7 +21,7 @@ func NewRouteDefinitionForCR(namespacedName types.NamespacedName, labels map[str
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Labels:    labels,
-			Name:      targetServiceName + "-rte",
+			Name:      RouteName(targetServiceName),
 			Namespace: namespacedName.Namespace,
 		},
 		Spec: routev1.RouteSpec{
@@ -44,3 +44,8 @@ func NewRouteDefinitionForCR(namespacedName types.NamespacedName, labels map[str
 
 	return route
 }
+
+// RouteName returns the name of the route exposing the target service
+func RouteName(targetServiceName string) string {
+	return targetServiceName + "-rte"
+}
@@ -0,0 +1,32 @@
+package servicemonitors
+
+import (
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// NewServiceMonitorForCR returns a service monitor scraping the endpoint of the services
+// matching the selector labels
+func NewServiceMonitorForCR(namespacedName types.NamespacedName, labels map[string]string, selectorLabels map[string]string, endpoint monitoringv1.Endpoint) *monitoringv1.ServiceMonitor {
+
+	serviceMonitor := &monitoringv1.ServiceMonitor{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: monitoringv1.SchemeGroupVersion.String(),
+			Kind:       monitoringv1.ServiceMonitorsKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    labels,
+			Name:      namespacedName.Name,
+			Namespace: namespacedName.Namespace,
+		},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Endpoints: []monitoringv1.Endpoint{endpoint},
+			Selector: metav1.LabelSelector{
+				MatchLabels: selectorLabels,
+			},
+		},
+	}
+
+	return serviceMonitor
+}
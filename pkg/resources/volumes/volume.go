@@ -127,6 +127,15 @@ func MakeVolume(secretName string) corev1.Volume {
 	return volume
 }
 
+// MakeOptionalVolume returns the volume of a secret the pods start without until it exists
+func MakeOptionalVolume(secretName string) corev1.Volume {
+
+	volume := MakeVolume(secretName)
+	optional := true
+	volume.Secret.Optional = &optional
+	return volume
+}
+
 //func makePersistentVolume(cr *brokerv2alpha1.ActiveMQArtemis) []corev1.Volume {
 func MakePersistentVolume(customResourceName string) []corev1.Volume {
 
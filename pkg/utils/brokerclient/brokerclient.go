@@ -0,0 +1,420 @@
+package brokerclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	mgmt "github.com/artemiscloud/activemq-artemis-management"
+	"github.com/artemiscloud/activemq-artemis-management/jolokia"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+)
+
+var log = logf.Log.WithName("brokerclient")
+
+const JolokiaPath = "/console/jolokia"
+
+// Client is a management client for a single broker. It embeds the client from
+// activemq-artemis-management, which covers address and queue creation, and adds
+// typed operational calls on top. Unlike the embedded client it decodes any kind
+// of jolokia value, not only strings.
+type Client struct {
+	*mgmt.Artemis
+	brokerName string
+	url        string
+	user       string
+	password   string
+	httpClient *http.Client
+}
+
+// Queue identifies a queue on the broker, the routing type is part of its mbean name
+type Queue struct {
+	Address     string `json:"address"`
+	Name        string `json:"name"`
+	RoutingType string `json:"routingType"`
+}
+
+// TopologyMember is one live/backup pair as reported by listNetworkTopology
+type TopologyMember struct {
+	NodeID string `json:"nodeID"`
+	Live   string `json:"live,omitempty"`
+	Backup string `json:"backup,omitempty"`
+}
+
+// QueueAttributes holds the attributes of a QueueControl mbean
+type QueueAttributes struct {
+	Name                    string `json:"Name"`
+	Address                 string `json:"Address"`
+	RoutingType             string `json:"RoutingType"`
+	Filter                  string `json:"Filter"`
+	User                    string `json:"User"`
+	Durable                 bool   `json:"Durable"`
+	Temporary               bool   `json:"Temporary"`
+	MaxConsumers            int32  `json:"MaxConsumers"`
+	Exclusive               bool   `json:"Exclusive"`
+	GroupRebalance          bool   `json:"GroupRebalance"`
+	GroupBuckets            int32  `json:"GroupBuckets"`
+	GroupFirstKey           string `json:"GroupFirstKey"`
+	LastValue               bool   `json:"LastValue"`
+	LastValueKey            string `json:"LastValueKey"`
+	NonDestructive          bool   `json:"NonDestructive"`
+	PurgeOnNoConsumers      bool   `json:"PurgeOnNoConsumers"`
+	Enabled                 bool   `json:"Enabled"`
+	ConsumersBeforeDispatch int32  `json:"ConsumersBeforeDispatch"`
+	DelayBeforeDispatch     int64  `json:"DelayBeforeDispatch"`
+	RingSize                int64  `json:"RingSize"`
+	ConfigurationManaged    bool   `json:"ConfigurationManaged"`
+	Paused                  bool   `json:"Paused"`
+	MessageCount            int64  `json:"MessageCount"`
+	ConsumerCount           int32  `json:"ConsumerCount"`
+	DeliveringCount         int32  `json:"DeliveringCount"`
+}
+
+type request struct {
+	Type      string        `json:"type"`
+	MBean     string        `json:"mbean"`
+	Attribute string        `json:"attribute,omitempty"`
+	Operation string        `json:"operation,omitempty"`
+	Arguments []interface{} `json:"arguments,omitempty"`
+}
+
+type response struct {
+	Status    int             `json:"status"`
+	Value     json.RawMessage `json:"value"`
+	ErrorType string          `json:"error_type"`
+	Error     string          `json:"error"`
+}
+
+// GetClient mirrors mgmt.GetArtemis, the broker name is the one used in the broker mbean
+func GetClient(ip string, jolokiaPort string, brokerName string, user string, password string, protocol string) *Client {
+
+	if user == "" {
+		user = "admin"
+	}
+	if password == "" {
+		password = "admin"
+	}
+	if protocol == "" {
+		protocol = "http"
+	}
+
+	httpClient := &http.Client{
+		Timeout: time.Second * 2,
+	}
+	if protocol == "https" {
+		httpClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		}
+	}
+
+	return &Client{
+		Artemis:    mgmt.GetArtemis(ip, jolokiaPort, brokerName, user, password, protocol),
+		brokerName: brokerName,
+		url:        protocol + "://" + ip + ":" + jolokiaPort + JolokiaPath,
+		user:       user,
+		password:   password,
+		httpClient: httpClient,
+	}
+}
+
+// IsUnreachable tells whether the error is one of reaching the broker rather than one
+// returned by it
+func IsUnreachable(err error) bool {
+	_, ok := err.(*url.Error)
+	return ok
+}
+
+// BrokerMBean returns the name of the broker mbean
+func (c *Client) BrokerMBean() string {
+	return `org.apache.activemq.artemis:broker="` + c.brokerName + `"`
+}
+
+// AddressMBean returns the name of the mbean for the given address
+func (c *Client) AddressMBean(address string) string {
+	return c.BrokerMBean() + `,component=addresses,address="` + address + `"`
+}
+
+// QueueMBean returns the name of the mbean for the given queue
+func (c *Client) QueueMBean(queue Queue) string {
+	return c.AddressMBean(queue.Address) + `,subcomponent=queues,routing-type="` + strings.ToLower(queue.RoutingType) + `",queue="` + queue.Name + `"`
+}
+
+// Read reads an attribute of an mbean into value
+func (c *Client) Read(mbean string, attribute string, value interface{}) error {
+	return c.do(request{Type: "read", MBean: mbean, Attribute: attribute}, value)
+}
+
+// Exec invokes an operation on an mbean, the result is decoded into value unless it is nil
+func (c *Client) Exec(mbean string, operation string, value interface{}, arguments ...interface{}) error {
+	if arguments == nil {
+		arguments = []interface{}{}
+	}
+	return c.do(request{Type: "exec", MBean: mbean, Operation: operation, Arguments: arguments}, value)
+}
+
+func (c *Client) do(req request, value interface{}) error {
+	err := c.doRequest(req, value)
+	metrics.ObserveJolokiaRequest(req.Type, err)
+	return err
+}
+
+func (c *Client) doRequest(req request, value interface{}) error {
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, c.url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("User-Agent", "activemq-artemis-operator")
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.SetBasicAuth(c.user, c.password)
+
+	log.V(1).Info("Jolokia request", "type", req.Type, "mbean", req.MBean, "attribute", req.Attribute, "operation", req.Operation)
+	res, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	resp := response{}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		if res.StatusCode < 200 || res.StatusCode > 299 {
+			return &jolokia.JolokiaError{HttpCode: res.StatusCode, Message: " Error: " + res.Status}
+		}
+		return err
+	}
+
+	jdata := &jolokia.ResponseData{
+		Status:    resp.Status,
+		Value:     string(resp.Value),
+		ErrorType: resp.ErrorType,
+		Error:     resp.Error,
+	}
+	if err := jolokia.CheckResponse(res, jdata); err != nil {
+		return err
+	}
+
+	if value != nil && len(resp.Value) > 0 {
+		if err := json.Unmarshal(resp.Value, value); err != nil {
+			return fmt.Errorf("failed to decode %s of %s: %v", req.Attribute+req.Operation, req.MBean, err)
+		}
+	}
+	return nil
+}
+
+// The calls of the embedded client are wrapped to be counted in the operator metrics
+
+// CreateAddress creates the address with the routing type
+func (c *Client) CreateAddress(addressName string, routingType string) (*jolokia.ResponseData, error) {
+	data, err := c.Artemis.CreateAddress(addressName, routingType)
+	metrics.ObserveJolokiaRequest("createAddress", err)
+	return data, err
+}
+
+// DeleteAddress deletes the address
+func (c *Client) DeleteAddress(addressName string) (*jolokia.ResponseData, error) {
+	data, err := c.Artemis.DeleteAddress(addressName)
+	metrics.ObserveJolokiaRequest("deleteAddress", err)
+	return data, err
+}
+
+// CreateQueue creates the queue on the address with the routing type
+func (c *Client) CreateQueue(addressName string, queueName string, routingType string) (*jolokia.ResponseData, error) {
+	data, err := c.Artemis.CreateQueue(addressName, queueName, routingType)
+	metrics.ObserveJolokiaRequest("createQueue", err)
+	return data, err
+}
+
+// CreateQueueFromConfig creates the queue of the json queue configuration
+func (c *Client) CreateQueueFromConfig(queueConfig string, ignoreIfExists bool) (*jolokia.ResponseData, error) {
+	data, err := c.Artemis.CreateQueueFromConfig(queueConfig, ignoreIfExists)
+	metrics.ObserveJolokiaRequest("createQueue", err)
+	return data, err
+}
+
+// UpdateQueue updates the queue of the json queue configuration
+func (c *Client) UpdateQueue(queueConfig string) (*jolokia.ResponseData, error) {
+	data, err := c.Artemis.UpdateQueue(queueConfig)
+	metrics.ObserveJolokiaRequest("updateQueue", err)
+	return data, err
+}
+
+// DeleteQueue deletes the queue
+func (c *Client) DeleteQueue(queueName string) (*jolokia.ResponseData, error) {
+	data, err := c.Artemis.DeleteQueue(queueName)
+	metrics.ObserveJolokiaRequest("deleteQueue", err)
+	return data, err
+}
+
+// ListBindingsForAddress lists the bindings of the address
+func (c *Client) ListBindingsForAddress(addressName string) (*jolokia.ResponseData, error) {
+	data, err := c.Artemis.ListBindingsForAddress(addressName)
+	metrics.ObserveJolokiaRequest("listBindingsForAddress", err)
+	return data, err
+}
+
+// GetVersion returns the broker version
+func (c *Client) GetVersion() (string, error) {
+	var version string
+	err := c.Read(c.BrokerMBean(), "Version", &version)
+	return version, err
+}
+
+// ListNetworkTopology returns the live/backup pairs the broker knows about
+func (c *Client) ListNetworkTopology() ([]TopologyMember, error) {
+	// the broker hands the topology back as a json string
+	var topologyJson string
+	if err := c.Exec(c.BrokerMBean(), "listNetworkTopology()", &topologyJson); err != nil {
+		return nil, err
+	}
+	var topology []TopologyMember
+	if err := json.Unmarshal([]byte(topologyJson), &topology); err != nil {
+		return nil, err
+	}
+	return topology, nil
+}
+
+// GetBrokerAttribute reads any attribute of the broker mbean into value
+func (c *Client) GetBrokerAttribute(attribute string, value interface{}) error {
+	return c.Read(c.BrokerMBean(), attribute, value)
+}
+
+// ListAddresses returns the names of all the addresses on the broker
+func (c *Client) ListAddresses() ([]string, error) {
+	var names []string
+	err := c.Read(c.BrokerMBean(), "AddressNames", &names)
+	return names, err
+}
+
+// ListQueues returns the names of all the queues on the broker
+func (c *Client) ListQueues() ([]string, error) {
+	var names []string
+	err := c.Read(c.BrokerMBean(), "QueueNames", &names)
+	return names, err
+}
+
+// FindQueue looks the queue up by name, returning nil when there is no such queue
+func (c *Client) FindQueue(queueName string) (*Queue, error) {
+
+	var mbeans []string
+	pattern := c.BrokerMBean() + `,component=addresses,address=*,subcomponent=queues,routing-type=*,queue="` + queueName + `"`
+	if err := c.do(request{Type: "search", MBean: pattern}, &mbeans); err != nil {
+		return nil, err
+	}
+	if len(mbeans) == 0 {
+		return nil, nil
+	}
+
+	queue := &Queue{Name: queueName}
+	for _, property := range strings.Split(strings.SplitN(mbeans[0], ":", 2)[1], ",") {
+		keyValue := strings.SplitN(property, "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		switch keyValue[0] {
+		case "address":
+			queue.Address = strings.Trim(keyValue[1], `"`)
+		case "routing-type":
+			queue.RoutingType = strings.ToUpper(strings.Trim(keyValue[1], `"`))
+		}
+	}
+	return queue, nil
+}
+
+// GetQueueAttributes reads all the attributes of the queue
+func (c *Client) GetQueueAttributes(queue Queue) (*QueueAttributes, error) {
+	attributes := &QueueAttributes{}
+	if err := c.Read(c.QueueMBean(queue), "", attributes); err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// PauseAddress stops delivery to all the queues of the address
+func (c *Client) PauseAddress(address string) error {
+	return c.Exec(c.AddressMBean(address), "pause()", nil)
+}
+
+// ResumeAddress resumes delivery to all the queues of the address
+func (c *Client) ResumeAddress(address string) error {
+	return c.Exec(c.AddressMBean(address), "resume()", nil)
+}
+
+// PauseQueue stops delivery of messages to the consumers of the queue
+func (c *Client) PauseQueue(queue Queue) error {
+	return c.Exec(c.QueueMBean(queue), "pause()", nil)
+}
+
+// ResumeQueue resumes delivery of messages to the consumers of the queue
+func (c *Client) ResumeQueue(queue Queue) error {
+	return c.Exec(c.QueueMBean(queue), "resume()", nil)
+}
+
+// IsQueuePaused tells whether delivery is paused on the queue
+func (c *Client) IsQueuePaused(queue Queue) (bool, error) {
+	var paused bool
+	err := c.Read(c.QueueMBean(queue), "Paused", &paused)
+	return paused, err
+}
+
+// PurgeQueue removes the messages matching the filter, all of them when the filter is empty.
+// It returns the number of messages removed.
+func (c *Client) PurgeQueue(queue Queue, filter string) (int, error) {
+	var removed int
+	err := c.Exec(c.QueueMBean(queue), "removeMessages(java.lang.String)", &removed, filter)
+	return removed, err
+}
+
+// MoveMessages moves the messages matching the filter to another queue, all of them when
+// the filter is empty. It returns the number of messages moved.
+func (c *Client) MoveMessages(queue Queue, filter string, otherQueueName string) (int, error) {
+	var moved int
+	err := c.Exec(c.QueueMBean(queue), "moveMessages(java.lang.String,java.lang.String)", &moved, filter, otherQueueName)
+	return moved, err
+}
+
+// GetMessageCount returns the number of messages currently in the queue
+func (c *Client) GetMessageCount(queue Queue) (int64, error) {
+	var count int64
+	err := c.Read(c.QueueMBean(queue), "MessageCount", &count)
+	return count, err
+}
+
+// GetConsumerCount returns the number of consumers on the queue
+func (c *Client) GetConsumerCount(queue Queue) (int, error) {
+	var count int
+	err := c.Read(c.QueueMBean(queue), "ConsumerCount", &count)
+	return count, err
+}
+
+// GetDeliveringCount returns the number of messages being delivered to consumers
+func (c *Client) GetDeliveringCount(queue Queue) (int, error) {
+	var count int
+	err := c.Read(c.QueueMBean(queue), "DeliveringCount", &count)
+	return count, err
+}
+
+// ResetUser sets the password and roles of a user of the properties login module of the
+// broker security domain. The broker writes them to its users and roles files, a module
+// configured with reload picks them up without a restart.
+func (c *Client) ResetUser(user string, password string, roles []string) error {
+	return c.Exec(c.BrokerMBean(), "resetUser(java.lang.String,java.lang.String,java.lang.String)", nil, user, password, strings.Join(roles, ","))
+}
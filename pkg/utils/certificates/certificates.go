@@ -0,0 +1,151 @@
+// Package certificates issues the certificates of the internal ca the brokers of a
+// deployment authenticate each other with over their cluster connections.
+package certificates
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"time"
+)
+
+// Certificates are backdated this much to be valid on nodes whose clocks are behind
+const clockSkew = 5 * time.Minute
+
+// NewCA returns a self-signed ca certificate and its key, valid for the given duration from now
+func NewCA(commonName string, now time.Time, validity time.Duration) (*x509.Certificate, crypto.Signer, error) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template, err := newTemplate(commonName, now, validity)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+
+	certificate, err := sign(template, template, key.Public(), key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certificate, key, nil
+}
+
+// NewCertificate returns a certificate of the dns names signed by the ca and its key, for
+// both server and client authentication, valid for the given duration from now
+func NewCertificate(ca *x509.Certificate, caKey crypto.Signer, commonName string, dnsNames []string, now time.Time, validity time.Duration) (*x509.Certificate, crypto.Signer, error) {
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template, err := newTemplate(commonName, now, validity)
+	if err != nil {
+		return nil, nil, err
+	}
+	template.DNSNames = dnsNames
+	template.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	if template.NotAfter.After(ca.NotAfter) {
+		template.NotAfter = ca.NotAfter
+	}
+
+	certificate, err := sign(template, ca, key.Public(), caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return certificate, key, nil
+}
+
+// NeedsRenewal tells whether less than a third of the lifetime of the certificate is left
+func NeedsRenewal(certificate *x509.Certificate, now time.Time) bool {
+	lifetime := certificate.NotAfter.Sub(certificate.NotBefore)
+	return now.After(certificate.NotAfter.Add(-lifetime / 3))
+}
+
+// EncodeCertificates returns the certificates pem encoded, one after the other
+func EncodeCertificates(certificates ...*x509.Certificate) []byte {
+	var data []byte
+	for _, certificate := range certificates {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})...)
+	}
+	return data
+}
+
+// EncodeKey returns the key pem encoded in PKCS#8
+func EncodeKey(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParseCertificates returns the pem encoded certificates of the data
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certificates []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, certificate)
+	}
+	return certificates, nil
+}
+
+// ParseKey returns the first pem encoded key of the data, in PKCS#8, PKCS#1 or SEC 1
+func ParseKey(data []byte) (crypto.Signer, error) {
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			signer, ok := key.(crypto.Signer)
+			if !ok {
+				return nil, errors.New("unsupported private key type")
+			}
+			return signer, nil
+		case "RSA PRIVATE KEY":
+			return x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			return x509.ParseECPrivateKey(block.Bytes)
+		}
+	}
+	return nil, errors.New("no private key found")
+}
+
+func newTemplate(commonName string, now time.Time, validity time.Duration) (*x509.Certificate, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	return &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    now.Add(-clockSkew),
+		NotAfter:     now.Add(validity),
+	}, nil
+}
+
+func sign(template *x509.Certificate, parent *x509.Certificate, publicKey crypto.PublicKey, signer crypto.Signer) (*x509.Certificate, error) {
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, publicKey, signer)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
@@ -0,0 +1,25 @@
+package escape
+
+import (
+	"strings"
+)
+
+// PropertyKey escapes the characters that end the key of a properties file line
+func PropertyKey(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "=", `\=`, ":", `\:`, " ", `\ `, "#", `\#`, "!", `\!`).Replace(s)
+}
+
+// PropertyValue escapes the characters a properties file would drop from a value, the
+// backslashes, the line breaks and the whitespace it starts with
+func PropertyValue(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`).Replace(s)
+	if strings.IndexAny(s, " \t\f") == 0 {
+		s = `\` + s
+	}
+	return s
+}
+
+// ShellQuote quotes a string as a single word of a shell command
+func ShellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
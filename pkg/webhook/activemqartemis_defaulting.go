@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+// activeMQArtemisDefaulter stores the defaults of a broker deployment in the CR as it is
+// created or updated, the controller does the same for CRs that got past it
+type activeMQArtemisDefaulter struct {
+	decoder types.Decoder
+}
+
+var _ admission.Handler = &activeMQArtemisDefaulter{}
+
+// Handle patches the CR in the admission request with its defaults
+func (h *activeMQArtemisDefaulter) Handle(ctx context.Context, req types.Request) types.Response {
+
+	cr := &brokerv2alpha5.ActiveMQArtemis{}
+	if err := h.decoder.Decode(req, cr); err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	defaulted := cr.DeepCopy()
+	brokerv2alpha5.SetObjectDefaults_ActiveMQArtemis(defaulted)
+	return admission.PatchResponse(cr, defaulted)
+}
+
+// InjectDecoder is called by the manager to hand over the admission request decoder
+func (h *activeMQArtemisDefaulter) InjectDecoder(d types.Decoder) error {
+	h.decoder = d
+	return nil
+}
@@ -0,0 +1,511 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/resources/pods"
+	"github.com/artemiscloud/activemq-artemis-operator/version"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var journalTypes = []string{"nio", "aio"}
+var applyRules = []string{"replace_all", "merge_replace", "merge_all"}
+var addressFullPolicies = []string{"PAGE", "BLOCK", "DROP", "FAIL"}
+var slowConsumerPolicies = []string{"KILL", "NOTIFY"}
+var routingTypes = []string{"ANYCAST", "MULTICAST"}
+var accessModes = []string{"ReadWriteOnce", "ReadOnlyMany", "ReadWriteMany"}
+var dataDirectories = []string{"journal", "bindings", "paging", "largeMessages"}
+var exposeModes = []string{
+	string(brokerv2alpha5.ExposeModeRoute),
+	string(brokerv2alpha5.ExposeModeIngress),
+	string(brokerv2alpha5.ExposeModeLoadBalancer),
+	string(brokerv2alpha5.ExposeModeNodePort),
+}
+
+var issuerKinds = []string{"Issuer", "ClusterIssuer"}
+var consoleOAuthProviders = []string{brokerv2alpha5.ConsoleOAuthProviderOpenShift, brokerv2alpha5.ConsoleOAuthProviderOIDC}
+
+func validateActiveMQArtemis(obj runtime.Object, c client.Client) field.ErrorList {
+	return ValidateActiveMQArtemis(obj.(*brokerv2alpha5.ActiveMQArtemis), c)
+}
+
+func validateActiveMQArtemisUpdate(old runtime.Object, obj runtime.Object) field.ErrorList {
+	return ValidateActiveMQArtemisUpdate(old.(*brokerv2alpha5.ActiveMQArtemis), obj.(*brokerv2alpha5.ActiveMQArtemis))
+}
+
+// ValidateActiveMQArtemisUpdate checks an update of a broker deployment keeps the data
+// directories that have a volume of their own. The claims of the brokers can't be added to
+// or removed, a directory moved on or off a volume would leave its data behind.
+func ValidateActiveMQArtemisUpdate(old *brokerv2alpha5.ActiveMQArtemis, cr *brokerv2alpha5.ActiveMQArtemis) field.ErrorList {
+
+	if !old.Spec.DeploymentPlan.PersistenceEnabled {
+		return nil
+	}
+	oldDirectories := make(map[string]bool)
+	for _, volume := range old.Spec.DeploymentPlan.Storage.Volumes {
+		oldDirectories[volume.Directory] = true
+	}
+	directories := make(map[string]bool)
+	for _, volume := range cr.Spec.DeploymentPlan.Storage.Volumes {
+		directories[volume.Directory] = true
+	}
+	if !equality.Semantic.DeepEqual(oldDirectories, directories) {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec", "deploymentPlan", "storage", "volumes"),
+			"the directories on volumes of their own can't change once the broker is deployed, their data would be left behind")}
+	}
+	return nil
+}
+
+// ValidateActiveMQArtemis checks the spec of a broker deployment. When c is not nil the
+// secrets of ssl enabled acceptors, connectors and console are checked too.
+func ValidateActiveMQArtemis(cr *brokerv2alpha5.ActiveMQArtemis, c client.Client) field.ErrorList {
+
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	spec := &cr.Spec
+
+	planPath := specPath.Child("deploymentPlan")
+	if spec.DeploymentPlan.Size < 0 {
+		errs = append(errs, field.Invalid(planPath.Child("size"), spec.DeploymentPlan.Size, "must not be negative"))
+	}
+	if spec.DeploymentPlan.JournalType != "" && !containsFold(journalTypes, spec.DeploymentPlan.JournalType) {
+		errs = append(errs, field.NotSupported(planPath.Child("journalType"), spec.DeploymentPlan.JournalType, journalTypes))
+	}
+	if spec.DeploymentPlan.Storage.Size != "" {
+		if _, err := resource.ParseQuantity(spec.DeploymentPlan.Storage.Size); err != nil {
+			errs = append(errs, field.Invalid(planPath.Child("storage", "size"), spec.DeploymentPlan.Storage.Size, err.Error()))
+		}
+	}
+	for i, mode := range spec.DeploymentPlan.Storage.AccessModes {
+		if !contains(accessModes, string(mode)) {
+			errs = append(errs, field.NotSupported(planPath.Child("storage", "accessModes").Index(i), mode, accessModes))
+		}
+	}
+	if selector := spec.DeploymentPlan.Storage.Selector; selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			errs = append(errs, field.Invalid(planPath.Child("storage", "selector"), selector, err.Error()))
+		}
+	}
+	directories := make(map[string]bool)
+	for i, volume := range spec.DeploymentPlan.Storage.Volumes {
+		path := planPath.Child("storage", "volumes").Index(i)
+		if !spec.DeploymentPlan.PersistenceEnabled {
+			errs = append(errs, field.Forbidden(path, "volumes require persistenceEnabled"))
+		}
+		if !contains(dataDirectories, volume.Directory) {
+			errs = append(errs, field.NotSupported(path.Child("directory"), volume.Directory, dataDirectories))
+		} else if directories[volume.Directory] {
+			errs = append(errs, field.Duplicate(path.Child("directory"), volume.Directory))
+		}
+		directories[volume.Directory] = true
+		if volume.Size != "" {
+			if _, err := resource.ParseQuantity(volume.Size); err != nil {
+				errs = append(errs, field.Invalid(path.Child("size"), volume.Size, err.Error()))
+			}
+		}
+		for j, mode := range volume.AccessModes {
+			if !contains(accessModes, string(mode)) {
+				errs = append(errs, field.NotSupported(path.Child("accessModes").Index(j), mode, accessModes))
+			}
+		}
+	}
+
+	liveness := &spec.DeploymentPlan.LivenessProbe
+	errs = append(errs, validateProbe(planPath.Child("livenessProbe"), &liveness.Handler, liveness.InitialDelaySeconds, liveness.TimeoutSeconds,
+		liveness.PeriodSeconds, liveness.SuccessThreshold, liveness.FailureThreshold)...)
+	if liveness.SuccessThreshold != nil && *liveness.SuccessThreshold != 1 {
+		errs = append(errs, field.Invalid(planPath.Child("livenessProbe", "successThreshold"), *liveness.SuccessThreshold, "must be 1"))
+	}
+	readiness := &spec.DeploymentPlan.ReadinessProbe
+	errs = append(errs, validateProbe(planPath.Child("readinessProbe"), &readiness.Handler, readiness.InitialDelaySeconds, readiness.TimeoutSeconds,
+		readiness.PeriodSeconds, readiness.SuccessThreshold, readiness.FailureThreshold)...)
+	if delay := spec.DeploymentPlan.ExtraLivenessDelaySeconds; delay != nil && *delay < 0 {
+		errs = append(errs, field.Invalid(planPath.Child("extraLivenessDelaySeconds"), *delay, "must not be negative"))
+	}
+
+	if budget := spec.DeploymentPlan.PodDisruptionBudget; budget != nil {
+		path := planPath.Child("podDisruptionBudget")
+		if budget.MinAvailable != nil && budget.MaxUnavailable != nil {
+			errs = append(errs, field.Forbidden(path, "only one of minAvailable and maxUnavailable may be set"))
+		}
+		errs = append(errs, validateIntOrPercent(path.Child("minAvailable"), budget.MinAvailable)...)
+		errs = append(errs, validateIntOrPercent(path.Child("maxUnavailable"), budget.MaxUnavailable)...)
+	}
+
+	if autoscaling := spec.DeploymentPlan.Autoscaling; autoscaling != nil {
+		errs = append(errs, validateAutoscaling(planPath.Child("autoscaling"), autoscaling)...)
+	}
+
+	if spec.DeploymentPlan.ClusterTLS && spec.DeploymentPlan.Clustered != nil && !*spec.DeploymentPlan.Clustered {
+		errs = append(errs, field.Invalid(planPath.Child("clusterTLS"), true, "only clustered deployments have cluster connections"))
+	}
+
+	if spec.DeploymentPlan.PodTemplate != nil {
+		errs = append(errs, pods.ValidatePodTemplateOverride(planPath.Child("podTemplate"), spec.DeploymentPlan.PodTemplate, cr.Name+"-container")...)
+	}
+
+	if monitoring := spec.Monitoring; monitoring != nil {
+		errs = append(errs, validateMonitoring(specPath.Child("monitoring"), monitoring, spec.DeploymentPlan.EnableMetricsPlugin)...)
+	}
+
+	if spec.Version != "" && !isVersionSupported(spec.Version) {
+		errs = append(errs, field.NotSupported(specPath.Child("version"), spec.Version, version.SupportedVersions))
+	}
+
+	acceptorNames := make(map[string]bool)
+	acceptorPorts := make(map[int32]string)
+	for i, acceptor := range spec.Acceptors {
+		path := specPath.Child("acceptors").Index(i)
+		if acceptor.Name == "" {
+			errs = append(errs, field.Required(path.Child("name"), ""))
+		} else if acceptorNames[acceptor.Name] {
+			errs = append(errs, field.Duplicate(path.Child("name"), acceptor.Name))
+		}
+		acceptorNames[acceptor.Name] = true
+		errs = append(errs, validatePort(path.Child("port"), acceptor.Port, false)...)
+		if acceptor.Port != 0 {
+			if other, found := acceptorPorts[acceptor.Port]; found {
+				errs = append(errs, field.Duplicate(path.Child("port"), fmt.Sprintf("%d is already used by acceptor %s", acceptor.Port, other)))
+			}
+			acceptorPorts[acceptor.Port] = acceptor.Name
+		}
+		if spec.DeploymentPlan.ClusterTLS && acceptor.Port == brokerv2alpha5.ClusterTLSAcceptorPort {
+			errs = append(errs, field.Invalid(path.Child("port"), acceptor.Port, "the port is used by the cluster tls acceptor"))
+		}
+		if spec.Console.OAuthProxy != nil && acceptor.Port == brokerv2alpha5.ConsoleOAuthProxyPort {
+			errs = append(errs, field.Invalid(path.Child("port"), acceptor.Port, "the port is used by the console oauth proxy"))
+		}
+		if acceptor.SSLEnabled {
+			secretName := cr.Name + "-" + acceptor.Name + "-secret"
+			if acceptor.SSLSecret != "" {
+				secretName = acceptor.SSLSecret
+			}
+			if acceptor.CertManager != nil {
+				errs = append(errs, validateCertManager(path.Child("certManager"), acceptor.CertManager)...)
+			} else {
+				errs = append(errs, validateSSLSecret(path.Child("sslSecret"), cr.Namespace, secretName, c)...)
+			}
+		}
+		errs = append(errs, validateIngressHost(path.Child("ingressHost"), acceptor.IngressHost, spec.DeploymentPlan.Size)...)
+		if acceptor.ExposeMode != "" && !contains(exposeModes, string(acceptor.ExposeMode)) {
+			errs = append(errs, field.NotSupported(path.Child("exposeMode"), acceptor.ExposeMode, exposeModes))
+		}
+	}
+
+	connectorNames := make(map[string]bool)
+	for i, connector := range spec.Connectors {
+		path := specPath.Child("connectors").Index(i)
+		if connector.Name == "" {
+			errs = append(errs, field.Required(path.Child("name"), ""))
+		} else if connectorNames[connector.Name] {
+			errs = append(errs, field.Duplicate(path.Child("name"), connector.Name))
+		}
+		connectorNames[connector.Name] = true
+		if connector.Host == "" {
+			errs = append(errs, field.Required(path.Child("host"), ""))
+		}
+		errs = append(errs, validatePort(path.Child("port"), connector.Port, true)...)
+		if connector.SSLEnabled {
+			secretName := cr.Name + "-" + connector.Name + "-secret"
+			if connector.SSLSecret != "" {
+				secretName = connector.SSLSecret
+			}
+			if connector.CertManager != nil {
+				errs = append(errs, validateCertManager(path.Child("certManager"), connector.CertManager)...)
+			} else {
+				errs = append(errs, validateSSLSecret(path.Child("sslSecret"), cr.Namespace, secretName, c)...)
+			}
+		}
+	}
+
+	if spec.Console.SSLEnabled {
+		secretName := cr.Name + "-console-secret"
+		if spec.Console.SSLSecret != "" {
+			secretName = spec.Console.SSLSecret
+		}
+		if spec.Console.CertManager != nil {
+			errs = append(errs, validateCertManager(specPath.Child("console", "certManager"), spec.Console.CertManager)...)
+		} else {
+			errs = append(errs, validateSSLSecret(specPath.Child("console", "sslSecret"), cr.Namespace, secretName, c)...)
+		}
+	}
+	errs = append(errs, validateIngressHost(specPath.Child("console", "ingressHost"), spec.Console.IngressHost, spec.DeploymentPlan.Size)...)
+	if spec.Console.OAuthProxy != nil {
+		if !spec.Console.Expose {
+			errs = append(errs, field.Invalid(specPath.Child("console", "expose"), spec.Console.Expose, "the oauth proxy fronts the exposed console"))
+		}
+		errs = append(errs, validateConsoleOAuthProxy(specPath.Child("console", "oauthProxy"), spec.Console.OAuthProxy)...)
+	}
+
+	settingsPath := specPath.Child("addressSettings")
+	if spec.AddressSettings.ApplyRule != nil && !contains(applyRules, *spec.AddressSettings.ApplyRule) {
+		errs = append(errs, field.NotSupported(settingsPath.Child("applyRule"), *spec.AddressSettings.ApplyRule, applyRules))
+	}
+	for i, setting := range spec.AddressSettings.AddressSetting {
+		path := settingsPath.Child("addressSetting").Index(i)
+		errs = append(errs, validateOneOf(path.Child("addressFullPolicy"), setting.AddressFullPolicy, addressFullPolicies)...)
+		errs = append(errs, validateOneOf(path.Child("slowConsumerPolicy"), setting.SlowConsumerPolicy, slowConsumerPolicies)...)
+		errs = append(errs, validateOneOf(path.Child("defaultQueueRoutingType"), setting.DefaultQueueRoutingType, routingTypes)...)
+		errs = append(errs, validateOneOf(path.Child("defaultAddressRoutingType"), setting.DefaultAddressRoutingType, routingTypes)...)
+	}
+
+	return errs
+}
+
+// validateProbe checks a probe has at most one handler, and that its delay is not negative
+// and its other durations and thresholds are positive
+func validateProbe(path *field.Path, handler *corev1.Handler, initialDelaySeconds *int32, timeoutSeconds *int32,
+	periodSeconds *int32, successThreshold *int32, failureThreshold *int32) field.ErrorList {
+	var errs field.ErrorList
+	handlers := 0
+	for _, set := range []bool{handler.Exec != nil, handler.HTTPGet != nil, handler.TCPSocket != nil} {
+		if set {
+			handlers++
+		}
+	}
+	if handlers > 1 {
+		errs = append(errs, field.Forbidden(path, "only one of exec, httpGet and tcpSocket may be set"))
+	}
+	if initialDelaySeconds != nil && *initialDelaySeconds < 0 {
+		errs = append(errs, field.Invalid(path.Child("initialDelaySeconds"), *initialDelaySeconds, "must not be negative"))
+	}
+	names := []string{"timeoutSeconds", "periodSeconds", "successThreshold", "failureThreshold"}
+	for i, value := range []*int32{timeoutSeconds, periodSeconds, successThreshold, failureThreshold} {
+		if value != nil && *value < 1 {
+			errs = append(errs, field.Invalid(path.Child(names[i]), *value, "must be greater than 0"))
+		}
+	}
+	return errs
+}
+
+// validateAutoscaling checks the replica range is not empty and the metric targets parse
+func validateAutoscaling(path *field.Path, autoscaling *brokerv2alpha5.AutoscalingType) field.ErrorList {
+	var errs field.ErrorList
+	minReplicas := int32(1)
+	if autoscaling.MinReplicas != nil {
+		minReplicas = *autoscaling.MinReplicas
+		if minReplicas < 1 {
+			errs = append(errs, field.Invalid(path.Child("minReplicas"), minReplicas, "must be greater than 0"))
+		}
+	}
+	if autoscaling.MaxReplicas < 1 {
+		errs = append(errs, field.Invalid(path.Child("maxReplicas"), autoscaling.MaxReplicas, "must be greater than 0"))
+	} else if autoscaling.MaxReplicas < minReplicas {
+		errs = append(errs, field.Invalid(path.Child("maxReplicas"), autoscaling.MaxReplicas, "must not be less than minReplicas"))
+	}
+	for i, metric := range autoscaling.Metrics {
+		metricPath := path.Child("metrics").Index(i)
+		if metric.Name == "" {
+			errs = append(errs, field.Required(metricPath.Child("name"), ""))
+		}
+		if _, err := resource.ParseQuantity(metric.TargetAverageValue); err != nil {
+			errs = append(errs, field.Invalid(metricPath.Child("targetAverageValue"), metric.TargetAverageValue, err.Error()))
+		}
+	}
+	if percentage := autoscaling.TargetCPUUtilizationPercentage; percentage != nil && *percentage < 1 {
+		errs = append(errs, field.Invalid(path.Child("targetCPUUtilizationPercentage"), *percentage, "must be greater than 0"))
+	}
+	return errs
+}
+
+// A prometheus duration, such as 30s or 1m
+var prometheusDuration = regexp.MustCompile(`^[0-9]+(ms|s|m|h|d|w|y)$`)
+
+// validateMonitoring checks the metrics plugin is enabled for the brokers to be monitored
+// and the scrape interval and alert threshold are valid
+func validateMonitoring(path *field.Path, monitoring *brokerv2alpha5.MonitoringType, enableMetricsPlugin *bool) field.ErrorList {
+	var errs field.ErrorList
+	if monitoring.Enabled && (enableMetricsPlugin == nil || !*enableMetricsPlugin) {
+		errs = append(errs, field.Invalid(path.Child("enabled"), monitoring.Enabled, "requires spec.deploymentPlan.enableMetricsPlugin to be true"))
+	}
+	if monitoring.Interval != "" && !prometheusDuration.MatchString(monitoring.Interval) {
+		errs = append(errs, field.Invalid(path.Child("interval"), monitoring.Interval, "must be a duration such as 30s or 1m"))
+	}
+	if alerts := monitoring.Alerts; alerts != nil && alerts.AddressMemoryUsagePercentage != nil {
+		if percentage := *alerts.AddressMemoryUsagePercentage; percentage < 1 || percentage > 100 {
+			errs = append(errs, field.Invalid(path.Child("alerts", "addressMemoryUsagePercentage"), percentage, "must be between 1 and 100"))
+		}
+	}
+	return errs
+}
+
+// validateIngressHost checks the host is a dns name once its placeholders are replaced, and
+// that the brokers get distinct hosts when there are more than one
+func validateIngressHost(path *field.Path, host string, size int32) field.ErrorList {
+	if host == "" {
+		return nil
+	}
+	var errs field.ErrorList
+	if size > 1 && !strings.Contains(host, "$(BROKER_ORDINAL)") {
+		errs = append(errs, field.Invalid(path, host, "must contain $(BROKER_ORDINAL) for each broker to have an ingress host of its own"))
+	}
+	sample := strings.NewReplacer("$(CR_NAME)", "broker", "$(CR_NAMESPACE)", "namespace", "$(BROKER_ORDINAL)", "0", "$(ITEM_NAME)", "item").Replace(host)
+	for _, msg := range validation.IsDNS1123Subdomain(sample) {
+		errs = append(errs, field.Invalid(path, host, msg))
+	}
+	return errs
+}
+
+// validateIntOrPercent checks a value is either a number that is not negative or a
+// percentage
+func validateIntOrPercent(path *field.Path, value *intstr.IntOrString) field.ErrorList {
+	if value == nil {
+		return nil
+	}
+	if value.Type == intstr.Int {
+		if value.IntVal < 0 {
+			return field.ErrorList{field.Invalid(path, value.IntVal, "must not be negative")}
+		}
+		return nil
+	}
+	percent, err := strconv.Atoi(strings.TrimSuffix(value.StrVal, "%"))
+	if !strings.HasSuffix(value.StrVal, "%") || err != nil || percent < 0 || percent > 100 {
+		return field.ErrorList{field.Invalid(path, value.StrVal, "must be a percentage such as 50%")}
+	}
+	return nil
+}
+
+func isVersionSupported(specifiedVersion string) bool {
+	return contains(version.SupportedVersions, specifiedVersion)
+}
+
+func validatePort(path *field.Path, port int32, required bool) field.ErrorList {
+	if port == 0 && !required {
+		return nil
+	}
+	if port < 1 || port > 65535 {
+		return field.ErrorList{field.Invalid(path, port, "must be between 1 and 65535")}
+	}
+	return nil
+}
+
+// validateSSLSecret checks the secret holds the key and trust stores the broker expects,
+// either at their default location or at the paths given in the secret, or a pem
+// certificate and key the operator converts to stores
+func validateSSLSecret(path *field.Path, namespace string, secretName string, c client.Client) field.ErrorList {
+
+	if c == nil {
+		return nil
+	}
+
+	// a secret that is not there yet may be applied along with the CR, the controller
+	// warns with an event for as long as it is missing
+	secret := &corev1.Secret{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: namespace}, secret); err != nil {
+		log.Info("Not checking the ssl secret", "namespace", namespace, "secret", secretName, "reason", err.Error())
+		return nil
+	}
+
+	if len(secret.Data[corev1.TLSCertKey]) > 0 && len(secret.Data[corev1.TLSPrivateKeyKey]) > 0 {
+		return nil
+	}
+
+	var errs field.ErrorList
+	if _, ok := secret.Data["keyStorePath"]; !ok {
+		if _, ok := secret.Data["broker.ks"]; !ok {
+			errs = append(errs, field.Invalid(path, secretName, "ssl is enabled but the secret has neither broker.ks nor keyStorePath"))
+		}
+	}
+	if _, ok := secret.Data["trustStorePath"]; !ok {
+		if _, ok := secret.Data["client.ts"]; !ok {
+			errs = append(errs, field.Invalid(path, secretName, "ssl is enabled but the secret has neither client.ts nor trustStorePath"))
+		}
+	}
+	return errs
+}
+
+// validateCertManager checks the issuer of the certificate cert-manager issues into the
+// ssl secret, which needn't exist yet
+func validateCertManager(path *field.Path, certManager *brokerv2alpha5.CertManagerType) field.ErrorList {
+
+	var errs field.ErrorList
+	if certManager.IssuerRef.Name == "" {
+		errs = append(errs, field.Required(path.Child("issuerRef", "name"), ""))
+	}
+	if certManager.IssuerRef.Kind != "" && !contains(issuerKinds, certManager.IssuerRef.Kind) {
+		errs = append(errs, field.NotSupported(path.Child("issuerRef", "kind"), certManager.IssuerRef.Kind, issuerKinds))
+	}
+	return errs
+}
+
+// A hawtio role, written as is to the broker profile
+var consoleRole = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// validateConsoleOAuthProxy checks the provider of the proxy is registered with and the
+// roles the users are mapped to
+func validateConsoleOAuthProxy(path *field.Path, proxy *brokerv2alpha5.ConsoleOAuthProxyType) field.ErrorList {
+
+	var errs field.ErrorList
+	if !contains(consoleOAuthProviders, proxy.Provider) {
+		errs = append(errs, field.NotSupported(path.Child("provider"), proxy.Provider, consoleOAuthProviders))
+	}
+	if proxy.Provider == brokerv2alpha5.ConsoleOAuthProviderOIDC {
+		if proxy.IssuerURL == "" {
+			errs = append(errs, field.Required(path.Child("issuerUrl"), "the oidc provider needs an issuer url"))
+		}
+		if proxy.ClientID == "" {
+			errs = append(errs, field.Required(path.Child("clientId"), "the oidc provider needs a client id"))
+		}
+		if proxy.ClientSecret == nil || proxy.ClientSecret.Name == "" || proxy.ClientSecret.Key == "" {
+			errs = append(errs, field.Required(path.Child("clientSecret"), "the oidc provider needs the secret and key of the client secret"))
+		}
+	}
+
+	if len(proxy.RoleMappings) == 0 {
+		errs = append(errs, field.Required(path.Child("roleMappings"), "no user would be admitted to the console"))
+	}
+	for i, mapping := range proxy.RoleMappings {
+		mappingPath := path.Child("roleMappings").Index(i)
+		if !consoleRole.MatchString(mapping.Role) {
+			errs = append(errs, field.Invalid(mappingPath.Child("role"), mapping.Role, "must match "+consoleRole.String()))
+		}
+		if len(mapping.Users) == 0 {
+			errs = append(errs, field.Required(mappingPath.Child("users"), ""))
+		}
+		for j, user := range mapping.Users {
+			if user == "" || strings.ContainsAny(user, ",\n") {
+				errs = append(errs, field.Invalid(mappingPath.Child("users").Index(j), user, "must be a non empty user name without commas"))
+			}
+		}
+	}
+	return errs
+}
+
+func validateOneOf(path *field.Path, value *string, supported []string) field.ErrorList {
+	if value != nil && !contains(supported, *value) {
+		return field.ErrorList{field.NotSupported(path, *value, supported)}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	brokerv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func validateActiveMQArtemisAddress(obj runtime.Object, c client.Client) field.ErrorList {
+	return ValidateActiveMQArtemisAddress(obj.(*brokerv2alpha3.ActiveMQArtemisAddress))
+}
+
+// ValidateActiveMQArtemisAddress checks the spec of an address
+func ValidateActiveMQArtemisAddress(cr *brokerv2alpha3.ActiveMQArtemisAddress) field.ErrorList {
+
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	spec := &cr.Spec
+
+	if spec.AddressName == "" {
+		errs = append(errs, field.Required(specPath.Child("addressName"), ""))
+	}
+
+	hasQueue := spec.QueueName != nil && *spec.QueueName != ""
+	if spec.RoutingType == nil {
+		if !hasQueue {
+			errs = append(errs, field.Required(specPath.Child("routingType"), "needed to create an address without a queue"))
+		}
+	} else if !containsFold(routingTypes, *spec.RoutingType) {
+		errs = append(errs, field.NotSupported(specPath.Child("routingType"), *spec.RoutingType, routingTypes))
+	}
+
+	if spec.QueueConfiguration != nil {
+		configPath := specPath.Child("queueConfiguration")
+		if !hasQueue {
+			errs = append(errs, field.Forbidden(configPath, "only applies when queueName is set"))
+		}
+		if spec.QueueConfiguration.RoutingType != nil && !containsFold(routingTypes, *spec.QueueConfiguration.RoutingType) {
+			errs = append(errs, field.NotSupported(configPath.Child("routingType"), *spec.QueueConfiguration.RoutingType, routingTypes))
+		}
+		if spec.QueueConfiguration.MaxConsumers != nil && *spec.QueueConfiguration.MaxConsumers < -1 {
+			errs = append(errs, field.Invalid(configPath.Child("maxConsumers"), *spec.QueueConfiguration.MaxConsumers, "must be -1 for unlimited or a positive number"))
+		}
+		if spec.QueueConfiguration.RingSize != nil && *spec.QueueConfiguration.RingSize < -1 {
+			errs = append(errs, field.Invalid(configPath.Child("ringSize"), *spec.QueueConfiguration.RingSize, "must be -1 for no limit or a positive number"))
+		}
+	}
+
+	if spec.ResyncPeriodSeconds != nil && *spec.ResyncPeriodSeconds < 0 {
+		errs = append(errs, field.Invalid(specPath.Child("resyncPeriodSeconds"), *spec.ResyncPeriodSeconds, "must not be negative, 0 disables the check"))
+	}
+
+	if spec.Password != nil && spec.User == nil {
+		errs = append(errs, field.Required(specPath.Child("user"), "needed when password is set"))
+	}
+
+	return errs
+}
@@ -0,0 +1,37 @@
+package webhook
+
+import (
+	brokerv2alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// the annotations the drain controller reads to find the broker to drain and to fill in
+// the pod template of the drainer
+var scaledownAnnotations = []string{"CRNAME", "CRNAMESPACE", "HEADLESSSVCNAMEVALUE", "PINGSVCNAMEVALUE"}
+
+func validateActiveMQArtemisScaledown(obj runtime.Object, c client.Client) field.ErrorList {
+	return ValidateActiveMQArtemisScaledown(obj.(*brokerv2alpha1.ActiveMQArtemisScaledown))
+}
+
+// ValidateActiveMQArtemisScaledown checks a scaledown names the broker it drains. A local
+// only scaledown watches its own namespace, so the broker has to be in that namespace.
+func ValidateActiveMQArtemisScaledown(cr *brokerv2alpha1.ActiveMQArtemisScaledown) field.ErrorList {
+
+	var errs field.ErrorList
+	annotationsPath := field.NewPath("metadata", "annotations")
+
+	for _, annotation := range scaledownAnnotations {
+		if cr.Annotations[annotation] == "" {
+			errs = append(errs, field.Required(annotationsPath.Key(annotation), "read by the drain controller"))
+		}
+	}
+
+	namespace := cr.Annotations["CRNAMESPACE"]
+	if cr.Spec.LocalOnly && namespace != "" && cr.Namespace != "" && namespace != cr.Namespace {
+		errs = append(errs, field.Invalid(annotationsPath.Key("CRNAMESPACE"), namespace, "a localOnly scaledown only drains brokers of its own namespace "+cr.Namespace))
+	}
+
+	return errs
+}
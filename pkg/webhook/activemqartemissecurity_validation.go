@@ -0,0 +1,168 @@
+package webhook
+
+import (
+	brokerv1alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v1alpha1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var keycloakModuleTypes = []string{"directAccess", "bearerToken"}
+var loginModuleFlags = []string{"required", "requisite", "sufficient", "optional"}
+var permissionOperationTypes = []string{"createAddress", "deleteAddress", "createDurableQueue", "deleteDurableQueue",
+	"createNonDurableQueue", "deleteNonDurableQueue", "send", "consume", "browse", "manage", "*"}
+
+func validateActiveMQArtemisSecurity(obj runtime.Object, c client.Client) field.ErrorList {
+	return ValidateActiveMQArtemisSecurity(obj.(*brokerv1alpha1.ActiveMQArtemisSecurity))
+}
+
+// ValidateActiveMQArtemisSecurity checks the spec of a security configuration, in
+// particular that the security domains only refer to login modules declared in it
+func ValidateActiveMQArtemisSecurity(cr *brokerv1alpha1.ActiveMQArtemisSecurity) field.ErrorList {
+
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	modulesPath := specPath.Child("loginModules")
+	spec := &cr.Spec
+
+	moduleNames := make(map[string]bool)
+	checkModuleName := func(path *field.Path, name string) {
+		if name == "" {
+			errs = append(errs, field.Required(path, ""))
+		} else if moduleNames[name] {
+			errs = append(errs, field.Duplicate(path, name))
+		} else {
+			errs = append(errs, validateConfigName(path, name)...)
+		}
+		moduleNames[name] = true
+	}
+
+	for i, module := range spec.LoginModules.PropertiesLoginModules {
+		path := modulesPath.Child("propertiesLoginModules").Index(i)
+		checkModuleName(path.Child("name"), module.Name)
+		userNames := make(map[string]bool)
+		for j, user := range module.Users {
+			if user.Name == "" {
+				errs = append(errs, field.Required(path.Child("users").Index(j).Child("name"), ""))
+			} else if userNames[user.Name] {
+				errs = append(errs, field.Duplicate(path.Child("users").Index(j).Child("name"), user.Name))
+			}
+			userNames[user.Name] = true
+			errs = append(errs, validateUserPassword(path.Child("users").Index(j), &user)...)
+		}
+	}
+	for i, module := range spec.LoginModules.GuestLoginModules {
+		checkModuleName(modulesPath.Child("guestLoginModules").Index(i).Child("name"), module.Name)
+	}
+	for i, module := range spec.LoginModules.KeycloakLoginModules {
+		path := modulesPath.Child("keycloakLoginModules").Index(i)
+		checkModuleName(path.Child("name"), module.Name)
+		errs = append(errs, validateOneOf(path.Child("moduleType"), module.ModuleType, keycloakModuleTypes)...)
+	}
+	for i, module := range spec.LoginModules.LDAPLoginModules {
+		path := modulesPath.Child("ldapLoginModules").Index(i)
+		checkModuleName(path.Child("name"), module.Name)
+		if module.ConnectionURL == "" {
+			errs = append(errs, field.Required(path.Child("connectionURL"), ""))
+		}
+		if module.UserBase == "" {
+			errs = append(errs, field.Required(path.Child("userBase"), ""))
+		}
+		if module.UserSearchMatching == "" {
+			errs = append(errs, field.Required(path.Child("userSearchMatching"), ""))
+		}
+		errs = append(errs, validatePasswordSource(path.Child("connectionPasswordFrom"), module.ConnectionPasswordFrom)...)
+	}
+	for i, module := range spec.LoginModules.TextFileCertificateLoginModules {
+		path := modulesPath.Child("textFileCertificateLoginModules").Index(i)
+		checkModuleName(path.Child("name"), module.Name)
+		userNames := make(map[string]bool)
+		for j, user := range module.Users {
+			if user.Name == "" {
+				errs = append(errs, field.Required(path.Child("users").Index(j).Child("name"), ""))
+			} else if userNames[user.Name] {
+				errs = append(errs, field.Duplicate(path.Child("users").Index(j).Child("name"), user.Name))
+			}
+			userNames[user.Name] = true
+			if user.DN == "" {
+				errs = append(errs, field.Required(path.Child("users").Index(j).Child("dn"), ""))
+			}
+		}
+	}
+
+	domainsPath := specPath.Child("securityDomains")
+	errs = append(errs, validateBrokerDomain(domainsPath.Child("brokerDomain"), &spec.SecurityDomains.BrokerDomain, moduleNames)...)
+	errs = append(errs, validateBrokerDomain(domainsPath.Child("consoleDomain"), &spec.SecurityDomains.ConsoleDomain, moduleNames)...)
+
+	for i, setting := range spec.SecuritySettings.Broker {
+		path := specPath.Child("securitySettings", "broker").Index(i)
+		if setting.Match == "" {
+			errs = append(errs, field.Required(path.Child("match"), ""))
+		}
+		for j, permission := range setting.Permissions {
+			if !contains(permissionOperationTypes, permission.OperationType) {
+				errs = append(errs, field.NotSupported(path.Child("permissions").Index(j).Child("operationType"), permission.OperationType, permissionOperationTypes))
+			}
+		}
+	}
+
+	return errs
+}
+
+func validateUserPassword(path *field.Path, user *brokerv1alpha1.UserType) field.ErrorList {
+	var errs field.ErrorList
+	if user.PasswordFrom == nil {
+		return errs
+	}
+	fromPath := path.Child("passwordFrom")
+	if user.Password != nil {
+		errs = append(errs, field.Forbidden(fromPath, "may not be set together with password"))
+	}
+	return append(errs, validatePasswordSource(fromPath, user.PasswordFrom)...)
+}
+
+func validatePasswordSource(path *field.Path, source *brokerv1alpha1.PasswordSourceType) field.ErrorList {
+	var errs field.ErrorList
+	if source == nil {
+		return errs
+	}
+	ref := source.SecretKeyRef
+	if ref == nil {
+		return append(errs, field.Required(path.Child("secretKeyRef"), ""))
+	}
+	if ref.Name == "" {
+		errs = append(errs, field.Required(path.Child("secretKeyRef", "name"), ""))
+	}
+	if ref.Key == "" {
+		errs = append(errs, field.Required(path.Child("secretKeyRef", "key"), ""))
+	}
+	return errs
+}
+
+// validateConfigName checks a name of the login config is a dns label, the init containers
+// write files named after it
+func validateConfigName(path *field.Path, name string) field.ErrorList {
+	var errs field.ErrorList
+	for _, msg := range validation.IsDNS1123Label(name) {
+		errs = append(errs, field.Invalid(path, name, msg))
+	}
+	return errs
+}
+
+func validateBrokerDomain(path *field.Path, domain *brokerv1alpha1.BrokerDomainType, moduleNames map[string]bool) field.ErrorList {
+	var errs field.ErrorList
+	if domain.Name != nil && *domain.Name != "" {
+		errs = append(errs, validateConfigName(path.Child("name"), *domain.Name)...)
+	}
+	for i, module := range domain.LoginModules {
+		modulePath := path.Child("loginModules").Index(i)
+		if module.Name == nil || *module.Name == "" {
+			errs = append(errs, field.Required(modulePath.Child("name"), ""))
+		} else if !moduleNames[*module.Name] {
+			errs = append(errs, field.NotFound(modulePath.Child("name"), *module.Name))
+		}
+		errs = append(errs, validateOneOf(modulePath.Child("flag"), module.Flag, loginModuleFlags)...)
+	}
+	return errs
+}
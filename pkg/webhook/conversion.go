@@ -0,0 +1,211 @@
+package webhook
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/conversion"
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+const (
+	conversionPath = "/convert"
+	// the CA certificate written to certDir by the webhook server
+	caCertName = "ca-cert.pem"
+
+	activeMQArtemisCRDName = "activemqartemises.broker.amq.io"
+)
+
+// conversionReview mirrors the ConversionReview of apiextensions.k8s.io v1 and v1beta1,
+// which have the same shape. The apiextensions API is not part of the pinned client.
+type conversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *conversionRequest  `json:"request,omitempty"`
+	Response        *conversionResponse `json:"response,omitempty"`
+}
+
+type conversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+type conversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+// conversionHandler serves the conversion webhook of the broker custom resources
+type conversionHandler struct {
+	scheme *runtime.Scheme
+}
+
+var _ http.Handler = &conversionHandler{}
+
+func (h *conversionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	review := conversionReview{}
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+		log.Error(err, "Invalid conversion review")
+		http.Error(w, "invalid conversion review", http.StatusBadRequest)
+		return
+	}
+
+	response := &conversionResponse{UID: review.Request.UID}
+	objects, err := h.convert(review.Request)
+	if err != nil {
+		log.Error(err, "Conversion failed", "desiredAPIVersion", review.Request.DesiredAPIVersion)
+		response.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+	} else {
+		response.ConvertedObjects = objects
+		response.Result = metav1.Status{Status: metav1.StatusSuccess}
+	}
+
+	// answer in the version of the review that was sent
+	review.Request = nil
+	review.Response = response
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&review); err != nil {
+		log.Error(err, "Failed to write conversion response")
+	}
+}
+
+func (h *conversionHandler) convert(request *conversionRequest) ([]runtime.RawExtension, error) {
+
+	gv, err := schema.ParseGroupVersion(request.DesiredAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var converted []runtime.RawExtension
+	for _, raw := range request.Objects {
+		typeMeta := metav1.TypeMeta{}
+		if err := json.Unmarshal(raw.Raw, &typeMeta); err != nil {
+			return nil, err
+		}
+		src, err := h.scheme.New(typeMeta.GroupVersionKind())
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(raw.Raw, src); err != nil {
+			return nil, err
+		}
+		src.GetObjectKind().SetGroupVersionKind(typeMeta.GroupVersionKind())
+
+		dst, err := conversion.Convert(h.scheme, src, gv)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(dst)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, runtime.RawExtension{Raw: data})
+	}
+	return converted, nil
+}
+
+// conversionInstalled is closed once the conversion webhook is installed
+var conversionInstalled = make(chan struct{})
+
+// ConversionInstalled returns a channel that is closed once the conversion of the
+// ActiveMQArtemis CRD goes through the webhook
+func ConversionInstalled() <-chan struct{} {
+	return conversionInstalled
+}
+
+// crdConversionInstaller points the conversion of the ActiveMQArtemis CRD at the webhook
+// server, once the server has written its certificates. The CRDs as deployed do not
+// convert, so that the served versions stay readable when the webhooks are off.
+type crdConversionInstaller struct {
+	client    client.Client
+	service   types.NamespacedName
+	caCertDir string
+}
+
+func addConversionToManager(mgr manager.Manager, namespace string) error {
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return err
+	}
+	installer := &crdConversionInstaller{
+		client:    c,
+		service:   types.NamespacedName{Namespace: namespace, Name: serverName},
+		caCertDir: certDir,
+	}
+	return mgr.Add(manager.RunnableFunc(installer.Start))
+}
+
+// Start waits for the CA certificate of the webhook server and installs the conversion,
+// failures are logged rather than stopping the operator
+func (i *crdConversionInstaller) Start(stop <-chan struct{}) error {
+
+	var caCert []byte
+	err := wait.PollImmediateUntil(2*time.Second, func() (bool, error) {
+		data, err := ioutil.ReadFile(path.Join(i.caCertDir, caCertName))
+		if err != nil {
+			return false, nil
+		}
+		caCert = data
+		return true, nil
+	}, stop)
+	if err != nil {
+		return nil
+	}
+
+	if err := i.install(caCert); err != nil {
+		log.Error(err, "Failed to install the conversion webhook", "crd", activeMQArtemisCRDName)
+		return nil
+	}
+	close(conversionInstalled)
+	return nil
+}
+
+func (i *crdConversionInstaller) install(caCert []byte) error {
+
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(schema.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"})
+	if err := i.client.Get(context.TODO(), types.NamespacedName{Name: activeMQArtemisCRDName}, crd); err != nil {
+		return err
+	}
+
+	webhookConversion := map[string]interface{}{
+		"strategy": "Webhook",
+		"webhook": map[string]interface{}{
+			"clientConfig": map[string]interface{}{
+				"service": map[string]interface{}{
+					"namespace": i.service.Namespace,
+					"name":      i.service.Name,
+					"path":      conversionPath,
+					"port":      int64(443),
+				},
+				"caBundle": base64.StdEncoding.EncodeToString(caCert),
+			},
+			"conversionReviewVersions": []interface{}{"v1", "v1beta1"},
+		},
+	}
+	if err := unstructured.SetNestedField(crd.Object, webhookConversion, "spec", "conversion"); err != nil {
+		return err
+	}
+	if err := i.client.Update(context.TODO(), crd); err != nil {
+		return fmt.Errorf("failed to update crd %s: %v", activeMQArtemisCRDName, err)
+	}
+
+	log.Info("Installed the conversion webhook", "crd", activeMQArtemisCRDName, "hub", brokerv2alpha5.SchemeGroupVersion.String())
+	return nil
+}
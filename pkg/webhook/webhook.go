@@ -0,0 +1,248 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/conversion"
+	brokerv1alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v1alpha1"
+	brokerv2alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha1"
+	brokerv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/builder"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission/types"
+)
+
+var log = logf.Log.WithName("webhook")
+
+const (
+	serverName          = "activemq-artemis-operator-webhook"
+	serverPort    int32 = 9443
+	certDir             = "/tmp/activemq-artemis-operator-webhook/cert"
+	operatorLabel       = "activemq-artemis-operator"
+
+	// EnvEnableWebhooks turns the webhook server on when set to true. Installing the
+	// webhook configuration needs cluster wide permissions, see deploy/cluster_role.yaml
+	EnvEnableWebhooks = "ENABLE_WEBHOOKS"
+)
+
+// validateFunc returns the problems found in a custom resource, an empty list when it is valid
+type validateFunc func(obj runtime.Object, c client.Client) field.ErrorList
+
+// validateUpdateFunc returns the changes made to a custom resource that can't be applied to
+// what was deployed for it
+type validateUpdateFunc func(old runtime.Object, obj runtime.Object) field.ErrorList
+
+// validatingHandler reads the custom resource under admission at the hub version of its
+// kind and rejects it when validate, or validateUpdate on an update, finds any problem
+type validatingHandler struct {
+	hub            schema.GroupVersionKind
+	newObject      func() runtime.Object
+	validate       validateFunc
+	validateUpdate validateUpdateFunc
+	scheme         *runtime.Scheme
+	client         client.Client
+}
+
+// IsEnabled tells whether the webhook server should be started
+func IsEnabled() bool {
+	return strings.ToLower(os.Getenv(EnvEnableWebhooks)) == "true"
+}
+
+// AddToManager registers the defaulting, validating and conversion webhooks for the broker
+// custom resources with a webhook server run by the manager. The webhook configuration, service and
+// certificate are installed by the server when it starts, the conversion once the certificate is there.
+func AddToManager(mgr manager.Manager, namespace string) error {
+
+	failurePolicy := admissionregistrationv1beta1.Ignore
+	operations := []admissionregistrationv1beta1.OperationType{admissionregistrationv1beta1.Create, admissionregistrationv1beta1.Update}
+
+	// every served version of a kind is validated, the handlers read them at the hub version
+	handlers := []struct {
+		name     string
+		path     string
+		resource string
+		versions []string
+		handler  *validatingHandler
+	}{
+		{"activemqartemis.broker.amq.io", "/validate-activemqartemis", "activemqartemises",
+			[]string{"v2alpha1", "v2alpha2", "v2alpha3", "v2alpha4", "v2alpha5"}, &validatingHandler{
+				hub:            brokerv2alpha5.SchemeGroupVersion.WithKind("ActiveMQArtemis"),
+				newObject:      func() runtime.Object { return &brokerv2alpha5.ActiveMQArtemis{} },
+				validate:       validateActiveMQArtemis,
+				validateUpdate: validateActiveMQArtemisUpdate,
+			}},
+		{"activemqartemisaddress.broker.amq.io", "/validate-activemqartemisaddress", "activemqartemisaddresses",
+			[]string{"v2alpha1", "v2alpha2", "v2alpha3"}, &validatingHandler{
+				hub:       brokerv2alpha3.SchemeGroupVersion.WithKind("ActiveMQArtemisAddress"),
+				newObject: func() runtime.Object { return &brokerv2alpha3.ActiveMQArtemisAddress{} },
+				validate:  validateActiveMQArtemisAddress,
+			}},
+		{"activemqartemissecurity.broker.amq.io", "/validate-activemqartemissecurity", "activemqartemissecurities",
+			[]string{"v1alpha1"}, &validatingHandler{
+				hub:       brokerv1alpha1.SchemeGroupVersion.WithKind("ActiveMQArtemisSecurity"),
+				newObject: func() runtime.Object { return &brokerv1alpha1.ActiveMQArtemisSecurity{} },
+				validate:  validateActiveMQArtemisSecurity,
+			}},
+		{"activemqartemisscaledown.broker.amq.io", "/validate-activemqartemisscaledown", "activemqartemisscaledowns",
+			[]string{"v2alpha1"}, &validatingHandler{
+				hub:       brokerv2alpha1.SchemeGroupVersion.WithKind("ActiveMQArtemisScaledown"),
+				newObject: func() runtime.Object { return &brokerv2alpha1.ActiveMQArtemisScaledown{} },
+				validate:  validateActiveMQArtemisScaledown,
+			}},
+	}
+
+	var webhooks []webhook.Webhook
+
+	// the defaults are patched in at the hub version only, as the patch has to be in the
+	// version of the request, the controller stores them for the CRs of older versions
+	defaulter, err := builder.NewWebhookBuilder().
+		Name("default.activemqartemis.broker.amq.io").
+		Mutating().
+		Path("/mutate-activemqartemis").
+		Operations(operations...).
+		FailurePolicy(failurePolicy).
+		ForType(&brokerv2alpha5.ActiveMQArtemis{}).
+		WithManager(mgr).
+		Handlers(&activeMQArtemisDefaulter{}).
+		Build()
+	if err != nil {
+		return err
+	}
+	webhooks = append(webhooks, defaulter)
+
+	for _, h := range handlers {
+		h.handler.scheme = mgr.GetScheme()
+		wh, err := builder.NewWebhookBuilder().
+			Name(h.name).
+			Validating().
+			Path(h.path).
+			Rules(admissionregistrationv1beta1.RuleWithOperations{
+				Operations: operations,
+				Rule: admissionregistrationv1beta1.Rule{
+					APIGroups:   []string{h.handler.hub.Group},
+					APIVersions: h.versions,
+					Resources:   []string{h.resource},
+				},
+			}).
+			FailurePolicy(failurePolicy).
+			WithManager(mgr).
+			Handlers(h.handler).
+			Build()
+		if err != nil {
+			return err
+		}
+		webhooks = append(webhooks, wh)
+	}
+
+	disableInstaller := false
+	server, err := webhook.NewServer(serverName, mgr, webhook.ServerOptions{
+		Port:                          serverPort,
+		CertDir:                       certDir,
+		DisableWebhookConfigInstaller: &disableInstaller,
+		BootstrapOptions: &webhook.BootstrapOptions{
+			MutatingWebhookConfigName:   serverName,
+			ValidatingWebhookConfigName: serverName,
+			Service: &webhook.Service{
+				Name:      serverName,
+				Namespace: namespace,
+				Selectors: map[string]string{
+					"name": operatorLabel,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Info("Registering webhooks", "count", len(webhooks), "namespace", namespace)
+	if err := server.Register(webhooks...); err != nil {
+		return err
+	}
+
+	server.Handle(conversionPath, &conversionHandler{scheme: mgr.GetScheme()})
+	return addConversionToManager(mgr, namespace)
+}
+
+var _ admission.Handler = &validatingHandler{}
+
+// Handle validates the custom resource in the admission request
+func (h *validatingHandler) Handle(ctx context.Context, req types.Request) types.Response {
+
+	obj, err := h.decode(req.AdmissionRequest.Kind, req.AdmissionRequest.Object.Raw)
+	if err != nil {
+		return admission.ErrorResponse(http.StatusBadRequest, err)
+	}
+
+	errs := h.validate(obj, h.client)
+	if req.AdmissionRequest.Operation == admissionv1beta1.Update && h.validateUpdate != nil {
+		old, err := h.decode(req.AdmissionRequest.Kind, req.AdmissionRequest.OldObject.Raw)
+		if err != nil {
+			return admission.ErrorResponse(http.StatusBadRequest, err)
+		}
+		errs = append(errs, h.validateUpdate(old, obj)...)
+	}
+	if len(errs) == 0 {
+		return admission.ValidationResponse(true, "")
+	}
+
+	name := ""
+	if accessor, err := meta.Accessor(obj); err == nil {
+		name = accessor.GetName()
+	}
+	log.Info("Rejecting invalid custom resource", "kind", h.hub.Kind, "name", name, "errors", errs.ToAggregate().Error())
+
+	status := apierrors.NewInvalid(h.hub.GroupKind(), name, errs).ErrStatus
+	response := admission.ValidationResponse(false, "")
+	response.Response.Result = &status
+	return response
+}
+
+// InjectClient is called by the manager to hand over its client
+func (h *validatingHandler) InjectClient(c client.Client) error {
+	h.client = c
+	return nil
+}
+
+// decode reads a custom resource of the admission request at the hub version. The older
+// versions of ActiveMQArtemis convert to the hub, the versions of the other kinds share
+// their fields and are read as they are, as the api server does without conversion.
+func (h *validatingHandler) decode(kind metav1.GroupVersionKind, raw []byte) (runtime.Object, error) {
+
+	gvk := schema.GroupVersionKind{Group: kind.Group, Version: kind.Version, Kind: kind.Kind}
+
+	if gvk != h.hub && h.scheme != nil {
+		if src, err := h.scheme.New(gvk); err == nil {
+			if _, ok := src.(conversion.Convertible); ok {
+				if err := json.Unmarshal(raw, src); err != nil {
+					return nil, err
+				}
+				src.GetObjectKind().SetGroupVersionKind(gvk)
+				return conversion.Convert(h.scheme, src, h.hub.GroupVersion())
+			}
+		}
+	}
+
+	obj := h.newObject()
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
@@ -0,0 +1,128 @@
+package conditions_test
+
+import (
+	"testing"
+	"time"
+
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConditions(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Conditions Suite")
+}
+
+var _ = Describe("Conditions Test", func() {
+
+	past := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+
+	Context("SetCondition", func() {
+		It("Testing a new condition is appended with a transition time", func() {
+			conditions := []brokerv2alpha5.Condition{
+				{Type: brokerv2alpha5.ConditionTypeValid, Status: corev1.ConditionTrue, LastTransitionTime: past},
+			}
+
+			brokerv2alpha5.SetCondition(&conditions, brokerv2alpha5.Condition{
+				Type:               brokerv2alpha5.ConditionTypeReady,
+				Status:             corev1.ConditionFalse,
+				Reason:             "WaitingForBrokers",
+				ObservedGeneration: 1,
+			})
+
+			Expect(conditions).To(HaveLen(2))
+			Expect(conditions[0].LastTransitionTime).To(Equal(past))
+			ready := brokerv2alpha5.FindCondition(conditions, brokerv2alpha5.ConditionTypeReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(corev1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("WaitingForBrokers"))
+			Expect(ready.ObservedGeneration).To(BeEquivalentTo(1))
+			Expect(ready.LastTransitionTime.IsZero()).To(BeFalse())
+		})
+
+		It("Testing a new condition keeps the transition time it is given", func() {
+			var conditions []brokerv2alpha5.Condition
+
+			brokerv2alpha5.SetCondition(&conditions, brokerv2alpha5.Condition{
+				Type:               brokerv2alpha5.ConditionTypeReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: past,
+			})
+
+			Expect(conditions).To(HaveLen(1))
+			Expect(conditions[0].LastTransitionTime).To(Equal(past))
+		})
+
+		It("Testing an update with the same status keeps the transition time", func() {
+			conditions := []brokerv2alpha5.Condition{
+				{Type: brokerv2alpha5.ConditionTypeReady, Status: corev1.ConditionFalse, Reason: "WaitingForBrokers", Message: "1/2 broker pods ready", ObservedGeneration: 1, LastTransitionTime: past},
+			}
+
+			brokerv2alpha5.SetCondition(&conditions, brokerv2alpha5.Condition{
+				Type:               brokerv2alpha5.ConditionTypeReady,
+				Status:             corev1.ConditionFalse,
+				Reason:             "ScaledToZero",
+				Message:            "0/0 broker pods ready",
+				ObservedGeneration: 2,
+			})
+
+			Expect(conditions).To(HaveLen(1))
+			Expect(conditions[0].LastTransitionTime).To(Equal(past))
+			Expect(conditions[0].Reason).To(Equal("ScaledToZero"))
+			Expect(conditions[0].Message).To(Equal("0/0 broker pods ready"))
+			Expect(conditions[0].ObservedGeneration).To(BeEquivalentTo(2))
+		})
+
+		It("Testing an update with another status moves the transition time", func() {
+			conditions := []brokerv2alpha5.Condition{
+				{Type: brokerv2alpha5.ConditionTypeReady, Status: corev1.ConditionFalse, LastTransitionTime: past},
+			}
+
+			brokerv2alpha5.SetCondition(&conditions, brokerv2alpha5.Condition{
+				Type:   brokerv2alpha5.ConditionTypeReady,
+				Status: corev1.ConditionTrue,
+			})
+
+			Expect(conditions).To(HaveLen(1))
+			Expect(conditions[0].Status).To(Equal(corev1.ConditionTrue))
+			Expect(conditions[0].LastTransitionTime.After(past.Time)).To(BeTrue())
+		})
+
+		It("Testing an update with another status takes the transition time it is given", func() {
+			given := metav1.NewTime(past.Add(30 * time.Minute))
+			conditions := []brokerv2alpha5.Condition{
+				{Type: brokerv2alpha5.ConditionTypeReady, Status: corev1.ConditionFalse, LastTransitionTime: past},
+			}
+
+			brokerv2alpha5.SetCondition(&conditions, brokerv2alpha5.Condition{
+				Type:               brokerv2alpha5.ConditionTypeReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: given,
+			})
+
+			Expect(conditions[0].LastTransitionTime).To(Equal(given))
+		})
+
+		It("Testing nil conditions are left alone", func() {
+			Expect(func() {
+				brokerv2alpha5.SetCondition(nil, brokerv2alpha5.Condition{Type: brokerv2alpha5.ConditionTypeReady})
+			}).NotTo(Panic())
+		})
+	})
+
+	Context("IsConditionTrue", func() {
+		It("Testing only a present condition with status True is true", func() {
+			conditions := []brokerv2alpha5.Condition{
+				{Type: brokerv2alpha5.ConditionTypeReady, Status: corev1.ConditionTrue},
+				{Type: brokerv2alpha5.ConditionTypeValid, Status: corev1.ConditionUnknown},
+			}
+
+			Expect(brokerv2alpha5.IsConditionTrue(conditions, brokerv2alpha5.ConditionTypeReady)).To(BeTrue())
+			Expect(brokerv2alpha5.IsConditionTrue(conditions, brokerv2alpha5.ConditionTypeValid)).To(BeFalse())
+			Expect(brokerv2alpha5.IsConditionTrue(conditions, brokerv2alpha5.ConditionTypeDeployed)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,220 @@
+package conversion_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/apis"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/conversion"
+	brokerv2alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha1"
+	brokerv2alpha2 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha2"
+	brokerv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
+	brokerv2alpha4 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha4"
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	fuzz "github.com/google/gofuzz"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const fuzzIterations = 200
+
+func TestConversion(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Conversion Suite")
+}
+
+var _ = BeforeSuite(func() {
+	fmt.Println("=======Before Conversion Suite========")
+})
+
+var _ = AfterSuite(func() {
+	fmt.Println("=======After Conversion Suite========")
+})
+
+// newFuzzer fills in the spec and status of a custom resource. Quantities and times have
+// no exported fields to fuzz, and the redelivery multiplier, an integer before v2alpha5,
+// is kept to values a float32 holds exactly.
+func newFuzzer(seed int64) *fuzz.Fuzzer {
+	return fuzz.New().RandSource(rand.NewSource(seed)).NilChance(0.2).Funcs(
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = *resource.NewQuantity(c.Int63n(1<<20), resource.BinarySI)
+		},
+		func(t *metav1.Time, c fuzz.Continue) {
+			*t = metav1.Unix(c.Int63n(1<<31), 0)
+		},
+		func(m *int32, c fuzz.Continue) {
+			*m = c.Int31n(1 << 24)
+		},
+		func(f *float32, c fuzz.Continue) {
+			*f = float32(c.Int31n(1<<16)) / 8
+		},
+	)
+}
+
+func newScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	Expect(apis.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+var _ = Describe("ActiveMQArtemis conversion", func() {
+
+	spokes := map[string]func() conversion.Convertible{
+		"v2alpha1": func() conversion.Convertible { return &brokerv2alpha1.ActiveMQArtemis{} },
+		"v2alpha2": func() conversion.Convertible { return &brokerv2alpha2.ActiveMQArtemis{} },
+		"v2alpha3": func() conversion.Convertible { return &brokerv2alpha3.ActiveMQArtemis{} },
+		"v2alpha4": func() conversion.Convertible { return &brokerv2alpha4.ActiveMQArtemis{} },
+	}
+
+	for version, newSpoke := range spokes {
+		version, newSpoke := version, newSpoke
+
+		It("round trips "+version+" through the hub", func() {
+			f := newFuzzer(1)
+			for i := 0; i < fuzzIterations; i++ {
+				original := newSpoke()
+				f.Fuzz(original)
+				sanitize(original)
+
+				hub := &brokerv2alpha5.ActiveMQArtemis{}
+				Expect(original.DeepCopyObject().(conversion.Convertible).ConvertTo(hub)).To(Succeed())
+				result := newSpoke()
+				Expect(result.ConvertFrom(hub)).To(Succeed())
+
+				Expect(equality.Semantic.DeepEqual(original, result)).To(BeTrue(), "%#v\n!=\n%#v", original, result)
+			}
+		})
+
+		It("round trips the hub through "+version, func() {
+			f := newFuzzer(2)
+			for i := 0; i < fuzzIterations; i++ {
+				original := &brokerv2alpha5.ActiveMQArtemis{}
+				f.Fuzz(original)
+				sanitize(original)
+
+				spoke := newSpoke()
+				Expect(spoke.ConvertFrom(original.DeepCopy())).To(Succeed())
+				result := &brokerv2alpha5.ActiveMQArtemis{}
+				Expect(spoke.ConvertTo(result)).To(Succeed())
+
+				// the status is written by the operator at the hub version, only the
+				// fields the older version knows about come back
+				Expect(equality.Semantic.DeepEqual(original.ObjectMeta, result.ObjectMeta)).To(BeTrue())
+				Expect(equality.Semantic.DeepEqual(original.Spec, result.Spec)).To(BeTrue(), "%#v\n!=\n%#v", original.Spec, result.Spec)
+				Expect(equality.Semantic.DeepEqual(original.Status.PodStatus, result.Status.PodStatus)).To(BeTrue())
+			}
+		})
+	}
+
+	It("keeps hub only fields when an older version is edited", func() {
+		clustered := false
+		hub := &brokerv2alpha5.ActiveMQArtemis{
+			Spec: brokerv2alpha5.ActiveMQArtemisSpec{
+				DeploymentPlan: brokerv2alpha5.DeploymentPlanType{Size: 1, Clustered: &clustered},
+				Acceptors: []brokerv2alpha5.AcceptorType{
+					{Name: "amqp", Port: 5672},
+					{Name: "core", Port: 61616, SupportAdvisory: &clustered},
+				},
+			},
+		}
+
+		spoke := &brokerv2alpha2.ActiveMQArtemis{}
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		Expect(spoke.Annotations).To(HaveKey(conversion.HubSpecAnnotation))
+
+		spoke.Spec.DeploymentPlan.Size = 3
+		spoke.Spec.Acceptors = spoke.Spec.Acceptors[1:]
+
+		result := &brokerv2alpha5.ActiveMQArtemis{}
+		Expect(spoke.ConvertTo(result)).To(Succeed())
+		Expect(result.Annotations).NotTo(HaveKey(conversion.HubSpecAnnotation))
+		Expect(result.Spec.DeploymentPlan.Size).To(Equal(int32(3)))
+		Expect(*result.Spec.DeploymentPlan.Clustered).To(BeFalse())
+		Expect(result.Spec.Acceptors).To(HaveLen(1))
+		Expect(result.Spec.Acceptors[0].Name).To(Equal("core"))
+		Expect(result.Spec.Acceptors[0].SupportAdvisory).NotTo(BeNil())
+	})
+
+	It("does not annotate an older version that holds the whole spec", func() {
+		hub := &brokerv2alpha5.ActiveMQArtemis{
+			Spec: brokerv2alpha5.ActiveMQArtemisSpec{
+				AdminUser:      "admin",
+				DeploymentPlan: brokerv2alpha5.DeploymentPlanType{Size: 2},
+			},
+		}
+		spoke := &brokerv2alpha1.ActiveMQArtemis{}
+		Expect(spoke.ConvertFrom(hub)).To(Succeed())
+		Expect(spoke.Annotations).To(BeNil())
+		Expect(spoke.Spec.DeploymentPlan.Size).To(Equal(int32(2)))
+	})
+
+	It("converts between any two served versions", func() {
+		scheme := newScheme()
+		multiplier := float32(2.5)
+		src := &brokerv2alpha5.ActiveMQArtemis{
+			TypeMeta:   metav1.TypeMeta{APIVersion: brokerv2alpha5.SchemeGroupVersion.String(), Kind: "ActiveMQArtemis"},
+			ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+			Spec: brokerv2alpha5.ActiveMQArtemisSpec{
+				AddressSettings: brokerv2alpha5.AddressSettingsType{
+					AddressSetting: []brokerv2alpha5.AddressSettingType{{Match: "#", RedeliveryDelayMultiplier: &multiplier}},
+				},
+			},
+		}
+
+		v2alpha3, err := conversion.Convert(scheme, src, brokerv2alpha3.SchemeGroupVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v2alpha3.GetObjectKind().GroupVersionKind().Version).To(Equal("v2alpha3"))
+		Expect(*v2alpha3.(*brokerv2alpha3.ActiveMQArtemis).Spec.AddressSettings.AddressSetting[0].RedeliveryDelayMultiplier).To(Equal(int32(2)))
+
+		v2alpha1, err := conversion.Convert(scheme, v2alpha3, brokerv2alpha1.SchemeGroupVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v2alpha1.(*brokerv2alpha1.ActiveMQArtemis).Name).To(Equal("ex-aao"))
+
+		back, err := conversion.Convert(scheme, v2alpha1, brokerv2alpha5.SchemeGroupVersion)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(equality.Semantic.DeepEqual(back.(*brokerv2alpha5.ActiveMQArtemis).Spec, src.Spec)).To(BeTrue())
+	})
+
+	It("refuses kinds without conversions", func() {
+		scheme := newScheme()
+		src := &brokerv2alpha1.ActiveMQArtemisScaledown{
+			TypeMeta: metav1.TypeMeta{APIVersion: brokerv2alpha1.SchemeGroupVersion.String(), Kind: "ActiveMQArtemisScaledown"},
+		}
+		_, err := conversion.Convert(scheme, src, schema.GroupVersion{Group: "broker.amq.io", Version: "v2alpha5"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+// sanitize clears what a real custom resource would not have: type meta, which the
+// conversion does not touch, and the annotation used to carry the hub spec
+func sanitize(obj runtime.Object) {
+	obj.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{})
+	switch cr := obj.(type) {
+	case *brokerv2alpha5.ActiveMQArtemis:
+		cr.ObjectMeta = sanitizeMeta(cr.ObjectMeta)
+	case *brokerv2alpha4.ActiveMQArtemis:
+		cr.ObjectMeta = sanitizeMeta(cr.ObjectMeta)
+	case *brokerv2alpha3.ActiveMQArtemis:
+		cr.ObjectMeta = sanitizeMeta(cr.ObjectMeta)
+	case *brokerv2alpha2.ActiveMQArtemis:
+		cr.ObjectMeta = sanitizeMeta(cr.ObjectMeta)
+	case *brokerv2alpha1.ActiveMQArtemis:
+		cr.ObjectMeta = sanitizeMeta(cr.ObjectMeta)
+	}
+}
+
+func sanitizeMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		Labels:      meta.Labels,
+		Annotations: meta.Annotations,
+		Generation:  meta.Generation,
+	}
+}
@@ -0,0 +1,79 @@
+package v2alpha3address_test
+
+import (
+	address "github.com/artemiscloud/activemq-artemis-operator/pkg/controller/broker/v2alpha3/activemqartemisaddress"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/brokerclient"
+	"github.com/artemiscloud/activemq-artemis-operator/test/utils/fakebroker"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Unmanaged Queue Removal Test", func() {
+
+	var broker *fakebroker.Broker
+	var client *brokerclient.Client
+
+	ginkgo.BeforeEach(func() {
+		broker = fakebroker.NewBroker("amq-broker", "admin", "admin")
+		client = brokerclient.GetClient(broker.Host(), broker.Port(), "amq-broker", "admin", "admin", "http")
+	})
+
+	ginkgo.AfterEach(func() {
+		broker.Close()
+	})
+
+	ginkgo.It("removes an idle durable anycast queue", func() {
+		broker.AddQueue("orders", "stale", "ANYCAST")
+
+		removed, err := address.RemoveUnmanagedQueue(client, "orders", "stale")
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(removed).To(gomega.BeTrue())
+		gomega.Expect(broker.Queues).NotTo(gomega.HaveKey("stale"))
+	})
+
+	ginkgo.It("keeps a subscription queue of a multicast address", func() {
+		broker.AddQueue("events", "subscriber", "MULTICAST")
+
+		removed, err := address.RemoveUnmanagedQueue(client, "events", "subscriber")
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(removed).To(gomega.BeFalse())
+		gomega.Expect(broker.Queues).To(gomega.HaveKey("subscriber"))
+	})
+
+	ginkgo.It("keeps a temporary queue", func() {
+		broker.AddQueue("orders", "reply", "ANYCAST").Attributes["Temporary"] = true
+
+		removed, err := address.RemoveUnmanagedQueue(client, "orders", "reply")
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(removed).To(gomega.BeFalse())
+		gomega.Expect(broker.Queues).To(gomega.HaveKey("reply"))
+	})
+
+	ginkgo.It("keeps a non durable queue", func() {
+		broker.AddQueue("orders", "volatile", "ANYCAST").Attributes["Durable"] = false
+
+		removed, err := address.RemoveUnmanagedQueue(client, "orders", "volatile")
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(removed).To(gomega.BeFalse())
+		gomega.Expect(broker.Queues).To(gomega.HaveKey("volatile"))
+	})
+
+	ginkgo.It("keeps a queue with consumers", func() {
+		broker.AddQueue("orders", "busy", "ANYCAST").Attributes["ConsumerCount"] = 2
+
+		removed, err := address.RemoveUnmanagedQueue(client, "orders", "busy")
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(removed).To(gomega.BeFalse())
+		gomega.Expect(broker.Queues).To(gomega.HaveKey("busy"))
+	})
+
+	ginkgo.It("keeps a queue of another address", func() {
+		broker.AddQueue("invoices", "stale", "ANYCAST")
+
+		removed, err := address.RemoveUnmanagedQueue(client, "orders", "stale")
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(removed).To(gomega.BeFalse())
+		gomega.Expect(broker.Queues).To(gomega.HaveKey("stale"))
+		gomega.Expect(broker.Operations()).NotTo(gomega.ContainElement("destroyQueue(java.lang.String)"))
+	})
+})
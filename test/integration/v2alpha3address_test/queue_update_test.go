@@ -0,0 +1,119 @@
+package v2alpha3address_test
+
+import (
+	brokerv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
+	address "github.com/artemiscloud/activemq-artemis-operator/pkg/controller/broker/v2alpha3/activemqartemisaddress"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/brokerclient"
+	"github.com/artemiscloud/activemq-artemis-operator/test/utils/fakebroker"
+	"github.com/onsi/ginkgo"
+	"github.com/onsi/gomega"
+)
+
+func newQueueAddress() *brokerv2alpha3.ActiveMQArtemisAddress {
+	queueName := "orders"
+	routingType := "anycast"
+	maxConsumers := int32(-1)
+	return &brokerv2alpha3.ActiveMQArtemisAddress{
+		Spec: brokerv2alpha3.ActiveMQArtemisAddressSpec{
+			AddressName: "orders",
+			QueueName:   &queueName,
+			RoutingType: &routingType,
+			QueueConfiguration: &brokerv2alpha3.QueueConfigurationType{
+				MaxConsumers: &maxConsumers,
+			},
+		},
+	}
+}
+
+var _ = ginkgo.Describe("Queue Configuration Update Test", func() {
+
+	var broker *fakebroker.Broker
+	var client *brokerclient.Client
+
+	ginkgo.BeforeEach(func() {
+		broker = fakebroker.NewBroker("amq-broker", "admin", "admin")
+		client = brokerclient.GetClient(broker.Host(), broker.Port(), "amq-broker", "admin", "admin", "http")
+	})
+
+	ginkgo.AfterEach(func() {
+		broker.Close()
+	})
+
+	ginkgo.It("creates a missing queue from its configuration", func() {
+		addressRes := newQueueAddress()
+
+		changed, err := address.CreateOrUpdateQueue(client, addressRes)
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(changed).To(gomega.BeTrue())
+		gomega.Expect(broker.Queues).To(gomega.HaveKey("orders"))
+		gomega.Expect(broker.Queues["orders"].RoutingType).To(gomega.Equal("ANYCAST"))
+	})
+
+	ginkgo.It("leaves an up to date queue alone", func() {
+		broker.AddQueue("orders", "orders", "ANYCAST")
+
+		changed, err := address.CreateOrUpdateQueue(client, newQueueAddress())
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(changed).To(gomega.BeFalse())
+		gomega.Expect(broker.Operations()).NotTo(gomega.ContainElement("updateQueue(java.lang.String)"))
+	})
+
+	ginkgo.It("updates changed attributes in place even with ignoreIfExists", func() {
+		broker.AddQueue("orders", "orders", "ANYCAST")
+
+		addressRes := newQueueAddress()
+		ignoreIfExists := true
+		maxConsumers := int32(5)
+		exclusive := true
+		filter := "color='red'"
+		ringSize := int64(100)
+		addressRes.Spec.QueueConfiguration.IgnoreIfExists = &ignoreIfExists
+		addressRes.Spec.QueueConfiguration.MaxConsumers = &maxConsumers
+		addressRes.Spec.QueueConfiguration.Exclusive = &exclusive
+		addressRes.Spec.QueueConfiguration.FilterString = &filter
+		addressRes.Spec.QueueConfiguration.RingSize = &ringSize
+
+		changed, err := address.CreateOrUpdateQueue(client, addressRes)
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(changed).To(gomega.BeTrue())
+		gomega.Expect(broker.Operations()).To(gomega.ContainElement("updateQueue(java.lang.String)"))
+
+		attributes, err := client.GetQueueAttributes(brokerclient.Queue{Address: "orders", Name: "orders", RoutingType: "ANYCAST"})
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(attributes.MaxConsumers).To(gomega.Equal(int32(5)))
+		gomega.Expect(attributes.Exclusive).To(gomega.BeTrue())
+		gomega.Expect(attributes.Filter).To(gomega.Equal("color='red'"))
+		gomega.Expect(attributes.RingSize).To(gomega.Equal(int64(100)))
+
+		// applying the same configuration again is a no-op
+		changed, err = address.CreateOrUpdateQueue(client, addressRes)
+		gomega.Expect(err).To(gomega.BeNil())
+		gomega.Expect(changed).To(gomega.BeFalse())
+	})
+
+	ginkgo.It("reports a routing type change as an error", func() {
+		broker.AddQueue("orders", "orders", "MULTICAST")
+
+		changed, err := address.CreateOrUpdateQueue(client, newQueueAddress())
+		gomega.Expect(changed).To(gomega.BeFalse())
+		gomega.Expect(err).NotTo(gomega.BeNil())
+		gomega.Expect(err.Error()).To(gomega.ContainSubstring("routing-type"))
+		gomega.Expect(broker.Operations()).NotTo(gomega.ContainElement("updateQueue(java.lang.String)"))
+	})
+
+	ginkgo.It("reports a durability change as an error", func() {
+		broker.AddQueue("orders", "orders", "ANYCAST")
+
+		addressRes := newQueueAddress()
+		durable := false
+		maxConsumers := int32(5)
+		addressRes.Spec.QueueConfiguration.Durable = &durable
+		addressRes.Spec.QueueConfiguration.MaxConsumers = &maxConsumers
+
+		changed, err := address.CreateOrUpdateQueue(client, addressRes)
+		gomega.Expect(changed).To(gomega.BeFalse())
+		gomega.Expect(err).NotTo(gomega.BeNil())
+		gomega.Expect(err.Error()).To(gomega.ContainSubstring("durable"))
+		gomega.Expect(broker.Queues["orders"].Attributes["MaxConsumers"]).To(gomega.Equal(-1))
+	})
+})
@@ -1,4 +1,4 @@
-package v2alpha2_test
+package v2alpha3address_test
 
 import (
 	. "github.com/onsi/ginkgo"
@@ -6,7 +6,7 @@ import (
 	"testing"
 )
 
-func TestV2alpha2(t *testing.T) {
+func TestV2alpha3Address(t *testing.T) {
 	RegisterFailHandler(Fail)
-	RunSpecs(t, "V2alpha2 Suite")
+	RunSpecs(t, "V2alpha3 Address Suite")
 }
@@ -0,0 +1,174 @@
+package brokerclient_test
+
+import (
+	"testing"
+
+	"fmt"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/brokerclient"
+	"github.com/artemiscloud/activemq-artemis-operator/test/utils/fakebroker"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBrokerClient(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Broker Client Suite")
+}
+
+var _ = BeforeSuite(func() {
+	fmt.Println("=======Before Broker Client Suite========")
+})
+
+var _ = AfterSuite(func() {
+	fmt.Println("=======After Broker Client Suite========")
+})
+
+var _ = Describe("Broker Client Test", func() {
+
+	var broker *fakebroker.Broker
+	var client *brokerclient.Client
+	queue := brokerclient.Queue{Address: "orders", Name: "orders.queue", RoutingType: "ANYCAST"}
+
+	BeforeEach(func() {
+		broker = fakebroker.NewBroker("amq-broker", "admin", "secret")
+		broker.Topology = `[{"nodeID":"n1","live":"ex-aao-ss-0:61616"},{"nodeID":"n2","live":"ex-aao-ss-1:61616"}]`
+		broker.AddQueue("orders", "orders.queue", "ANYCAST").Attributes["MessageCount"] = 5
+		broker.AddQueue("orders", "orders.dlq", "ANYCAST")
+		client = brokerclient.GetClient(broker.Host(), broker.Port(), "amq-broker", "admin", "secret", "http")
+	})
+
+	AfterEach(func() {
+		broker.Close()
+	})
+
+	Context("Broker calls", func() {
+		It("reads the version", func() {
+			version, err := client.GetVersion()
+			Expect(err).To(BeNil())
+			Expect(version).To(Equal("2.20.0"))
+		})
+
+		It("lists the network topology", func() {
+			topology, err := client.ListNetworkTopology()
+			Expect(err).To(BeNil())
+			Expect(topology).To(HaveLen(2))
+			Expect(topology[1].NodeID).To(Equal("n2"))
+			Expect(topology[1].Live).To(Equal("ex-aao-ss-1:61616"))
+		})
+
+		It("lists addresses and queues", func() {
+			addresses, err := client.ListAddresses()
+			Expect(err).To(BeNil())
+			Expect(addresses).To(Equal([]string{"orders"}))
+
+			queues, err := client.ListQueues()
+			Expect(err).To(BeNil())
+			Expect(queues).To(Equal([]string{"orders.dlq", "orders.queue"}))
+		})
+	})
+
+	Context("Queue calls", func() {
+		It("finds a queue by name", func() {
+			found, err := client.FindQueue("orders.queue")
+			Expect(err).To(BeNil())
+			Expect(*found).To(Equal(queue))
+
+			missing, err := client.FindQueue("nothere")
+			Expect(err).To(BeNil())
+			Expect(missing).To(BeNil())
+		})
+
+		It("reads the queue attributes", func() {
+			attributes, err := client.GetQueueAttributes(queue)
+			Expect(err).To(BeNil())
+			Expect(attributes.Name).To(Equal("orders.queue"))
+			Expect(attributes.RoutingType).To(Equal("ANYCAST"))
+			Expect(attributes.Durable).To(BeTrue())
+			Expect(attributes.MaxConsumers).To(Equal(int32(-1)))
+			Expect(attributes.MessageCount).To(Equal(int64(5)))
+		})
+
+		It("pauses and resumes a queue", func() {
+			Expect(client.PauseQueue(queue)).To(Succeed())
+			paused, err := client.IsQueuePaused(queue)
+			Expect(err).To(BeNil())
+			Expect(paused).To(BeTrue())
+
+			Expect(client.ResumeQueue(queue)).To(Succeed())
+			paused, err = client.IsQueuePaused(queue)
+			Expect(err).To(BeNil())
+			Expect(paused).To(BeFalse())
+		})
+
+		It("pauses and resumes an address", func() {
+			Expect(client.PauseAddress("orders")).To(Succeed())
+			paused, _ := client.IsQueuePaused(brokerclient.Queue{Address: "orders", Name: "orders.dlq", RoutingType: "ANYCAST"})
+			Expect(paused).To(BeTrue())
+
+			Expect(client.ResumeAddress("orders")).To(Succeed())
+			paused, _ = client.IsQueuePaused(queue)
+			Expect(paused).To(BeFalse())
+		})
+
+		It("reads the counters", func() {
+			count, err := client.GetMessageCount(queue)
+			Expect(err).To(BeNil())
+			Expect(count).To(Equal(int64(5)))
+
+			consumers, err := client.GetConsumerCount(queue)
+			Expect(err).To(BeNil())
+			Expect(consumers).To(Equal(0))
+
+			delivering, err := client.GetDeliveringCount(queue)
+			Expect(err).To(BeNil())
+			Expect(delivering).To(Equal(0))
+		})
+
+		It("moves and purges messages", func() {
+			moved, err := client.MoveMessages(queue, "", "orders.dlq")
+			Expect(err).To(BeNil())
+			Expect(moved).To(Equal(5))
+
+			dlq := brokerclient.Queue{Address: "orders", Name: "orders.dlq", RoutingType: "ANYCAST"}
+			removed, err := client.PurgeQueue(dlq, "")
+			Expect(err).To(BeNil())
+			Expect(removed).To(Equal(5))
+
+			count, _ := client.GetMessageCount(dlq)
+			Expect(count).To(Equal(int64(0)))
+		})
+
+		It("resets a user", func() {
+			broker.Users["bob"] = fakebroker.User{Password: "old", Roles: "root"}
+			Expect(client.ResetUser("bob", "new", []string{"root", "admin"})).To(Succeed())
+			Expect(broker.Users["bob"]).To(Equal(fakebroker.User{Password: "new", Roles: "root,admin"}))
+		})
+	})
+
+	Context("Errors", func() {
+		It("reports a missing queue", func() {
+			_, err := client.GetQueueAttributes(brokerclient.Queue{Address: "orders", Name: "nothere", RoutingType: "ANYCAST"})
+			Expect(err).NotTo(BeNil())
+			Expect(err.Error()).To(ContainSubstring("InstanceNotFoundException"))
+		})
+
+		It("reports a missing user", func() {
+			Expect(client.ResetUser("nobody", "secret", nil)).NotTo(Succeed())
+		})
+
+		It("reports bad credentials", func() {
+			badClient := brokerclient.GetClient(broker.Host(), broker.Port(), "amq-broker", "admin", "wrong", "http")
+			_, err := badClient.GetVersion()
+			Expect(err).NotTo(BeNil())
+		})
+
+		It("still supports the embedded management calls", func() {
+			_, err := client.CreateQueue("invoices", "invoices", "MULTICAST")
+			Expect(err).To(BeNil())
+			found, err := client.FindQueue("invoices")
+			Expect(err).To(BeNil())
+			Expect(found.RoutingType).To(Equal("MULTICAST"))
+		})
+	})
+})
@@ -0,0 +1,75 @@
+package certificates_test
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/certificates"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCertificates(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Certificates Suite")
+}
+
+var _ = Describe("Certificates Test", func() {
+	Context("NewCertificate", func() {
+		It("Testing the certificate verifies against its ca for its dns names", func() {
+			ca, caKey, err := certificates.NewCA("ex-aao-cluster-ca", time.Now(), time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			certificate, _, err := certificates.NewCertificate(ca, caKey, "ex-aao-ss-0", []string{"ex-aao-ss-0.ex-aao-hdls-svc.test.svc"}, time.Now(), time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			roots := x509.NewCertPool()
+			roots.AddCert(ca)
+			_, err = certificate.Verify(x509.VerifyOptions{
+				DNSName:   "ex-aao-ss-0.ex-aao-hdls-svc.test.svc",
+				Roots:     roots,
+				KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Testing the certificate does not outlive its ca", func() {
+			ca, caKey, err := certificates.NewCA("ex-aao-cluster-ca", time.Now(), time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			certificate, _, err := certificates.NewCertificate(ca, caKey, "ex-aao-ss-0", nil, time.Now(), 2*time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(certificate.NotAfter).To(Equal(ca.NotAfter))
+		})
+	})
+
+	Context("NeedsRenewal", func() {
+		It("Testing a certificate is renewed once a third of its lifetime is left", func() {
+			ca, _, err := certificates.NewCA("ex-aao-cluster-ca", time.Now(), 90*24*time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(certificates.NeedsRenewal(ca, time.Now())).To(BeFalse())
+			Expect(certificates.NeedsRenewal(ca, time.Now().Add(59*24*time.Hour))).To(BeFalse())
+			Expect(certificates.NeedsRenewal(ca, time.Now().Add(61*24*time.Hour))).To(BeTrue())
+		})
+	})
+
+	Context("EncodeKey", func() {
+		It("Testing the encoded certificate and key parse back", func() {
+			ca, caKey, err := certificates.NewCA("ex-aao-cluster-ca", time.Now(), time.Hour)
+			Expect(err).NotTo(HaveOccurred())
+
+			keyPem, err := certificates.EncodeKey(caKey)
+			Expect(err).NotTo(HaveOccurred())
+			parsedKey, err := certificates.ParseKey(keyPem)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsedKey.Public()).To(Equal(caKey.Public()))
+
+			parsed, err := certificates.ParseCertificates(certificates.EncodeCertificates(ca, ca))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(HaveLen(2))
+			Expect(parsed[0].Equal(ca)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,47 @@
+package escape_test
+
+import (
+	"os/exec"
+	"testing"
+
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/utils/escape"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestEscape(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Escape Utils Suite")
+}
+
+var _ = Describe("Escape Util Test", func() {
+
+	Context("PropertyKey", func() {
+		It("Testing the separators, comments and backslashes of a key are escaped", func() {
+			Expect(escape.PropertyKey(`a=b:c d#e!f\g`)).To(Equal(`a\=b\:c\ d\#e\!f\\g`))
+			Expect(escape.PropertyKey("admin")).To(Equal("admin"))
+		})
+	})
+
+	Context("PropertyValue", func() {
+		It("Testing the backslashes and line breaks of a value are escaped", func() {
+			Expect(escape.PropertyValue("CN=app,O=Example\\, Inc.\nOU=x\r")).To(Equal(`CN=app,O=Example\\, Inc.\nOU=x\r`))
+		})
+
+		It("Testing only the leading whitespace of a value is escaped", func() {
+			Expect(escape.PropertyValue(" CN=a b")).To(Equal(`\ CN=a b`))
+			Expect(escape.PropertyValue("\tCN=a")).To(Equal("\\\tCN=a"))
+			Expect(escape.PropertyValue("CN=a=b:c")).To(Equal("CN=a=b:c"))
+		})
+	})
+
+	Context("ShellQuote", func() {
+		It("Testing a quoted string is a single word of the shell", func() {
+			for _, s := range []string{"", "o'brien", `$HOME "x" \n`, "a;touch pwned", "''"} {
+				out, err := exec.Command("sh", "-c", "printf '%s' "+escape.ShellQuote(s)).CombinedOutput()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(out)).To(Equal(s))
+			}
+		})
+	})
+})
@@ -0,0 +1,461 @@
+package fakebroker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Queue is a queue held by the fake broker. Attributes are returned as is when
+// the queue mbean is read, keyed by the QueueControl attribute names.
+type Queue struct {
+	Address     string
+	Name        string
+	RoutingType string
+	Attributes  map[string]interface{}
+}
+
+// User is a user of the properties login module of the fake broker
+type User struct {
+	Password string
+	Roles    string
+}
+
+// Request is a jolokia request as received by the fake broker
+type Request struct {
+	Type      string        `json:"type"`
+	MBean     string        `json:"mbean"`
+	Attribute string        `json:"attribute,omitempty"`
+	Operation string        `json:"operation,omitempty"`
+	Arguments []interface{} `json:"arguments,omitempty"`
+}
+
+// Broker is a fake jolokia endpoint that understands the subset of the Artemis
+// management api used by the operator
+type Broker struct {
+	Name     string
+	User     string
+	Password string
+	Version  string
+	Topology string
+	Queues   map[string]*Queue
+	// Users holds the password and roles of the users set through the management api
+	Users map[string]User
+	// Requests holds every request received, in order
+	Requests []Request
+
+	addresses map[string]string
+	server    *httptest.Server
+	mu        sync.Mutex
+}
+
+var mbeanPropertyRegexp = regexp.MustCompile(`([a-z-]+)=("[^"]*"|[^,]*)`)
+
+// NewBroker starts a fake broker, Close must be called once done with it
+func NewBroker(name string, user string, password string) *Broker {
+	b := &Broker{
+		Name:      name,
+		User:      user,
+		Password:  password,
+		Version:   "2.20.0",
+		Topology:  "[]",
+		Queues:    make(map[string]*Queue),
+		Users:     make(map[string]User),
+		addresses: make(map[string]string),
+	}
+	b.server = httptest.NewServer(http.HandlerFunc(b.serve))
+	return b
+}
+
+func (b *Broker) Close() {
+	b.server.Close()
+}
+
+// Host returns the ip the fake broker listens on
+func (b *Broker) Host() string {
+	host, _, _ := net.SplitHostPort(b.server.Listener.Addr().String())
+	return host
+}
+
+// Port returns the port the fake broker listens on
+func (b *Broker) Port() string {
+	_, port, _ := net.SplitHostPort(b.server.Listener.Addr().String())
+	return port
+}
+
+// AddQueue adds a queue, and its address when needed, with default attributes
+func (b *Broker) AddQueue(address string, name string, routingType string) *Queue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.addQueue(address, name, routingType, nil)
+}
+
+// Operations returns the operations invoked so far, in order
+func (b *Broker) Operations() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var operations []string
+	for _, r := range b.Requests {
+		if strings.ToLower(r.Type) == "exec" {
+			operations = append(operations, r.Operation)
+		}
+	}
+	return operations
+}
+
+func (b *Broker) addQueue(address string, name string, routingType string, config map[string]interface{}) *Queue {
+	routingType = strings.ToUpper(routingType)
+	b.addresses[address] = routingType
+	queue := &Queue{
+		Address:     address,
+		Name:        name,
+		RoutingType: routingType,
+		Attributes: map[string]interface{}{
+			"Name":                    name,
+			"Address":                 address,
+			"RoutingType":             routingType,
+			"Filter":                  nil,
+			"Durable":                 true,
+			"Temporary":               false,
+			"MaxConsumers":            -1,
+			"Exclusive":               false,
+			"GroupRebalance":          false,
+			"GroupBuckets":            -1,
+			"GroupFirstKey":           nil,
+			"LastValue":               false,
+			"LastValueKey":            nil,
+			"NonDestructive":          false,
+			"PurgeOnNoConsumers":      false,
+			"Enabled":                 true,
+			"ConsumersBeforeDispatch": 0,
+			"DelayBeforeDispatch":     -1,
+			"RingSize":                -1,
+			"ConfigurationManaged":    false,
+			"Paused":                  false,
+			"MessageCount":            0,
+			"ConsumerCount":           0,
+			"DeliveringCount":         0,
+		},
+	}
+	b.applyConfig(queue, config)
+	b.Queues[name] = queue
+	return queue
+}
+
+// applyConfig sets the attributes from a queue configuration as passed to createQueue and updateQueue
+func (b *Broker) applyConfig(queue *Queue, config map[string]interface{}) {
+	names := map[string]string{
+		"filter-string":             "Filter",
+		"durable":                   "Durable",
+		"max-consumers":             "MaxConsumers",
+		"exclusive":                 "Exclusive",
+		"group-rebalance":           "GroupRebalance",
+		"group-buckets":             "GroupBuckets",
+		"group-first-key":           "GroupFirstKey",
+		"last-value":                "LastValue",
+		"last-value-key":            "LastValueKey",
+		"non-destructive":           "NonDestructive",
+		"purge-on-no-consumers":     "PurgeOnNoConsumers",
+		"enabled":                   "Enabled",
+		"consumers-before-dispatch": "ConsumersBeforeDispatch",
+		"delay-before-dispatch":     "DelayBeforeDispatch",
+		"ring-size":                 "RingSize",
+		"configuration-managed":     "ConfigurationManaged",
+		"temporary":                 "Temporary",
+	}
+	for key, value := range config {
+		if attribute, ok := names[key]; ok {
+			queue.Attributes[attribute] = value
+		}
+	}
+}
+
+func mbeanProperties(mbean string) map[string]string {
+	properties := make(map[string]string)
+	parts := strings.SplitN(mbean, ":", 2)
+	if len(parts) != 2 {
+		return properties
+	}
+	for _, match := range mbeanPropertyRegexp.FindAllStringSubmatch(parts[1], -1) {
+		properties[match[1]] = strings.Trim(match[2], `"`)
+	}
+	return properties
+}
+
+func (b *Broker) serve(w http.ResponseWriter, r *http.Request) {
+
+	user, password, ok := r.BasicAuth()
+	if !ok || user != b.User || password != b.Password {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	body, _ := ioutil.ReadAll(r.Body)
+	request := Request{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeResponse(w, request, 400, nil, "java.lang.IllegalArgumentException", err.Error())
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.Requests = append(b.Requests, request)
+
+	properties := mbeanProperties(request.MBean)
+	if properties["broker"] != b.Name {
+		writeResponse(w, request, 404, nil, "javax.management.InstanceNotFoundException", request.MBean)
+		return
+	}
+
+	switch strings.ToLower(request.Type) {
+	case "search":
+		b.search(w, request, properties)
+	case "read":
+		b.read(w, request, properties)
+	case "exec":
+		b.exec(w, request, properties)
+	default:
+		writeResponse(w, request, 400, nil, "java.lang.IllegalArgumentException", "unknown type "+request.Type)
+	}
+}
+
+func (b *Broker) search(w http.ResponseWriter, request Request, properties map[string]string) {
+	var mbeans []string = []string{}
+	for _, queue := range b.Queues {
+		if properties["queue"] == queue.Name {
+			mbeans = append(mbeans, fmt.Sprintf(`org.apache.activemq.artemis:address="%s",broker="%s",component=addresses,queue="%s",routing-type="%s",subcomponent=queues`,
+				queue.Address, b.Name, queue.Name, strings.ToLower(queue.RoutingType)))
+		}
+	}
+	writeResponse(w, request, 200, mbeans, "", "")
+}
+
+func (b *Broker) queueFor(w http.ResponseWriter, request Request, properties map[string]string) *Queue {
+	queue := b.Queues[properties["queue"]]
+	if queue == nil || queue.Address != properties["address"] || strings.ToLower(queue.RoutingType) != properties["routing-type"] {
+		writeResponse(w, request, 404, nil, "javax.management.InstanceNotFoundException", request.MBean)
+		return nil
+	}
+	return queue
+}
+
+func (b *Broker) read(w http.ResponseWriter, request Request, properties map[string]string) {
+
+	if _, isQueue := properties["queue"]; isQueue {
+		queue := b.queueFor(w, request, properties)
+		if queue == nil {
+			return
+		}
+		if request.Attribute == "" {
+			writeResponse(w, request, 200, queue.Attributes, "", "")
+		} else if value, ok := queue.Attributes[request.Attribute]; ok {
+			writeResponse(w, request, 200, value, "", "")
+		} else {
+			writeResponse(w, request, 404, nil, "javax.management.AttributeNotFoundException", request.Attribute)
+		}
+		return
+	}
+
+	switch request.Attribute {
+	case "Version":
+		writeResponse(w, request, 200, b.Version, "", "")
+	case "AddressNames":
+		names := []string{}
+		for address := range b.addresses {
+			names = append(names, address)
+		}
+		sort.Strings(names)
+		writeResponse(w, request, 200, names, "", "")
+	case "QueueNames":
+		names := []string{}
+		for name := range b.Queues {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		writeResponse(w, request, 200, names, "", "")
+	default:
+		writeResponse(w, request, 404, nil, "javax.management.AttributeNotFoundException", request.Attribute)
+	}
+}
+
+func stringArgument(request Request, i int) string {
+	if i >= len(request.Arguments) || request.Arguments[i] == nil {
+		return ""
+	}
+	if s, ok := request.Arguments[i].(string); ok {
+		return s
+	}
+	bytes, _ := json.Marshal(request.Arguments[i])
+	return string(bytes)
+}
+
+func configArgument(request Request) map[string]interface{} {
+	config := make(map[string]interface{})
+	if len(request.Arguments) == 0 {
+		return config
+	}
+	if m, ok := request.Arguments[0].(map[string]interface{}); ok {
+		return m
+	}
+	json.Unmarshal([]byte(stringArgument(request, 0)), &config)
+	return config
+}
+
+func (b *Broker) exec(w http.ResponseWriter, request Request, properties map[string]string) {
+
+	if _, isQueue := properties["queue"]; isQueue {
+		queue := b.queueFor(w, request, properties)
+		if queue == nil {
+			return
+		}
+		switch request.Operation {
+		case "pause()":
+			queue.Attributes["Paused"] = true
+			writeResponse(w, request, 200, nil, "", "")
+		case "resume()":
+			queue.Attributes["Paused"] = false
+			writeResponse(w, request, 200, nil, "", "")
+		case "removeMessages(java.lang.String)":
+			removed := queue.Attributes["MessageCount"]
+			queue.Attributes["MessageCount"] = 0
+			writeResponse(w, request, 200, removed, "", "")
+		case "moveMessages(java.lang.String,java.lang.String)":
+			other := b.Queues[stringArgument(request, 1)]
+			if other == nil {
+				writeResponse(w, request, 500, nil, "java.lang.IllegalArgumentException", "No queue found for "+stringArgument(request, 1))
+				return
+			}
+			moved := toInt(queue.Attributes["MessageCount"])
+			other.Attributes["MessageCount"] = toInt(other.Attributes["MessageCount"]) + moved
+			queue.Attributes["MessageCount"] = 0
+			writeResponse(w, request, 200, moved, "", "")
+		default:
+			writeResponse(w, request, 404, nil, "java.lang.IllegalArgumentException", "No operation "+request.Operation)
+		}
+		return
+	}
+
+	if _, isAddress := properties["address"]; isAddress {
+		if _, ok := b.addresses[properties["address"]]; !ok {
+			writeResponse(w, request, 404, nil, "javax.management.InstanceNotFoundException", request.MBean)
+			return
+		}
+		for _, queue := range b.Queues {
+			if queue.Address == properties["address"] {
+				queue.Attributes["Paused"] = request.Operation == "pause()"
+			}
+		}
+		writeResponse(w, request, 200, nil, "", "")
+		return
+	}
+
+	switch request.Operation {
+	case "listNetworkTopology()":
+		writeResponse(w, request, 200, b.Topology, "", "")
+	case "createAddress(java.lang.String,java.lang.String)":
+		b.addresses[stringArgument(request, 0)] = strings.ToUpper(stringArgument(request, 1))
+		writeResponse(w, request, 200, "", "", "")
+	case "createQueue(java.lang.String,java.lang.String,java.lang.String)":
+		name := stringArgument(request, 1)
+		if _, exists := b.Queues[name]; exists {
+			writeResponse(w, request, 500, nil, "org.apache.activemq.artemis.api.core.ActiveMQQueueExistsException", "AMQ229019: Queue "+name+" already exists on address "+stringArgument(request, 0))
+			return
+		}
+		b.addQueue(stringArgument(request, 0), name, stringArgument(request, 2), nil)
+		writeResponse(w, request, 200, nil, "", "")
+	case "createQueue(java.lang.String,boolean)":
+		config := configArgument(request)
+		name, _ := config["name"].(string)
+		address, _ := config["address"].(string)
+		routingType, _ := config["routing-type"].(string)
+		if _, exists := b.Queues[name]; exists {
+			if ignore, _ := request.Arguments[len(request.Arguments)-1].(bool); ignore {
+				writeResponse(w, request, 200, "", "", "")
+				return
+			}
+			writeResponse(w, request, 500, nil, "org.apache.activemq.artemis.api.core.ActiveMQQueueExistsException", "AMQ229019: Queue "+name+" already exists on address "+address)
+			return
+		}
+		b.addQueue(address, name, routingType, config)
+		writeResponse(w, request, 200, "", "", "")
+	case "updateQueue(java.lang.String)":
+		config := configArgument(request)
+		name, _ := config["name"].(string)
+		queue := b.Queues[name]
+		if queue == nil {
+			writeResponse(w, request, 500, nil, "org.apache.activemq.artemis.api.core.ActiveMQNonExistentQueueException", "AMQ229017: Queue "+name+" does not exist")
+			return
+		}
+		if routingType, ok := config["routing-type"].(string); ok && strings.ToUpper(routingType) != queue.RoutingType {
+			writeResponse(w, request, 500, nil, "java.lang.IllegalArgumentException", "AMQ229242: Invalid routing type "+routingType)
+			return
+		}
+		b.applyConfig(queue, config)
+		writeResponse(w, request, 200, "", "", "")
+	case "destroyQueue(java.lang.String)":
+		name := stringArgument(request, 0)
+		if _, exists := b.Queues[name]; !exists {
+			writeResponse(w, request, 500, nil, "org.apache.activemq.artemis.api.core.ActiveMQNonExistentQueueException", "AMQ229017: Queue "+name+" does not exist")
+			return
+		}
+		delete(b.Queues, name)
+		writeResponse(w, request, 200, nil, "", "")
+	case "listBindingsForAddress(java.lang.String)":
+		var bindings []string
+		for _, queue := range b.Queues {
+			if queue.Address == stringArgument(request, 0) {
+				bindings = append(bindings, fmt.Sprintf("LocalQueueBinding [address=%s, queue=(QueueImpl[name=%s, postOffice=PostOfficeImpl, temp=false]@1), filter=null]", queue.Address, queue.Name))
+			}
+		}
+		sort.Strings(bindings)
+		writeResponse(w, request, 200, strings.Join(bindings, ","), "", "")
+	case "deleteAddress(java.lang.String)":
+		delete(b.addresses, stringArgument(request, 0))
+		writeResponse(w, request, 200, nil, "", "")
+	case "resetUser(java.lang.String,java.lang.String,java.lang.String)":
+		user := stringArgument(request, 0)
+		if _, exists := b.Users[user]; !exists {
+			writeResponse(w, request, 500, nil, "java.lang.IllegalArgumentException", "AMQ229225: User "+user+" does not exist")
+			return
+		}
+		b.Users[user] = User{Password: stringArgument(request, 1), Roles: stringArgument(request, 2)}
+		writeResponse(w, request, 200, nil, "", "")
+	default:
+		writeResponse(w, request, 404, nil, "java.lang.IllegalArgumentException", "No operation "+request.Operation)
+	}
+}
+
+func toInt(value interface{}) int {
+	switch v := value.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+func writeResponse(w http.ResponseWriter, request Request, status int, value interface{}, errorType string, errorMessage string) {
+	response := map[string]interface{}{
+		"request":   request,
+		"status":    status,
+		"timestamp": 0,
+	}
+	if value != nil {
+		response["value"] = value
+	}
+	if errorType != "" {
+		response["error_type"] = errorType
+		response["error"] = errorType + " : " + errorMessage
+	}
+	// jolokia reports errors in the body with a 200 http status
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
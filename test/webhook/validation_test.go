@@ -0,0 +1,336 @@
+package webhook_test
+
+import (
+	"fmt"
+	"testing"
+
+	brokerv1alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v1alpha1"
+	brokerv2alpha1 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha1"
+	brokerv2alpha3 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha3"
+	brokerv2alpha5 "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha5"
+	"github.com/artemiscloud/activemq-artemis-operator/pkg/webhook"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWebhook(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Webhook Suite")
+}
+
+var _ = BeforeSuite(func() {
+	fmt.Println("=======Before Webhook Suite========")
+})
+
+var _ = AfterSuite(func() {
+	fmt.Println("=======After Webhook Suite========")
+})
+
+// fields lists the fields the errors are about
+func fields(errs field.ErrorList) []string {
+	var names []string
+	for _, err := range errs {
+		names = append(names, err.Field)
+	}
+	return names
+}
+
+func newBroker() *brokerv2alpha5.ActiveMQArtemis {
+	return &brokerv2alpha5.ActiveMQArtemis{
+		ObjectMeta: metav1.ObjectMeta{Name: "ex-aao", Namespace: "test"},
+		Spec: brokerv2alpha5.ActiveMQArtemisSpec{
+			DeploymentPlan: brokerv2alpha5.DeploymentPlanType{Size: 1},
+		},
+	}
+}
+
+var _ = Describe("ActiveMQArtemis validation", func() {
+
+	It("accepts a minimal broker", func() {
+		Expect(webhook.ValidateActiveMQArtemis(newBroker(), nil)).To(BeEmpty())
+	})
+
+	It("rejects an unknown journal type and version", func() {
+		cr := newBroker()
+		cr.Spec.DeploymentPlan.JournalType = "mapped"
+		cr.Spec.Version = "1.0.0"
+
+		errs := webhook.ValidateActiveMQArtemis(cr, nil)
+		Expect(fields(errs)).To(ConsistOf("spec.deploymentPlan.journalType", "spec.version"))
+		Expect(errs[0].Type).To(Equal(field.ErrorTypeNotSupported))
+	})
+
+	It("rejects duplicate acceptor names and ports", func() {
+		cr := newBroker()
+		cr.Spec.Acceptors = []brokerv2alpha5.AcceptorType{
+			{Name: "amqp", Port: 5672},
+			{Name: "amqp", Port: 5672},
+		}
+
+		errs := webhook.ValidateActiveMQArtemis(cr, nil)
+		Expect(fields(errs)).To(ConsistOf("spec.acceptors[1].name", "spec.acceptors[1].port"))
+	})
+
+	It("rejects an unknown address full policy", func() {
+		cr := newBroker()
+		policy := "SPILL"
+		cr.Spec.AddressSettings.AddressSetting = []brokerv2alpha5.AddressSettingType{
+			{Match: "#", AddressFullPolicy: &policy},
+		}
+
+		errs := webhook.ValidateActiveMQArtemis(cr, nil)
+		Expect(fields(errs)).To(ConsistOf("spec.addressSettings.addressSetting[0].addressFullPolicy"))
+	})
+
+	It("accepts an ssl acceptor whose secret is not created yet", func() {
+		cr := newBroker()
+		cr.Spec.Acceptors = []brokerv2alpha5.AcceptorType{{Name: "amqps", Port: 5671, SSLEnabled: true}}
+
+		Expect(webhook.ValidateActiveMQArtemis(cr, fake.NewFakeClient())).To(BeEmpty())
+	})
+
+	It("rejects an ssl acceptor whose secret has no stores", func() {
+		cr := newBroker()
+		cr.Spec.Acceptors = []brokerv2alpha5.AcceptorType{{Name: "amqps", Port: 5671, SSLEnabled: true}}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ex-aao-amqps-secret", Namespace: "test"},
+			Data:       map[string][]byte{"keyStorePassword": []byte("secret")},
+		}
+
+		errs := webhook.ValidateActiveMQArtemis(cr, fake.NewFakeClient(secret))
+		Expect(fields(errs)).To(ConsistOf("spec.acceptors[0].sslSecret", "spec.acceptors[0].sslSecret"))
+	})
+
+	It("accepts an ssl acceptor with a pem secret", func() {
+		cr := newBroker()
+		cr.Spec.Acceptors = []brokerv2alpha5.AcceptorType{{Name: "amqps", Port: 5671, SSLEnabled: true}}
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "ex-aao-amqps-secret", Namespace: "test"},
+			Data: map[string][]byte{
+				corev1.TLSCertKey:       []byte("cert"),
+				corev1.TLSPrivateKeyKey: []byte("key"),
+			},
+		}
+
+		Expect(webhook.ValidateActiveMQArtemis(cr, fake.NewFakeClient(secret))).To(BeEmpty())
+	})
+
+	It("rejects a pod template overriding the operator managed parts of the pod", func() {
+		cr := newBroker()
+		cr.Spec.DeploymentPlan.PodTemplate = &corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				InitContainers: []corev1.Container{{Name: "init"}},
+				Containers: []corev1.Container{
+					{Name: "sidecar", Image: "sidecar:latest"},
+					{Name: "ex-aao-container", Image: "custom:latest", Env: []corev1.EnvVar{{Name: "FOO", Value: "bar"}}},
+				},
+				Volumes: []corev1.Volume{{Name: "extra"}, {Name: "amq-cfg-dir"}},
+			},
+		}
+
+		errs := webhook.ValidateActiveMQArtemis(cr, nil)
+		Expect(fields(errs)).To(ConsistOf(
+			"spec.deploymentPlan.podTemplate.spec.initContainers",
+			"spec.deploymentPlan.podTemplate.spec.containers[1]",
+			"spec.deploymentPlan.podTemplate.spec.volumes[1].name"))
+	})
+
+	It("rejects invalid probes and a negative extra liveness delay", func() {
+		cr := newBroker()
+		zero, negative := int32(0), int32(-1)
+		cr.Spec.DeploymentPlan.LivenessProbe.Handler = corev1.Handler{
+			Exec:      &corev1.ExecAction{Command: []string{"true"}},
+			TCPSocket: &corev1.TCPSocketAction{},
+		}
+		cr.Spec.DeploymentPlan.ReadinessProbe.PeriodSeconds = &zero
+		cr.Spec.DeploymentPlan.ExtraLivenessDelaySeconds = &negative
+
+		errs := webhook.ValidateActiveMQArtemis(cr, nil)
+		Expect(fields(errs)).To(ConsistOf(
+			"spec.deploymentPlan.livenessProbe",
+			"spec.deploymentPlan.readinessProbe.periodSeconds",
+			"spec.deploymentPlan.extraLivenessDelaySeconds"))
+	})
+
+	It("rejects moving a data directory on or off a volume of its own once deployed", func() {
+		old := newBroker()
+		old.Spec.DeploymentPlan.PersistenceEnabled = true
+		old.Spec.DeploymentPlan.Storage.Volumes = []brokerv2alpha5.DataVolumeType{{Directory: "journal", Size: "10Gi"}}
+
+		resized := old.DeepCopy()
+		resized.Spec.DeploymentPlan.Storage.Volumes[0].Size = "20Gi"
+		Expect(webhook.ValidateActiveMQArtemisUpdate(old, resized)).To(BeEmpty())
+
+		added := old.DeepCopy()
+		added.Spec.DeploymentPlan.Storage.Volumes = append(added.Spec.DeploymentPlan.Storage.Volumes, brokerv2alpha5.DataVolumeType{Directory: "paging"})
+		Expect(fields(webhook.ValidateActiveMQArtemisUpdate(old, added))).To(ConsistOf("spec.deploymentPlan.storage.volumes"))
+
+		removed := old.DeepCopy()
+		removed.Spec.DeploymentPlan.Storage.Volumes = nil
+		Expect(fields(webhook.ValidateActiveMQArtemisUpdate(old, removed))).To(ConsistOf("spec.deploymentPlan.storage.volumes"))
+
+		notPersistent := newBroker()
+		Expect(webhook.ValidateActiveMQArtemisUpdate(notPersistent, added)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("ActiveMQArtemisAddress validation", func() {
+
+	It("accepts a queue", func() {
+		queueName := "orders"
+		cr := &brokerv2alpha3.ActiveMQArtemisAddress{
+			Spec: brokerv2alpha3.ActiveMQArtemisAddressSpec{AddressName: "orders", QueueName: &queueName},
+		}
+		Expect(webhook.ValidateActiveMQArtemisAddress(cr)).To(BeEmpty())
+	})
+
+	It("rejects a missing address name and an unknown routing type", func() {
+		routingType := "broadcast"
+		cr := &brokerv2alpha3.ActiveMQArtemisAddress{
+			Spec: brokerv2alpha3.ActiveMQArtemisAddressSpec{RoutingType: &routingType},
+		}
+
+		errs := webhook.ValidateActiveMQArtemisAddress(cr)
+		Expect(fields(errs)).To(ConsistOf("spec.addressName", "spec.routingType"))
+	})
+
+	It("rejects a queue configuration without a queue", func() {
+		routingType := "anycast"
+		cr := &brokerv2alpha3.ActiveMQArtemisAddress{
+			Spec: brokerv2alpha3.ActiveMQArtemisAddressSpec{
+				AddressName:        "orders",
+				RoutingType:        &routingType,
+				QueueConfiguration: &brokerv2alpha3.QueueConfigurationType{},
+			},
+		}
+
+		errs := webhook.ValidateActiveMQArtemisAddress(cr)
+		Expect(fields(errs)).To(ConsistOf("spec.queueConfiguration"))
+	})
+})
+
+var _ = Describe("ActiveMQArtemisSecurity validation", func() {
+
+	It("rejects a domain referring to an undeclared login module", func() {
+		moduleName := "missing"
+		cr := &brokerv1alpha1.ActiveMQArtemisSecurity{
+			Spec: brokerv1alpha1.ActiveMQArtemisSecuritySpec{
+				LoginModules: brokerv1alpha1.LoginModulesType{
+					PropertiesLoginModules: []brokerv1alpha1.PropertiesLoginModuleType{{Name: "prop-module"}},
+				},
+				SecurityDomains: brokerv1alpha1.SecurityDomainsType{
+					BrokerDomain: brokerv1alpha1.BrokerDomainType{
+						LoginModules: []brokerv1alpha1.LoginModuleReferenceType{{Name: &moduleName}},
+					},
+				},
+			},
+		}
+
+		errs := webhook.ValidateActiveMQArtemisSecurity(cr)
+		Expect(fields(errs)).To(ConsistOf("spec.securityDomains.brokerDomain.loginModules[0].name"))
+		Expect(errs[0].Type).To(Equal(field.ErrorTypeNotFound))
+	})
+
+	It("rejects duplicate users and a password given twice", func() {
+		password := "secret"
+		cr := &brokerv1alpha1.ActiveMQArtemisSecurity{
+			Spec: brokerv1alpha1.ActiveMQArtemisSecuritySpec{
+				LoginModules: brokerv1alpha1.LoginModulesType{
+					PropertiesLoginModules: []brokerv1alpha1.PropertiesLoginModuleType{{
+						Name: "prop-module",
+						Users: []brokerv1alpha1.UserType{
+							{Name: "admin", Password: &password},
+							{Name: "admin", Password: &password, PasswordFrom: &brokerv1alpha1.PasswordSourceType{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "users"},
+									Key:                  "admin",
+								},
+							}},
+						},
+					}},
+				},
+			},
+		}
+
+		errs := webhook.ValidateActiveMQArtemisSecurity(cr)
+		Expect(fields(errs)).To(ConsistOf(
+			"spec.loginModules.propertiesLoginModules[0].users[1].name",
+			"spec.loginModules.propertiesLoginModules[0].users[1].passwordFrom"))
+	})
+
+	It("rejects domain and module names that are not dns labels", func() {
+		moduleName := "certs;touch pwned"
+		domainName := "$(id)"
+		consoleName := "web-console"
+		cr := &brokerv1alpha1.ActiveMQArtemisSecurity{
+			Spec: brokerv1alpha1.ActiveMQArtemisSecuritySpec{
+				LoginModules: brokerv1alpha1.LoginModulesType{
+					GuestLoginModules:               []brokerv1alpha1.GuestLoginModuleType{{Name: "guest module"}},
+					TextFileCertificateLoginModules: []brokerv1alpha1.TextFileCertificateLoginModuleType{{Name: moduleName}},
+				},
+				SecurityDomains: brokerv1alpha1.SecurityDomainsType{
+					BrokerDomain: brokerv1alpha1.BrokerDomainType{
+						Name:         &domainName,
+						LoginModules: []brokerv1alpha1.LoginModuleReferenceType{{Name: &moduleName}},
+					},
+					ConsoleDomain: brokerv1alpha1.BrokerDomainType{Name: &consoleName},
+				},
+			},
+		}
+
+		errs := webhook.ValidateActiveMQArtemisSecurity(cr)
+		Expect(fields(errs)).To(ConsistOf(
+			"spec.loginModules.guestLoginModules[0].name",
+			"spec.loginModules.textFileCertificateLoginModules[0].name",
+			"spec.securityDomains.brokerDomain.name"))
+		for _, err := range errs {
+			Expect(err.Type).To(Equal(field.ErrorTypeInvalid))
+		}
+	})
+})
+
+var _ = Describe("ActiveMQArtemisScaledown validation", func() {
+
+	newScaledown := func() *brokerv2alpha1.ActiveMQArtemisScaledown {
+		return &brokerv2alpha1.ActiveMQArtemisScaledown{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "ex-aao",
+				Namespace: "test",
+				Annotations: map[string]string{
+					"CRNAME":               "ex-aao",
+					"CRNAMESPACE":          "test",
+					"HEADLESSSVCNAMEVALUE": "ex-aao-hdls-svc",
+					"PINGSVCNAMEVALUE":     "ex-aao-ping-svc",
+				},
+			},
+			Spec: brokerv2alpha1.ActiveMQArtemisScaledownSpec{LocalOnly: true},
+		}
+	}
+
+	It("accepts the scaledown the operator creates", func() {
+		Expect(webhook.ValidateActiveMQArtemisScaledown(newScaledown())).To(BeEmpty())
+	})
+
+	It("requires the broker to drain", func() {
+		cr := newScaledown()
+		delete(cr.Annotations, "CRNAME")
+
+		errs := webhook.ValidateActiveMQArtemisScaledown(cr)
+		Expect(fields(errs)).To(ConsistOf("metadata.annotations[CRNAME]"))
+	})
+
+	It("rejects a local only scaledown of a broker in another namespace", func() {
+		cr := newScaledown()
+		cr.Annotations["CRNAMESPACE"] = "other"
+
+		errs := webhook.ValidateActiveMQArtemisScaledown(cr)
+		Expect(fields(errs)).To(ConsistOf("metadata.annotations[CRNAMESPACE]"))
+
+		cr.Spec.LocalOnly = false
+		Expect(webhook.ValidateActiveMQArtemisScaledown(cr)).To(BeEmpty())
+	})
+})
@@ -1,8 +1,8 @@
 package components
 
 import (
-	activemqartemis "github.com/artemiscloud/activemq-artemis-operator/pkg/controller/broker/v2alpha2/activemqartemis"
 	"github.com/artemiscloud/activemq-artemis-operator/tools/constants"
+	"github.com/artemiscloud/activemq-artemis-operator/version"
 	"sort"
 	"strings"
 
@@ -98,8 +98,8 @@ func GetDeployment(operatorName, repository, context, imageName, tag, imagePullP
 			},
 		},
 	}
-	sort.Sort(sort.Reverse(sort.StringSlice(activemqartemis.SupportedVersions)))
-	for _, imageVersion := range activemqartemis.SupportedVersions {
+	sort.Sort(sort.Reverse(sort.StringSlice(version.SupportedVersions)))
+	for _, imageVersion := range version.SupportedVersions {
 
 		// TODO: FIX reference to 7.5 to be current
 		// add Broker75Image reference in Env variables
@@ -11,7 +11,7 @@ import (
 	"strconv"
 
 	api "github.com/artemiscloud/activemq-artemis-operator/pkg/apis/broker/v2alpha2"
-	activemqartemis "github.com/artemiscloud/activemq-artemis-operator/pkg/controller/broker/v2alpha2/activemqartemis"
+	activemqartemis "github.com/artemiscloud/activemq-artemis-operator/pkg/controller/broker/v2alpha5/activemqartemis"
 	"github.com/artemiscloud/activemq-artemis-operator/tools/components"
 	"github.com/artemiscloud/activemq-artemis-operator/tools/constants"
 	"github.com/artemiscloud/activemq-artemis-operator/tools/util"
@@ -38,7 +38,7 @@ import (
 var (
 	rh              = "Red Hat"
 	maturity        = "stable"
-	major, minor, _ = activemqartemis.MajorMinorMicro(activemqartemis.LatestVersion)
+	major, minor, _ = activemqartemis.MajorMinorMicro(version.LatestVersion)
 	csv             = csvSetting{
 
 		Name:         "activemq-artemis",
@@ -503,7 +503,7 @@ func main() {
 		},
 	}
 
-	sort.Sort(sort.Reverse(sort.StringSlice(activemqartemis.SupportedVersions)))
+	sort.Sort(sort.Reverse(sort.StringSlice(version.SupportedVersions)))
 
 	imageRef.Spec.Tags = append(imageRef.Spec.Tags, constants.ImageRefTag{
 		Name: constants.Broker75Component,